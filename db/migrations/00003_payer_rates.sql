@@ -0,0 +1,106 @@
+-- +goose Up
+
+-- Payer reporting entities (issuers, TPAs) publishing in-network files
+CREATE TABLE IF NOT EXISTS reporting_entities (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(500) NOT NULL,
+    entity_type VARCHAR(100) NOT NULL,
+    UNIQUE(name, entity_type)
+);
+
+-- Payer health plans. Empty strings stand in for absent plan fields so the
+-- unique constraint treats them as equal.
+CREATE TABLE IF NOT EXISTS payer_plans (
+    id SERIAL PRIMARY KEY,
+    reporting_entity_id INTEGER NOT NULL REFERENCES reporting_entities(id) ON DELETE CASCADE,
+    plan_name VARCHAR(500) NOT NULL DEFAULT '',
+    issuer_name VARCHAR(500) NOT NULL DEFAULT '',
+    plan_sponsor_name VARCHAR(500) NOT NULL DEFAULT '',
+    plan_id_type VARCHAR(20) NOT NULL DEFAULT '',
+    plan_id VARCHAR(50) NOT NULL DEFAULT '',
+    plan_market_type VARCHAR(20) NOT NULL DEFAULT '',
+    UNIQUE(reporting_entity_id, plan_name, issuer_name, plan_sponsor_name, plan_id_type, plan_id, plan_market_type)
+);
+CREATE INDEX IF NOT EXISTS idx_payer_plans_plan_id ON payer_plans(plan_id_type, plan_id);
+
+-- One row per loaded in-network file. Item and provider group IDs are
+-- file-local (as assigned by in_network), so child tables key on file_id.
+CREATE TABLE IF NOT EXISTS in_network_files (
+    id SERIAL PRIMARY KEY,
+    reporting_entity_id INTEGER NOT NULL REFERENCES reporting_entities(id) ON DELETE CASCADE,
+    payer_plan_id INTEGER REFERENCES payer_plans(id),
+    last_updated_on DATE NOT NULL,
+    version VARCHAR(20) NOT NULL,
+    source TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_in_network_files_entity ON in_network_files(reporting_entity_id);
+
+-- In-network items (the billing codes a file prices)
+CREATE TABLE IF NOT EXISTS in_network_items (
+    file_id INTEGER NOT NULL REFERENCES in_network_files(id) ON DELETE CASCADE,
+    item_id BIGINT NOT NULL,
+    negotiation_arrangement VARCHAR(20) NOT NULL,
+    name TEXT NOT NULL,
+    billing_code_type VARCHAR(20) NOT NULL,
+    billing_code_type_version VARCHAR(50) NOT NULL,
+    billing_code VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL,
+    bundled_codes JSONB,
+    covered_services JSONB,
+    PRIMARY KEY (file_id, item_id)
+);
+CREATE INDEX IF NOT EXISTS idx_in_network_items_code ON in_network_items(billing_code, billing_code_type);
+
+-- Provider groups as referenced by negotiated prices. A group (a
+-- provider_references entry) may hold several TINs, each with its NPIs.
+CREATE TABLE IF NOT EXISTS provider_groups (
+    file_id INTEGER NOT NULL REFERENCES in_network_files(id) ON DELETE CASCADE,
+    provider_group_id INTEGER NOT NULL,
+    network_names TEXT[],
+    PRIMARY KEY (file_id, provider_group_id)
+);
+
+-- One row per (provider group, TIN, NPI)
+CREATE TABLE IF NOT EXISTS provider_group_npis (
+    file_id INTEGER NOT NULL,
+    provider_group_id INTEGER NOT NULL,
+    npi BIGINT NOT NULL,
+    tin_type VARCHAR(10) NOT NULL,
+    tin_value VARCHAR(50) NOT NULL,
+    business_name TEXT,
+    FOREIGN KEY (file_id, provider_group_id)
+        REFERENCES provider_groups(file_id, provider_group_id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_provider_group_npis_group ON provider_group_npis(file_id, provider_group_id);
+CREATE INDEX IF NOT EXISTS idx_provider_group_npis_npi ON provider_group_npis(npi);
+CREATE INDEX IF NOT EXISTS idx_provider_group_npis_tin ON provider_group_npis(tin_value);
+
+-- Negotiated prices. provider_group_ids reference provider_groups within
+-- the same file.
+CREATE TABLE IF NOT EXISTS negotiated_prices (
+    id BIGSERIAL PRIMARY KEY,
+    file_id INTEGER NOT NULL,
+    item_id BIGINT NOT NULL,
+    negotiated_type VARCHAR(20) NOT NULL,
+    negotiated_rate NUMERIC NOT NULL,
+    expiration_date DATE,
+    billing_class VARCHAR(20) NOT NULL,
+    setting VARCHAR(20),
+    service_code TEXT[],
+    billing_code_modifier TEXT[],
+    additional_information TEXT,
+    provider_group_ids INTEGER[],
+    FOREIGN KEY (file_id, item_id)
+        REFERENCES in_network_items(file_id, item_id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_negotiated_prices_item ON negotiated_prices(file_id, item_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS negotiated_prices;
+DROP TABLE IF EXISTS provider_group_npis;
+DROP TABLE IF EXISTS provider_groups;
+DROP TABLE IF EXISTS in_network_items;
+DROP TABLE IF EXISTS in_network_files;
+DROP TABLE IF EXISTS payer_plans;
+DROP TABLE IF EXISTS reporting_entities;
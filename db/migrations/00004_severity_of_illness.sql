@@ -0,0 +1,7 @@
+-- +goose Up
+
+-- DRG rates may depend on a severity of illness (in_network.severity_of_illness)
+ALTER TABLE in_network_items ADD COLUMN IF NOT EXISTS severity_of_illness TEXT;
+
+-- +goose Down
+ALTER TABLE in_network_items DROP COLUMN IF EXISTS severity_of_illness;
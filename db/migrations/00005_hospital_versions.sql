@@ -0,0 +1,48 @@
+-- +goose Up
+
+-- Reloads look up the stored hospital by license, type 2 NPI or name
+CREATE INDEX IF NOT EXISTS idx_hospitals_license ON hospitals(license_state, license_number);
+CREATE INDEX IF NOT EXISTS idx_hospitals_npis ON hospitals USING GIN (npis);
+CREATE INDEX IF NOT EXISTS idx_hospitals_name ON hospitals(lower(name));
+
+-- Superseded hospital versions, kept when a newer file is loaded with
+-- -archive. Rows keep their original ids; items and modifiers carry the
+-- archive_id of the hospital version they belonged to. Columns follow the
+-- live tables, so add any new live column here too.
+CREATE TABLE IF NOT EXISTS archived_hospitals (LIKE hospitals);
+ALTER TABLE archived_hospitals
+    ADD COLUMN IF NOT EXISTS archive_id SERIAL PRIMARY KEY,
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_archived_hospitals_id ON archived_hospitals(id);
+
+CREATE TABLE IF NOT EXISTS archived_standard_charge_items (LIKE standard_charge_items);
+ALTER TABLE archived_standard_charge_items ADD COLUMN IF NOT EXISTS archive_id INTEGER NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_archived_items_archive ON archived_standard_charge_items(archive_id);
+
+CREATE TABLE IF NOT EXISTS archived_item_codes (LIKE item_codes);
+CREATE INDEX IF NOT EXISTS idx_archived_item_codes_item ON archived_item_codes(item_id);
+
+CREATE TABLE IF NOT EXISTS archived_standard_charges (LIKE standard_charges);
+CREATE INDEX IF NOT EXISTS idx_archived_charges_item ON archived_standard_charges(item_id);
+
+CREATE TABLE IF NOT EXISTS archived_payer_charges (LIKE payer_charges);
+CREATE INDEX IF NOT EXISTS idx_archived_payer_charges_charge ON archived_payer_charges(standard_charge_id);
+
+CREATE TABLE IF NOT EXISTS archived_modifiers (LIKE modifiers);
+ALTER TABLE archived_modifiers ADD COLUMN IF NOT EXISTS archive_id INTEGER NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_archived_modifiers_archive ON archived_modifiers(archive_id);
+
+CREATE TABLE IF NOT EXISTS archived_modifier_payer_info (LIKE modifier_payer_info);
+CREATE INDEX IF NOT EXISTS idx_archived_modifier_payer_modifier ON archived_modifier_payer_info(modifier_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS archived_modifier_payer_info;
+DROP TABLE IF EXISTS archived_modifiers;
+DROP TABLE IF EXISTS archived_payer_charges;
+DROP TABLE IF EXISTS archived_standard_charges;
+DROP TABLE IF EXISTS archived_item_codes;
+DROP TABLE IF EXISTS archived_standard_charge_items;
+DROP TABLE IF EXISTS archived_hospitals;
+DROP INDEX IF EXISTS idx_hospitals_name;
+DROP INDEX IF EXISTS idx_hospitals_npis;
+DROP INDEX IF EXISTS idx_hospitals_license;
@@ -1,11 +1,9 @@
 package main
 
 import (
-	"bufio"
 	"encoding/csv"
 	"fmt"
 	"io"
-	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -22,15 +20,27 @@ const (
 	formatWide
 )
 
+// csvTemplate is the CMS CSV template generation. V3 changes row 1
+// (location_name, type_2_npi, attester_name) and adds the median_amount,
+// 10th_percentile, 90th_percentile and count columns.
+type csvTemplate int
+
+const (
+	templateV2 csvTemplate = iota
+	templateV3
+)
+
 type hospitalMeta struct {
 	hospitalName              string
 	lastUpdatedOn             string
 	version                   string
-	hospitalLocation          string
-	hospitalAddress           string
+	hospitalLocations         []string
+	hospitalAddresses         []string
+	type2NPIs                 []string
 	licenseNumber             *string
 	licenseState              *string
 	affirmation               bool
+	attesterName              *string
 	financialAidPolicy        *string
 	generalContractProvisions *string
 }
@@ -51,35 +61,53 @@ type payerPlanCols struct {
 	estIdx    int
 	methodIdx int
 	notesIdx  int
+	medianIdx int // V3
+	p10Idx    int // V3
+	p90Idx    int // V3
+	countIdx  int // V3
 }
 
-// CSVReader streams a CMS V2.x CSV file (Tall or Wide) and emits
+// CSVReader streams a CMS V2.x or V3 CSV file (Tall or Wide) and emits
 // HospitalChargeRow records one CSV row at a time.
 type CSVReader struct {
-	file    *os.File
-	csv     *csv.Reader
-	format  csvFormat
-	rowNum  int64
-	colIdx  map[string]int // lowercase normalized key → column index
-	headers []string       // normalized (trimmed pipe-segments), original case
-	meta    hospitalMeta
+	input    io.Closer
+	csv      *csv.Reader
+	format   csvFormat
+	template csvTemplate
+	enc      textEncoding
+	rowNum   int64
+	colIdx   map[string]int // lowercase normalized key → column index
+	headers  []string       // normalized (trimmed pipe-segments), original case
+	meta     hospitalMeta
 
 	codeCols   []codeColPair
 	payerPlans []payerPlanCols // Wide format only
+	codes      []codePair      // code pairs of the last row, for -validate
+
+	transcoded int64 // cells decoded from Windows-1252 / Latin-1
 }
 
+// NewCSVReader opens a CSV file, decompressing gzip content and
+// detecting the text encoding.
 func NewCSVReader(filepath string) (*CSVReader, error) {
-	file, err := os.Open(filepath)
+	in, err := openInputFile(filepath)
 	if err != nil {
-		return nil, fmt.Errorf("open %s: %w", filepath, err)
+		return nil, err
 	}
+	return newCSVReader(in, encodingAuto)
+}
 
-	bufReader := bufio.NewReaderSize(file, 256*1024)
+// newCSVReader reads CSV from an opened input and takes ownership of it.
+func newCSVReader(in *inputReader, enc textEncoding) (*CSVReader, error) {
+	bufReader := in.Reader
 
-	// Skip UTF-8 BOM if present
+	// Skip UTF-8 BOM if present. A BOM settles the encoding in auto mode.
 	bom, err := bufReader.Peek(3)
 	if err == nil && len(bom) >= 3 && bom[0] == 0xEF && bom[1] == 0xBB && bom[2] == 0xBF {
 		bufReader.Discard(3)
+		if enc == encodingAuto {
+			enc = encodingUTF8
+		}
 	}
 
 	reader := csv.NewReader(bufReader)
@@ -87,13 +115,14 @@ func NewCSVReader(filepath string) (*CSVReader, error) {
 	reader.FieldsPerRecord = -1
 
 	r := &CSVReader{
-		file:   file,
+		input:  in,
 		csv:    reader,
+		enc:    enc,
 		colIdx: make(map[string]int),
 	}
 
 	if err := r.readHeaders(); err != nil {
-		file.Close()
+		in.Close()
 		return nil, err
 	}
 
@@ -121,6 +150,7 @@ func (r *CSVReader) readHeaders() error {
 	if len(headerRow) > 0 {
 		headerRow[0] = strings.TrimPrefix(headerRow[0], "\ufeff")
 	}
+	r.transcodeRow(headerRow)
 
 	// Row 2: header values
 	valueRow, err := r.csv.Read()
@@ -128,8 +158,10 @@ func (r *CSVReader) readHeaders() error {
 		return fmt.Errorf("read header row 2: %w", err)
 	}
 	r.rowNum++
+	r.transcodeRow(valueRow)
 
 	r.parseHeaderMeta(headerRow, valueRow)
+	r.template = detectTemplate(headerRow, r.meta.version)
 
 	// Row 3: data column headers
 	r.headers, err = r.csv.Read()
@@ -137,6 +169,7 @@ func (r *CSVReader) readHeaders() error {
 		return fmt.Errorf("read column headers row 3: %w", err)
 	}
 	r.rowNum++
+	r.transcodeRow(r.headers)
 
 	// Normalize headers and build lowercase index for case-insensitive lookup.
 	// r.headers keeps normalized original case (for payer name extraction).
@@ -156,6 +189,18 @@ func (r *CSVReader) readHeaders() error {
 	return nil
 }
 
+// splitHeaderList splits a multi-valued header cell. CMS CSV templates list
+// several locations, addresses or NPIs in one cell separated by "|".
+func splitHeaderList(val string) []string {
+	var out []string
+	for _, v := range strings.Split(val, "|") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func (r *CSVReader) parseHeaderMeta(headerRow, valueRow []string) {
 	for i, col := range headerRow {
 		if i >= len(valueRow) {
@@ -171,10 +216,20 @@ func (r *CSVReader) parseHeaderMeta(headerRow, valueRow []string) {
 			r.meta.lastUpdatedOn = val
 		case strings.EqualFold(col, "version"):
 			r.meta.version = val
-		case strings.EqualFold(col, "hospital_location"):
-			r.meta.hospitalLocation = val
+		case strings.EqualFold(col, "hospital_location"), strings.EqualFold(col, "location_name"):
+			r.meta.hospitalLocations = splitHeaderList(val)
 		case strings.EqualFold(col, "hospital_address"):
-			r.meta.hospitalAddress = val
+			r.meta.hospitalAddresses = splitHeaderList(val)
+		case strings.EqualFold(col, "type_2_npi"):
+			r.meta.type2NPIs = splitHeaderList(val)
+		case strings.EqualFold(col, "attester_name"):
+			if val != "" {
+				r.meta.attesterName = &val
+			}
+		case strings.EqualFold(col, "license_number"):
+			if val != "" && r.meta.licenseNumber == nil {
+				r.meta.licenseNumber = &val
+			}
 		case strings.HasPrefix(strings.ToLower(col), "license_number|"):
 			parts := strings.SplitN(col, "|", 2)
 			if len(parts) == 2 && r.meta.licenseState == nil {
@@ -200,24 +255,64 @@ func (r *CSVReader) parseHeaderMeta(headerRow, valueRow []string) {
 	}
 }
 
+// detectTemplate identifies the template from the version value, falling
+// back to the row 1 fields only V3 has when version is missing or odd.
+func detectTemplate(headerRow []string, version string) csvTemplate {
+	if strings.HasPrefix(strings.TrimSpace(version), "3") {
+		return templateV3
+	}
+	if strings.HasPrefix(strings.TrimSpace(version), "2") {
+		return templateV2
+	}
+	for _, col := range headerRow {
+		switch strings.ToLower(strings.TrimSpace(col)) {
+		case "location_name", "type_2_npi", "attester_name":
+			return templateV3
+		}
+	}
+	return templateV2
+}
+
 func (r *CSVReader) detectFormat() csvFormat {
 	// Tall format has explicit payer_name/plan_name columns.
-	// Check via direct map lookup before scanning for Wide indicators,
-	// since map iteration order is non-deterministic.
 	if _, ok := r.colIdx["payer_name"]; ok {
 		return formatTall
 	}
 	if _, ok := r.colIdx["plan_name"]; ok {
 		return formatTall
 	}
-	for k := range r.colIdx {
-		if strings.Contains(k, "|") && strings.Contains(k, "negotiated_dollar") {
+	for _, h := range r.headers {
+		if isWidePayerColumn(h) {
 			return formatWide
 		}
 	}
 	return formatTall
 }
 
+// isWidePayerColumn reports whether h names a per-payer/plan Wide column:
+// standard_charge|<payer>|<plan>|<negotiated_*|methodology>, or a
+// <field>|<payer>|<plan> column such as V3 median_amount|<payer>|<plan>.
+// A payer may carry only percentages or algorithms, so any of them marks
+// the file as Wide.
+func isWidePayerColumn(h string) bool {
+	parts := strings.Split(strings.ToLower(h), "|")
+	if len(parts) >= 4 && parts[0] == "standard_charge" {
+		switch parts[len(parts)-1] {
+		case "negotiated_dollar", "negotiated_percentage", "negotiated_algorithm", "methodology":
+			return true
+		}
+		return false
+	}
+	if len(parts) >= 3 {
+		switch parts[0] {
+		case "estimated_amount", "additional_payer_notes",
+			"median_amount", "10th_percentile", "90th_percentile", "count":
+			return true
+		}
+	}
+	return false
+}
+
 func (r *CSVReader) extractCodeCols() {
 	// Match against lowercase keys to handle "Code|1" or "code|1"
 	for lk, idx := range r.colIdx {
@@ -248,6 +343,7 @@ func (r *CSVReader) extractPayerPlans() {
 			payer: payer, plan: plan,
 			dollarIdx: -1, pctIdx: -1, algoIdx: -1,
 			estIdx: -1, methodIdx: -1, notesIdx: -1,
+			medianIdx: -1, p10Idx: -1, p90Idx: -1, countIdx: -1,
 		})
 		seen[key] = idx
 		return idx
@@ -281,20 +377,27 @@ func (r *CSVReader) extractPayerPlans() {
 			}
 		}
 
-		// estimated_amount|<payer>|<plan> (3+ parts)
-		if len(parts) >= 3 && strings.EqualFold(parts[0], "estimated_amount") {
-			payer := parts[1]
-			plan := strings.Join(parts[2:], "|")
-			idx := ensurePP(payer, plan)
-			r.payerPlans[idx].estIdx = i
-		}
-
-		// additional_payer_notes|<payer>|<plan> (3+ parts)
-		if len(parts) >= 3 && strings.EqualFold(parts[0], "additional_payer_notes") {
-			payer := parts[1]
-			plan := strings.Join(parts[2:], "|")
-			idx := ensurePP(payer, plan)
-			r.payerPlans[idx].notesIdx = i
+		// <field>|<payer>|<plan> (3+ parts): estimated_amount,
+		// additional_payer_notes and the V3 median_amount,
+		// 10th_percentile, 90th_percentile and count
+		if len(parts) >= 3 {
+			pp := func() *payerPlanCols {
+				return &r.payerPlans[ensurePP(parts[1], strings.Join(parts[2:], "|"))]
+			}
+			switch strings.ToLower(parts[0]) {
+			case "estimated_amount":
+				pp().estIdx = i
+			case "additional_payer_notes":
+				pp().notesIdx = i
+			case "median_amount":
+				pp().medianIdx = i
+			case "10th_percentile":
+				pp().p10Idx = i
+			case "90th_percentile":
+				pp().p90Idx = i
+			case "count":
+				pp().countIdx = i
+			}
 		}
 	}
 }
@@ -314,6 +417,7 @@ func (r *CSVReader) Next() ([]HospitalChargeRow, error) {
 		if len(row) == 0 || (len(row) == 1 && row[0] == "") {
 			continue
 		}
+		r.transcodeRow(row)
 
 		if r.format == formatTall {
 			return r.parseTallRow(row), nil
@@ -332,6 +436,10 @@ func (r *CSVReader) parseTallRow(row []string) []HospitalChargeRow {
 	base.NegotiatedAlgorithm = optStr(row, r.colIdx, "standard_charge|negotiated_algorithm")
 	base.EstimatedAmount = optFloat(row, r.colIdx, "estimated_amount")
 	base.Methodology = optStr(row, r.colIdx, "standard_charge|methodology")
+	base.MedianAmount = optFloat(row, r.colIdx, "median_amount")
+	base.Percentile10th = optFloat(row, r.colIdx, "10th_percentile")
+	base.Percentile90th = optFloat(row, r.colIdx, "90th_percentile")
+	base.Count = optStr(row, r.colIdx, "count")
 
 	return []HospitalChargeRow{base}
 }
@@ -349,8 +457,13 @@ func (r *CSVReader) parseWideRow(row []string) []HospitalChargeRow {
 		est := floatAt(row, pp.estIdx)
 		method := strAt(row, pp.methodIdx)
 		notes := strAt(row, pp.notesIdx)
+		median := floatAt(row, pp.medianIdx)
+		p10 := floatAt(row, pp.p10Idx)
+		p90 := floatAt(row, pp.p90Idx)
+		count := strAt(row, pp.countIdx)
 
-		if dollar == nil && pct == nil && algo == nil && est == nil && method == nil && notes == nil {
+		if dollar == nil && pct == nil && algo == nil && est == nil && method == nil && notes == nil &&
+			median == nil && p10 == nil && p90 == nil && count == nil {
 			continue
 		}
 
@@ -365,6 +478,10 @@ func (r *CSVReader) parseWideRow(row []string) []HospitalChargeRow {
 		prow.EstimatedAmount = est
 		prow.Methodology = method
 		prow.AdditionalPayerNotes = notes
+		prow.MedianAmount = median
+		prow.Percentile10th = p10
+		prow.Percentile90th = p90
+		prow.Count = count
 		rows = append(rows, prow)
 	}
 
@@ -382,11 +499,13 @@ func (r *CSVReader) baseRow(row []string) HospitalChargeRow {
 		HospitalName:              r.meta.hospitalName,
 		LastUpdatedOn:             r.meta.lastUpdatedOn,
 		Version:                   r.meta.version,
-		HospitalLocation:          r.meta.hospitalLocation,
-		HospitalAddress:           r.meta.hospitalAddress,
+		HospitalLocations:         r.meta.hospitalLocations,
+		HospitalAddresses:         r.meta.hospitalAddresses,
+		Type2NPIs:                 r.meta.type2NPIs,
 		LicenseNumber:             r.meta.licenseNumber,
 		LicenseState:              r.meta.licenseState,
 		Affirmation:               r.meta.affirmation,
+		AttesterName:              r.meta.attesterName,
 		FinancialAidPolicy:        r.meta.financialAidPolicy,
 		GeneralContractProvisions: r.meta.generalContractProvisions,
 
@@ -406,19 +525,21 @@ func (r *CSVReader) baseRow(row []string) HospitalChargeRow {
 		BillingClass:           optStr(row, r.colIdx, "billing_class"),
 	}
 
+	r.codes = r.codes[:0]
 	for _, cc := range r.codeCols {
-		if cc.codeIdx >= len(row) {
-			continue
-		}
-		codeVal := strings.TrimSpace(row[cc.codeIdx])
-		if codeVal == "" {
-			continue
+		codeVal := ""
+		if cc.codeIdx < len(row) {
+			codeVal = strings.TrimSpace(row[cc.codeIdx])
 		}
 		codeType := ""
 		if cc.typeIdx >= 0 && cc.typeIdx < len(row) {
 			codeType = strings.ToUpper(strings.TrimSpace(row[cc.typeIdx]))
 		}
-		if codeType != "" {
+		if codeVal == "" && codeType == "" {
+			continue
+		}
+		r.codes = append(r.codes, codePair{code: codeVal, codeType: codeType})
+		if codeVal != "" && codeType != "" {
 			hr.SetCode(codeType, codeVal)
 		}
 	}
@@ -434,6 +555,41 @@ func (r *CSVReader) Format() string {
 	return "tall"
 }
 
+// TemplateVersion returns "v2" or "v3".
+func (r *CSVReader) TemplateVersion() string {
+	if r.template == templateV3 {
+		return "v3"
+	}
+	return "v2"
+}
+
+// Meta returns the row 1 and 2 hospital metadata.
+func (r *CSVReader) Meta() hospitalMeta {
+	return r.meta
+}
+
+// Codes returns the code / code type pairs of the last row as written,
+// including incomplete pairs and types SetCode does not know.
+func (r *CSVReader) Codes() []codePair {
+	return r.codes
+}
+
+// transcodeRow decodes non-UTF-8 cells in place and counts them.
+func (r *CSVReader) transcodeRow(row []string) {
+	for i, cell := range row {
+		if s, ok := r.enc.transcode(cell); ok {
+			row[i] = s
+			r.transcoded++
+		}
+	}
+}
+
+// TranscodedCells returns how many cells were decoded from Windows-1252
+// or Latin-1 to UTF-8.
+func (r *CSVReader) TranscodedCells() int64 {
+	return r.transcoded
+}
+
 // RowNum returns the current CSV row number (1-based).
 func (r *CSVReader) RowNum() int64 {
 	return r.rowNum
@@ -445,8 +601,8 @@ func (r *CSVReader) PayerPlanCount() int {
 }
 
 func (r *CSVReader) Close() error {
-	if r.file != nil {
-		return r.file.Close()
+	if r.input != nil {
+		return r.input.Close()
 	}
 	return nil
 }
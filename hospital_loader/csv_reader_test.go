@@ -5,6 +5,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/parquet-go/parquet-go"
@@ -183,8 +184,8 @@ func TestCSVReaderTallToParquet(t *testing.T) {
 		if row.Version != "2.0.0" {
 			t.Errorf("row[%d].Version = %q, want %q", i, row.Version, "2.0.0")
 		}
-		if row.HospitalAddress != "123 Main St, New York, NY 10001" {
-			t.Errorf("row[%d].HospitalAddress = %q", i, row.HospitalAddress)
+		if want := []string{"123 Main St, New York, NY 10001"}; !slices.Equal(row.HospitalAddresses, want) {
+			t.Errorf("row[%d].HospitalAddresses = %q, want %q", i, row.HospitalAddresses, want)
 		}
 	}
 
@@ -372,3 +373,216 @@ func TestCSVReaderWideToParquet(t *testing.T) {
 		assertStrPtrEq(t, "roundtrip Methodology", pq.Methodology, csv.Methodology)
 	}
 }
+
+// TestCSVReaderV3AllowedAmounts checks the V3 median, percentile and count
+// columns in both layouts and that they survive the Parquet round trip.
+func TestCSVReaderV3AllowedAmounts(t *testing.T) {
+	dir := t.TempDir()
+	tall := filepath.Join(dir, "v3_tall.csv")
+	tallContent := `hospital_name,last_updated_on,version,hospital_location,hospital_address
+V3 Hospital,2025-01-01,3.0.0,Albany NY,1 State St
+description,setting,code|1,code|1|type,standard_charge|gross,payer_name,plan_name,standard_charge|negotiated_dollar,standard_charge|methodology,median_amount,10th_percentile,90th_percentile,count
+KNEE ARTHROSCOPY,outpatient,29881,CPT,9000.00,Aetna,PPO,4050.00,case_rate,4100.50,3200.00,5300.00,11
+`
+	if err := os.WriteFile(tall, []byte(tallContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wide := filepath.Join(dir, "v3_wide.csv")
+	wideContent := `hospital_name,last_updated_on,version,hospital_location,hospital_address
+V3 Hospital,2025-01-01,3.0.0,Albany NY,1 State St
+description,setting,code|1,code|1|type,standard_charge|gross,standard_charge|Aetna|PPO|negotiated_dollar,standard_charge|Aetna|PPO|methodology,median_amount|Aetna|PPO,10th_percentile|Aetna|PPO,90th_percentile|Aetna|PPO,count|Aetna|PPO,count|Cigna|HMO
+KNEE ARTHROSCOPY,outpatient,29881,CPT,9000.00,4050.00,case_rate,4100.50,3200.00,5300.00,11,1 through 10
+`
+	if err := os.WriteFile(wide, []byte(wideContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{tall, wide} {
+		parquetPath, _ := csvToParquet(t, path)
+		rows := readParquet(t, parquetPath)
+		name := filepath.Base(path)
+		want := 1
+		if path == wide {
+			want = 2 // Cigna has only a count, which is enough to emit its row
+		}
+		if len(rows) != want {
+			t.Fatalf("%s: %d rows, want %d", name, len(rows), want)
+		}
+		r := rows[0]
+		assertStrPtrEq(t, name+" PayerName", r.PayerName, strPtr("Aetna"))
+		assertF64PtrEq(t, name+" NegotiatedDollar", r.NegotiatedDollar, f64Ptr(4050.00))
+		assertF64PtrEq(t, name+" MedianAmount", r.MedianAmount, f64Ptr(4100.50))
+		assertF64PtrEq(t, name+" Percentile10th", r.Percentile10th, f64Ptr(3200.00))
+		assertF64PtrEq(t, name+" Percentile90th", r.Percentile90th, f64Ptr(5300.00))
+		assertStrPtrEq(t, name+" Count", r.Count, strPtr("11"))
+		if path == wide {
+			r = rows[1]
+			assertStrPtrEq(t, name+" row[1].PayerName", r.PayerName, strPtr("Cigna"))
+			assertStrPtrEq(t, name+" row[1].Count", r.Count, strPtr("1 through 10"))
+			assertF64PtrEq(t, name+" row[1].MedianAmount", r.MedianAmount, nil)
+		}
+	}
+}
+
+// TestCSVReaderV3HeaderLists checks the V3 header row: pipe-separated
+// locations, addresses and type 2 NPIs, the license column and attester.
+func TestCSVReaderV3HeaderLists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "v3_header.csv")
+	content := `hospital_name,last_updated_on,version,location_name,hospital_address,license_number|NY,type_2_npi,"To the best of its knowledge and belief, this hospital has included all applicable standard charge information",attester_name
+V3 Hospital,2025-01-01,3.0.0,V3 Hospital | V3 Hospital East,1 State St Albany NY|2 East Rd Albany NY,LIC-1,1234567890|1987654321,true,Pat Smith
+description,setting,code|1,code|1|type,standard_charge|gross
+X-RAY CHEST,outpatient,71046,CPT,250.00
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parquetPath, _ := csvToParquet(t, path)
+	rows := readParquet(t, parquetPath)
+	if len(rows) != 1 {
+		t.Fatalf("%d rows, want 1", len(rows))
+	}
+	r := rows[0]
+	if want := []string{"V3 Hospital", "V3 Hospital East"}; !slices.Equal(r.HospitalLocations, want) {
+		t.Errorf("HospitalLocations = %q, want %q", r.HospitalLocations, want)
+	}
+	if want := []string{"1 State St Albany NY", "2 East Rd Albany NY"}; !slices.Equal(r.HospitalAddresses, want) {
+		t.Errorf("HospitalAddresses = %q, want %q", r.HospitalAddresses, want)
+	}
+	if want := []string{"1234567890", "1987654321"}; !slices.Equal(r.Type2NPIs, want) {
+		t.Errorf("Type2NPIs = %q, want %q", r.Type2NPIs, want)
+	}
+	assertStrPtrEq(t, "LicenseNumber", r.LicenseNumber, strPtr("LIC-1"))
+	assertStrPtrEq(t, "LicenseState", r.LicenseState, strPtr("NY"))
+	assertStrPtrEq(t, "AttesterName", r.AttesterName, strPtr("Pat Smith"))
+	if !r.Affirmation {
+		t.Error("Affirmation = false, want true")
+	}
+}
+
+// TestCSVReaderWindows1252 reads an Excel-style Windows-1252 export with
+// one UTF-8 cell mixed in, as files edited in several tools often are.
+func TestCSVReaderWindows1252(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cp1252.csv")
+	content := "hospital_name,last_updated_on,version,hospital_location,hospital_address\n" +
+		"H\xf4pital Saint-Andr\xe9,2024-01-15,2.0.0,Main,1 Rue \x96 Est\n" +
+		"description,setting,code|1,code|1|type,standard_charge|gross,additional_generic_notes\n" +
+		"CAF\xc9 CONSULT \x93NEW\x94,outpatient,99203,CPT,150.00,na\xefve \u2013 ok\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := NewCSVReader(path)
+	if err != nil {
+		t.Fatalf("NewCSVReader: %v", err)
+	}
+	defer reader.Close()
+	rows, err := reader.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	r := rows[0]
+	if r.HospitalName != "Hôpital Saint-André" {
+		t.Errorf("HospitalName = %q", r.HospitalName)
+	}
+	if want := []string{"1 Rue – Est"}; !slices.Equal(r.HospitalAddresses, want) {
+		t.Errorf("HospitalAddresses = %q, want %q", r.HospitalAddresses, want)
+	}
+	if r.Description != "CAFÉ CONSULT “NEW”" {
+		t.Errorf("Description = %q", r.Description)
+	}
+	assertStrPtrEq(t, "AdditionalGenericNotes", r.AdditionalGenericNotes, strPtr("naïve – ok"))
+	if got := reader.TranscodedCells(); got != 4 {
+		t.Errorf("TranscodedCells = %d, want 4", got)
+	}
+}
+
+// The V3 fixtures follow the column layout of the CMS V3.0.0 Tall and Wide
+// CSV templates, with values in the style of the template examples.
+
+const v3TallCSV = `hospital_name,last_updated_on,version,location_name,hospital_address,license_number|CA,type_2_npi,"To the best of its knowledge and belief, this hospital has included all applicable standard charge information in accordance with the requirements of 45 CFR 180.50, and the information encoded is true, accurate, and complete as of the date in the file.",attester_name
+West Mercy Hospital,2025-01-01,3.0.0,West Mercy Hospital|West Mercy Surgical Center,"12 Main Street, Fullerton, CA 92832|23 Ocean Ave, San Jose, CA 94088",50056,0000000001|0000000002,true,Jane Doe
+description,code|1,code|1|type,code|2,code|2|type,modifiers,setting,drug_unit_of_measurement,drug_type_of_measurement,standard_charge|gross,standard_charge|discounted_cash,payer_name,plan_name,standard_charge|negotiated_dollar,standard_charge|negotiated_percentage,standard_charge|negotiated_algorithm,median_amount,10th_percentile,90th_percentile,count,standard_charge|min,standard_charge|max,standard_charge|methodology,additional_generic_notes
+Major hip and knee joint replacement or reattachment of lower extremity without mcc,470,MS-DRG,175869,LOCAL,,inpatient,,,120000,70000,Platform Health Insurance,PPO,,50,,17500.25,15000,25000,11,,,percent of total billed charges,110 dollars per diem
+Behavioral health; residential (hospital residential treatment program),H0017,HCPCS,1001,RC,,inpatient,,,3000,2500,Region Health Insurance,HMO,1500,,,,,,0,1500,1500,per diem,
+`
+
+const v3WideCSV = `hospital_name,last_updated_on,version,location_name,hospital_address,license_number|CA,type_2_npi,"To the best of its knowledge and belief, this hospital has included all applicable standard charge information in accordance with the requirements of 45 CFR 180.50, and the information encoded is true, accurate, and complete as of the date in the file.",attester_name
+West Mercy Hospital,2025-01-01,3.0.0,West Mercy Hospital,"12 Main Street, Fullerton, CA 92832",50056,0000000001,true,Jane Doe
+description,code|1,code|1|type,setting,standard_charge|gross,standard_charge|discounted_cash,standard_charge|min,standard_charge|max,standard_charge|Platform Health Insurance|PPO|negotiated_percentage,standard_charge|Platform Health Insurance|PPO|negotiated_algorithm,median_amount|Platform Health Insurance|PPO,10th_percentile|Platform Health Insurance|PPO,90th_percentile|Platform Health Insurance|PPO,count|Platform Health Insurance|PPO,standard_charge|Platform Health Insurance|PPO|methodology,additional_payer_notes|Platform Health Insurance|PPO,standard_charge|Region Health Insurance|HMO|negotiated_algorithm,count|Region Health Insurance|HMO,standard_charge|Region Health Insurance|HMO|methodology,additional_generic_notes
+Major hip and knee joint replacement,470,MS-DRG,inpatient,120000,70000,16000,20000,50,,17500.25,15000,25000,11,percent of total billed charges,Capped at the max,,,,
+Behavioral health; residential,H0017,HCPCS,inpatient,3000,2500,1500,1500,,,,,,,,,"Per diem of $1,500 per approved day",1 through 10,per diem,
+`
+
+func TestCSVReaderV3Templates(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct{ name, content, format string }{
+		{"v3_tall.csv", v3TallCSV, "tall"},
+		{"v3_wide.csv", v3WideCSV, "wide"},
+	} {
+		path := filepath.Join(dir, tc.name)
+		if err := os.WriteFile(path, []byte(tc.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		reader, err := NewCSVReader(path)
+		if err != nil {
+			t.Fatalf("NewCSVReader(%s): %v", tc.name, err)
+		}
+		if reader.Format() != tc.format || reader.TemplateVersion() != "v3" {
+			t.Errorf("%s: format %s/%s, want %s/v3", tc.name, reader.Format(), reader.TemplateVersion(), tc.format)
+		}
+		reader.Close()
+
+		parquetPath, _ := csvToParquet(t, path)
+		rows := readParquet(t, parquetPath)
+		// Both files have one Platform PPO percentage row and one Region HMO row.
+		if len(rows) != 2 {
+			t.Fatalf("%s: %d rows, want 2", tc.name, len(rows))
+		}
+		for i, r := range rows {
+			assertStrPtrEq(t, tc.name+" LicenseNumber", r.LicenseNumber, strPtr("50056"))
+			assertStrPtrEq(t, tc.name+" LicenseState", r.LicenseState, strPtr("CA"))
+			assertStrPtrEq(t, tc.name+" AttesterName", r.AttesterName, strPtr("Jane Doe"))
+			if !r.Affirmation {
+				t.Errorf("%s row[%d].Affirmation = false", tc.name, i)
+			}
+			if r.HospitalLocations[0] != "West Mercy Hospital" {
+				t.Errorf("%s row[%d].HospitalLocations = %q", tc.name, i, r.HospitalLocations)
+			}
+		}
+
+		r := rows[0]
+		assertStrPtrEq(t, tc.name+" row[0].MSDRGCode", r.MSDRGCode, strPtr("470"))
+		assertStrPtrEq(t, tc.name+" row[0].PayerName", r.PayerName, strPtr("Platform Health Insurance"))
+		assertStrPtrEq(t, tc.name+" row[0].PlanName", r.PlanName, strPtr("PPO"))
+		assertF64PtrEq(t, tc.name+" row[0].NegotiatedDollar", r.NegotiatedDollar, nil)
+		assertF64PtrEq(t, tc.name+" row[0].NegotiatedPercentage", r.NegotiatedPercentage, f64Ptr(50))
+		assertF64PtrEq(t, tc.name+" row[0].MedianAmount", r.MedianAmount, f64Ptr(17500.25))
+		assertF64PtrEq(t, tc.name+" row[0].Percentile10th", r.Percentile10th, f64Ptr(15000))
+		assertF64PtrEq(t, tc.name+" row[0].Percentile90th", r.Percentile90th, f64Ptr(25000))
+		assertStrPtrEq(t, tc.name+" row[0].Count", r.Count, strPtr("11"))
+		assertStrPtrEq(t, tc.name+" row[0].Methodology", r.Methodology, strPtr("percent of total billed charges"))
+
+		r = rows[1]
+		assertStrPtrEq(t, tc.name+" row[1].HCPCSCode", r.HCPCSCode, strPtr("H0017"))
+		assertStrPtrEq(t, tc.name+" row[1].PayerName", r.PayerName, strPtr("Region Health Insurance"))
+		assertStrPtrEq(t, tc.name+" row[1].Methodology", r.Methodology, strPtr("per diem"))
+		assertF64PtrEq(t, tc.name+" row[1].MedianAmount", r.MedianAmount, nil)
+	}
+
+	// Tall-only and Wide-only details
+	tallPath, _ := csvToParquet(t, filepath.Join(dir, "v3_tall.csv"))
+	tall := readParquet(t, tallPath)
+	if want := []string{"0000000001", "0000000002"}; !slices.Equal(tall[0].Type2NPIs, want) {
+		t.Errorf("tall Type2NPIs = %q, want %q", tall[0].Type2NPIs, want)
+	}
+	if want := []string{"12 Main Street, Fullerton, CA 92832", "23 Ocean Ave, San Jose, CA 94088"}; !slices.Equal(tall[0].HospitalAddresses, want) {
+		t.Errorf("tall HospitalAddresses = %q, want %q", tall[0].HospitalAddresses, want)
+	}
+	assertStrPtrEq(t, "tall row[1].Count", tall[1].Count, strPtr("0"))
+	assertF64PtrEq(t, "tall row[1].NegotiatedDollar", tall[1].NegotiatedDollar, f64Ptr(1500))
+
+	widePath, _ := csvToParquet(t, filepath.Join(dir, "v3_wide.csv"))
+	wide := readParquet(t, widePath)
+	assertStrPtrEq(t, "wide row[0].AdditionalPayerNotes", wide[0].AdditionalPayerNotes, strPtr("Capped at the max"))
+	assertStrPtrEq(t, "wide row[1].NegotiatedAlgorithm", wide[1].NegotiatedAlgorithm, strPtr("Per diem of $1,500 per approved day"))
+	assertStrPtrEq(t, "wide row[1].Count", wide[1].Count, strPtr("1 through 10"))
+}
@@ -9,6 +9,39 @@ import (
 	"context"
 )
 
+// iteratorForInsertItemCodes implements pgx.CopyFromSource.
+type iteratorForInsertItemCodes struct {
+	rows                 []InsertItemCodesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForInsertItemCodes) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForInsertItemCodes) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ItemID,
+		r.rows[0].CodeID,
+	}, nil
+}
+
+func (r iteratorForInsertItemCodes) Err() error {
+	return nil
+}
+
+func (q *Queries) InsertItemCodes(ctx context.Context, arg []InsertItemCodesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"item_codes"}, []string{"item_id", "code_id"}, &iteratorForInsertItemCodes{rows: arg})
+}
+
 // iteratorForInsertPayerCharges implements pgx.CopyFromSource.
 type iteratorForInsertPayerCharges struct {
 	rows                 []InsertPayerChargesParams
@@ -52,3 +85,79 @@ func (r iteratorForInsertPayerCharges) Err() error {
 func (q *Queries) InsertPayerCharges(ctx context.Context, arg []InsertPayerChargesParams) (int64, error) {
 	return q.db.CopyFrom(ctx, []string{"payer_charges"}, []string{"standard_charge_id", "payer_id", "plan_id", "methodology", "standard_charge_dollar", "standard_charge_percentage", "standard_charge_algorithm", "estimated_amount", "median_amount", "percentile_10th", "percentile_90th", "count", "additional_notes"}, &iteratorForInsertPayerCharges{rows: arg})
 }
+
+// iteratorForInsertStandardChargeItems implements pgx.CopyFromSource.
+type iteratorForInsertStandardChargeItems struct {
+	rows                 []InsertStandardChargeItemsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForInsertStandardChargeItems) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForInsertStandardChargeItems) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].HospitalID,
+		r.rows[0].Description,
+		r.rows[0].DrugUnit,
+		r.rows[0].DrugUnitType,
+	}, nil
+}
+
+func (r iteratorForInsertStandardChargeItems) Err() error {
+	return nil
+}
+
+func (q *Queries) InsertStandardChargeItems(ctx context.Context, arg []InsertStandardChargeItemsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"standard_charge_items"}, []string{"id", "hospital_id", "description", "drug_unit", "drug_unit_type"}, &iteratorForInsertStandardChargeItems{rows: arg})
+}
+
+// iteratorForInsertStandardCharges implements pgx.CopyFromSource.
+type iteratorForInsertStandardCharges struct {
+	rows                 []InsertStandardChargesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForInsertStandardCharges) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForInsertStandardCharges) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].ItemID,
+		r.rows[0].Setting,
+		r.rows[0].GrossCharge,
+		r.rows[0].DiscountedCash,
+		r.rows[0].Minimum,
+		r.rows[0].Maximum,
+		r.rows[0].ModifierCodes,
+		r.rows[0].AdditionalNotes,
+	}, nil
+}
+
+func (r iteratorForInsertStandardCharges) Err() error {
+	return nil
+}
+
+func (q *Queries) InsertStandardCharges(ctx context.Context, arg []InsertStandardChargesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"standard_charges"}, []string{"id", "item_id", "setting", "gross_charge", "discounted_cash", "minimum", "maximum", "modifier_codes", "additional_notes"}, &iteratorForInsertStandardCharges{rows: arg})
+}
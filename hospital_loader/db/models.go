@@ -8,6 +8,84 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ArchivedHospital struct {
+	ID            int32            `json:"id"`
+	Name          string           `json:"name"`
+	Addresses     []string         `json:"addresses"`
+	LocationNames []string         `json:"location_names"`
+	Npis          []string         `json:"npis"`
+	LicenseNumber pgtype.Text      `json:"license_number"`
+	LicenseState  pgtype.Text      `json:"license_state"`
+	Version       string           `json:"version"`
+	LastUpdatedOn pgtype.Date      `json:"last_updated_on"`
+	AttesterName  pgtype.Text      `json:"attester_name"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	ArchiveID     int32            `json:"archive_id"`
+	ArchivedAt    pgtype.Timestamp `json:"archived_at"`
+}
+
+type ArchivedItemCode struct {
+	ID     int32 `json:"id"`
+	ItemID int32 `json:"item_id"`
+	CodeID int32 `json:"code_id"`
+}
+
+type ArchivedModifier struct {
+	ID          int32       `json:"id"`
+	HospitalID  int32       `json:"hospital_id"`
+	Code        string      `json:"code"`
+	Description string      `json:"description"`
+	Setting     pgtype.Text `json:"setting"`
+	ArchiveID   int32       `json:"archive_id"`
+}
+
+type ArchivedModifierPayerInfo struct {
+	ID          int32  `json:"id"`
+	ModifierID  int32  `json:"modifier_id"`
+	PlanID      int32  `json:"plan_id"`
+	Description string `json:"description"`
+	PayerID     int32  `json:"payer_id"`
+}
+
+type ArchivedPayerCharge struct {
+	ID                       int32          `json:"id"`
+	StandardChargeID         int32          `json:"standard_charge_id"`
+	PlanID                   int32          `json:"plan_id"`
+	Methodology              string         `json:"methodology"`
+	StandardChargeDollar     pgtype.Numeric `json:"standard_charge_dollar"`
+	StandardChargePercentage pgtype.Numeric `json:"standard_charge_percentage"`
+	StandardChargeAlgorithm  pgtype.Text    `json:"standard_charge_algorithm"`
+	EstimatedAmount          pgtype.Numeric `json:"estimated_amount"`
+	MedianAmount             pgtype.Numeric `json:"median_amount"`
+	Percentile10th           pgtype.Numeric `json:"percentile_10th"`
+	Percentile90th           pgtype.Numeric `json:"percentile_90th"`
+	Count                    pgtype.Text    `json:"count"`
+	AdditionalNotes          pgtype.Text    `json:"additional_notes"`
+	PayerID                  int32          `json:"payer_id"`
+}
+
+type ArchivedStandardCharge struct {
+	ID              int32          `json:"id"`
+	ItemID          int32          `json:"item_id"`
+	Setting         string         `json:"setting"`
+	GrossCharge     pgtype.Numeric `json:"gross_charge"`
+	DiscountedCash  pgtype.Numeric `json:"discounted_cash"`
+	Minimum         pgtype.Numeric `json:"minimum"`
+	Maximum         pgtype.Numeric `json:"maximum"`
+	ModifierCodes   []string       `json:"modifier_codes"`
+	AdditionalNotes pgtype.Text    `json:"additional_notes"`
+}
+
+type ArchivedStandardChargeItem struct {
+	ID           int32            `json:"id"`
+	HospitalID   int32            `json:"hospital_id"`
+	Description  string           `json:"description"`
+	DrugUnit     pgtype.Numeric   `json:"drug_unit"`
+	DrugUnitType pgtype.Text      `json:"drug_unit_type"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	ArchiveID    int32            `json:"archive_id"`
+}
+
 type Code struct {
 	ID       int32  `json:"id"`
 	Code     string `json:"code"`
@@ -28,6 +106,29 @@ type Hospital struct {
 	CreatedAt     pgtype.Timestamp `json:"created_at"`
 }
 
+type InNetworkFile struct {
+	ID                int32            `json:"id"`
+	ReportingEntityID int32            `json:"reporting_entity_id"`
+	PayerPlanID       pgtype.Int4      `json:"payer_plan_id"`
+	LastUpdatedOn     pgtype.Date      `json:"last_updated_on"`
+	Version           string           `json:"version"`
+	Source            string           `json:"source"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+}
+
+type InNetworkItem struct {
+	FileID                 int32  `json:"file_id"`
+	ItemID                 int64  `json:"item_id"`
+	NegotiationArrangement string `json:"negotiation_arrangement"`
+	Name                   string `json:"name"`
+	BillingCodeType        string `json:"billing_code_type"`
+	BillingCodeTypeVersion string `json:"billing_code_type_version"`
+	BillingCode            string `json:"billing_code"`
+	Description            string `json:"description"`
+	BundledCodes           []byte `json:"bundled_codes"`
+	CoveredServices        []byte `json:"covered_services"`
+}
+
 type ItemCode struct {
 	ID     int32 `json:"id"`
 	ItemID int32 `json:"item_id"`
@@ -50,6 +151,21 @@ type ModifierPayerInfo struct {
 	PayerID     int32  `json:"payer_id"`
 }
 
+type NegotiatedPrice struct {
+	ID                    int64          `json:"id"`
+	FileID                int32          `json:"file_id"`
+	ItemID                int64          `json:"item_id"`
+	NegotiatedType        string         `json:"negotiated_type"`
+	NegotiatedRate        pgtype.Numeric `json:"negotiated_rate"`
+	ExpirationDate        pgtype.Date    `json:"expiration_date"`
+	BillingClass          string         `json:"billing_class"`
+	Setting               pgtype.Text    `json:"setting"`
+	ServiceCode           []string       `json:"service_code"`
+	BillingCodeModifier   []string       `json:"billing_code_modifier"`
+	AdditionalInformation pgtype.Text    `json:"additional_information"`
+	ProviderGroupIds      []int32        `json:"provider_group_ids"`
+}
+
 type Payer struct {
 	ID   int32  `json:"id"`
 	Name string `json:"name"`
@@ -72,11 +188,43 @@ type PayerCharge struct {
 	PayerID                  int32          `json:"payer_id"`
 }
 
+type PayerPlan struct {
+	ID                int32  `json:"id"`
+	ReportingEntityID int32  `json:"reporting_entity_id"`
+	PlanName          string `json:"plan_name"`
+	IssuerName        string `json:"issuer_name"`
+	PlanSponsorName   string `json:"plan_sponsor_name"`
+	PlanIDType        string `json:"plan_id_type"`
+	PlanID            string `json:"plan_id"`
+	PlanMarketType    string `json:"plan_market_type"`
+}
+
 type Plan struct {
 	ID   int32  `json:"id"`
 	Name string `json:"name"`
 }
 
+type ProviderGroup struct {
+	FileID          int32    `json:"file_id"`
+	ProviderGroupID int32    `json:"provider_group_id"`
+	NetworkNames    []string `json:"network_names"`
+}
+
+type ProviderGroupNpi struct {
+	FileID          int32       `json:"file_id"`
+	ProviderGroupID int32       `json:"provider_group_id"`
+	Npi             int64       `json:"npi"`
+	TinType         string      `json:"tin_type"`
+	TinValue        string      `json:"tin_value"`
+	BusinessName    pgtype.Text `json:"business_name"`
+}
+
+type ReportingEntity struct {
+	ID         int32  `json:"id"`
+	Name       string `json:"name"`
+	EntityType string `json:"entity_type"`
+}
+
 type StandardCharge struct {
 	ID              int32          `json:"id"`
 	ItemID          int32          `json:"item_id"`
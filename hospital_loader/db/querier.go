@@ -9,26 +9,46 @@ import (
 )
 
 type Querier interface {
+	ArchiveHospital(ctx context.Context, id int32) (int32, error)
+	ArchiveHospitalCharges(ctx context.Context, hospitalID int32) error
+	ArchiveHospitalItemCodes(ctx context.Context, hospitalID int32) error
+	ArchiveHospitalItems(ctx context.Context, arg ArchiveHospitalItemsParams) error
+	ArchiveHospitalModifierPayerInfo(ctx context.Context, hospitalID int32) error
+	ArchiveHospitalModifiers(ctx context.Context, arg ArchiveHospitalModifiersParams) error
+	ArchiveHospitalPayerCharges(ctx context.Context, hospitalID int32) error
 	CodeExists(ctx context.Context, arg CodeExistsParams) (bool, error)
+	CountArchivedItems(ctx context.Context) (int32, error)
 	CountCharges(ctx context.Context) (int32, error)
 	CountCodes(ctx context.Context) (int32, error)
+	CountHospitals(ctx context.Context) (int32, error)
 	CountItemCodes(ctx context.Context) (int32, error)
 	CountItems(ctx context.Context) (int32, error)
+	CountModifiers(ctx context.Context) (int32, error)
 	CountPayerCharges(ctx context.Context) (int32, error)
 	CountPayers(ctx context.Context) (int32, error)
 	CountPlans(ctx context.Context) (int32, error)
+	DeleteHospitalItems(ctx context.Context, hospitalID int32) error
+	DeleteHospitalModifiers(ctx context.Context, hospitalID int32) error
+	FindHospitalCandidates(ctx context.Context, arg FindHospitalCandidatesParams) ([]FindHospitalCandidatesRow, error)
 	// Read queries (used by tests)
 	GetFirstHospital(ctx context.Context) (GetFirstHospitalRow, error)
+	GetHospitalLists(ctx context.Context) (GetHospitalListsRow, error)
 	GetItemDrugInfo(ctx context.Context, description string) (GetItemDrugInfoRow, error)
 	GetItemNotes(ctx context.Context, description string) (GetItemNotesRow, error)
 	InsertHospital(ctx context.Context, arg InsertHospitalParams) (int32, error)
-	InsertItemCode(ctx context.Context, arg InsertItemCodeParams) error
+	InsertItemCodes(ctx context.Context, arg []InsertItemCodesParams) (int64, error)
+	InsertModifier(ctx context.Context, arg InsertModifierParams) (int32, error)
+	InsertModifierPayerInfo(ctx context.Context, arg InsertModifierPayerInfoParams) error
 	InsertPayerCharges(ctx context.Context, arg []InsertPayerChargesParams) (int64, error)
-	InsertStandardCharge(ctx context.Context, arg InsertStandardChargeParams) (int32, error)
-	InsertStandardChargeItem(ctx context.Context, arg InsertStandardChargeItemParams) (int32, error)
+	InsertStandardChargeItems(ctx context.Context, arg []InsertStandardChargeItemsParams) (int64, error)
+	InsertStandardCharges(ctx context.Context, arg []InsertStandardChargesParams) (int64, error)
 	ListChargeValues(ctx context.Context) ([]ListChargeValuesRow, error)
 	ListItemDescriptions(ctx context.Context) ([]string, error)
+	ListModifierPayerInfo(ctx context.Context) ([]ListModifierPayerInfoRow, error)
 	ListPayerDetails(ctx context.Context) ([]ListPayerDetailsRow, error)
+	NextChargeIDs(ctx context.Context, count int32) ([]int32, error)
+	NextItemIDs(ctx context.Context, count int32) ([]int32, error)
+	UpdateHospital(ctx context.Context, arg UpdateHospitalParams) error
 	UpsertCode(ctx context.Context, arg UpsertCodeParams) (int32, error)
 	UpsertPayer(ctx context.Context, name string) (int32, error)
 	UpsertPlan(ctx context.Context, name string) (int32, error)
@@ -11,6 +11,116 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const archiveHospital = `-- name: ArchiveHospital :one
+INSERT INTO archived_hospitals
+  (id, name, addresses, location_names, npis, license_number, license_state, version, last_updated_on, attester_name, created_at)
+SELECT id, name, addresses, location_names, npis, license_number, license_state, version, last_updated_on, attester_name, created_at
+FROM hospitals WHERE id = $1
+RETURNING archive_id
+`
+
+func (q *Queries) ArchiveHospital(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, archiveHospital, id)
+	var archive_id int32
+	err := row.Scan(&archive_id)
+	return archive_id, err
+}
+
+const archiveHospitalCharges = `-- name: ArchiveHospitalCharges :exec
+INSERT INTO archived_standard_charges
+  (id, item_id, setting, gross_charge, discounted_cash, minimum, maximum, modifier_codes, additional_notes)
+SELECT sc.id, sc.item_id, sc.setting, sc.gross_charge, sc.discounted_cash, sc.minimum, sc.maximum,
+       sc.modifier_codes, sc.additional_notes
+FROM standard_charges sc
+JOIN standard_charge_items sci ON sci.id = sc.item_id
+WHERE sci.hospital_id = $1
+`
+
+func (q *Queries) ArchiveHospitalCharges(ctx context.Context, hospitalID int32) error {
+	_, err := q.db.Exec(ctx, archiveHospitalCharges, hospitalID)
+	return err
+}
+
+const archiveHospitalItemCodes = `-- name: ArchiveHospitalItemCodes :exec
+INSERT INTO archived_item_codes (id, item_id, code_id)
+SELECT ic.id, ic.item_id, ic.code_id
+FROM item_codes ic
+JOIN standard_charge_items sci ON sci.id = ic.item_id
+WHERE sci.hospital_id = $1
+`
+
+func (q *Queries) ArchiveHospitalItemCodes(ctx context.Context, hospitalID int32) error {
+	_, err := q.db.Exec(ctx, archiveHospitalItemCodes, hospitalID)
+	return err
+}
+
+const archiveHospitalItems = `-- name: ArchiveHospitalItems :exec
+INSERT INTO archived_standard_charge_items
+  (id, hospital_id, description, drug_unit, drug_unit_type, created_at, archive_id)
+SELECT id, hospital_id, description, drug_unit, drug_unit_type, created_at, $1::int
+FROM standard_charge_items WHERE hospital_id = $2
+`
+
+type ArchiveHospitalItemsParams struct {
+	ArchiveID  int32 `json:"archive_id"`
+	HospitalID int32 `json:"hospital_id"`
+}
+
+func (q *Queries) ArchiveHospitalItems(ctx context.Context, arg ArchiveHospitalItemsParams) error {
+	_, err := q.db.Exec(ctx, archiveHospitalItems, arg.ArchiveID, arg.HospitalID)
+	return err
+}
+
+const archiveHospitalModifierPayerInfo = `-- name: ArchiveHospitalModifierPayerInfo :exec
+INSERT INTO archived_modifier_payer_info (id, modifier_id, payer_id, plan_id, description)
+SELECT mpi.id, mpi.modifier_id, mpi.payer_id, mpi.plan_id, mpi.description
+FROM modifier_payer_info mpi
+JOIN modifiers m ON m.id = mpi.modifier_id
+WHERE m.hospital_id = $1
+`
+
+func (q *Queries) ArchiveHospitalModifierPayerInfo(ctx context.Context, hospitalID int32) error {
+	_, err := q.db.Exec(ctx, archiveHospitalModifierPayerInfo, hospitalID)
+	return err
+}
+
+const archiveHospitalModifiers = `-- name: ArchiveHospitalModifiers :exec
+INSERT INTO archived_modifiers (id, hospital_id, code, description, setting, archive_id)
+SELECT id, hospital_id, code, description, setting, $1::int
+FROM modifiers WHERE hospital_id = $2
+`
+
+type ArchiveHospitalModifiersParams struct {
+	ArchiveID  int32 `json:"archive_id"`
+	HospitalID int32 `json:"hospital_id"`
+}
+
+func (q *Queries) ArchiveHospitalModifiers(ctx context.Context, arg ArchiveHospitalModifiersParams) error {
+	_, err := q.db.Exec(ctx, archiveHospitalModifiers, arg.ArchiveID, arg.HospitalID)
+	return err
+}
+
+const archiveHospitalPayerCharges = `-- name: ArchiveHospitalPayerCharges :exec
+INSERT INTO archived_payer_charges
+  (id, standard_charge_id, payer_id, plan_id, methodology,
+   standard_charge_dollar, standard_charge_percentage,
+   standard_charge_algorithm, estimated_amount, median_amount,
+   percentile_10th, percentile_90th, count, additional_notes)
+SELECT pc.id, pc.standard_charge_id, pc.payer_id, pc.plan_id, pc.methodology,
+       pc.standard_charge_dollar, pc.standard_charge_percentage,
+       pc.standard_charge_algorithm, pc.estimated_amount, pc.median_amount,
+       pc.percentile_10th, pc.percentile_90th, pc.count, pc.additional_notes
+FROM payer_charges pc
+JOIN standard_charges sc ON sc.id = pc.standard_charge_id
+JOIN standard_charge_items sci ON sci.id = sc.item_id
+WHERE sci.hospital_id = $1
+`
+
+func (q *Queries) ArchiveHospitalPayerCharges(ctx context.Context, hospitalID int32) error {
+	_, err := q.db.Exec(ctx, archiveHospitalPayerCharges, hospitalID)
+	return err
+}
+
 const codeExists = `-- name: CodeExists :one
 SELECT EXISTS(SELECT 1 FROM codes WHERE code = $1 AND code_type = $2)
 `
@@ -27,6 +137,17 @@ func (q *Queries) CodeExists(ctx context.Context, arg CodeExistsParams) (bool, e
 	return exists, err
 }
 
+const countArchivedItems = `-- name: CountArchivedItems :one
+SELECT count(*)::int FROM archived_standard_charge_items
+`
+
+func (q *Queries) CountArchivedItems(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, countArchivedItems)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const countCharges = `-- name: CountCharges :one
 SELECT count(*)::int FROM standard_charges
 `
@@ -49,6 +170,17 @@ func (q *Queries) CountCodes(ctx context.Context) (int32, error) {
 	return column_1, err
 }
 
+const countHospitals = `-- name: CountHospitals :one
+SELECT count(*)::int FROM hospitals
+`
+
+func (q *Queries) CountHospitals(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, countHospitals)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const countItemCodes = `-- name: CountItemCodes :one
 SELECT count(*)::int FROM item_codes
 `
@@ -71,6 +203,17 @@ func (q *Queries) CountItems(ctx context.Context) (int32, error) {
 	return column_1, err
 }
 
+const countModifiers = `-- name: CountModifiers :one
+SELECT count(*)::int FROM modifiers
+`
+
+func (q *Queries) CountModifiers(ctx context.Context) (int32, error) {
+	row := q.db.QueryRow(ctx, countModifiers)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const countPayerCharges = `-- name: CountPayerCharges :one
 SELECT count(*)::int FROM payer_charges
 `
@@ -104,6 +247,83 @@ func (q *Queries) CountPlans(ctx context.Context) (int32, error) {
 	return column_1, err
 }
 
+const deleteHospitalItems = `-- name: DeleteHospitalItems :exec
+DELETE FROM standard_charge_items WHERE hospital_id = $1
+`
+
+func (q *Queries) DeleteHospitalItems(ctx context.Context, hospitalID int32) error {
+	_, err := q.db.Exec(ctx, deleteHospitalItems, hospitalID)
+	return err
+}
+
+const deleteHospitalModifiers = `-- name: DeleteHospitalModifiers :exec
+DELETE FROM modifiers WHERE hospital_id = $1
+`
+
+func (q *Queries) DeleteHospitalModifiers(ctx context.Context, hospitalID int32) error {
+	_, err := q.db.Exec(ctx, deleteHospitalModifiers, hospitalID)
+	return err
+}
+
+const findHospitalCandidates = `-- name: FindHospitalCandidates :many
+SELECT id, name, addresses, npis, license_number, license_state, last_updated_on
+FROM hospitals
+WHERE (license_number = $1 AND upper(license_state) = upper($2))
+   OR npis && $3::text[]
+   OR lower(name) = lower($4)
+ORDER BY last_updated_on DESC, id DESC
+`
+
+type FindHospitalCandidatesParams struct {
+	LicenseNumber pgtype.Text `json:"license_number"`
+	LicenseState  pgtype.Text `json:"license_state"`
+	Npis          []string    `json:"npis"`
+	Name          string      `json:"name"`
+}
+
+type FindHospitalCandidatesRow struct {
+	ID            int32       `json:"id"`
+	Name          string      `json:"name"`
+	Addresses     []string    `json:"addresses"`
+	Npis          []string    `json:"npis"`
+	LicenseNumber pgtype.Text `json:"license_number"`
+	LicenseState  pgtype.Text `json:"license_state"`
+	LastUpdatedOn pgtype.Date `json:"last_updated_on"`
+}
+
+func (q *Queries) FindHospitalCandidates(ctx context.Context, arg FindHospitalCandidatesParams) ([]FindHospitalCandidatesRow, error) {
+	rows, err := q.db.Query(ctx, findHospitalCandidates,
+		arg.LicenseNumber,
+		arg.LicenseState,
+		arg.Npis,
+		arg.Name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FindHospitalCandidatesRow
+	for rows.Next() {
+		var i FindHospitalCandidatesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Addresses,
+			&i.Npis,
+			&i.LicenseNumber,
+			&i.LicenseState,
+			&i.LastUpdatedOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFirstHospital = `-- name: GetFirstHospital :one
 
 SELECT name, version, addresses[1]::text as first_address FROM hospitals LIMIT 1
@@ -123,6 +343,29 @@ func (q *Queries) GetFirstHospital(ctx context.Context) (GetFirstHospitalRow, er
 	return i, err
 }
 
+const getHospitalLists = `-- name: GetHospitalLists :one
+SELECT addresses, location_names, npis, attester_name FROM hospitals LIMIT 1
+`
+
+type GetHospitalListsRow struct {
+	Addresses     []string    `json:"addresses"`
+	LocationNames []string    `json:"location_names"`
+	Npis          []string    `json:"npis"`
+	AttesterName  pgtype.Text `json:"attester_name"`
+}
+
+func (q *Queries) GetHospitalLists(ctx context.Context) (GetHospitalListsRow, error) {
+	row := q.db.QueryRow(ctx, getHospitalLists)
+	var i GetHospitalListsRow
+	err := row.Scan(
+		&i.Addresses,
+		&i.LocationNames,
+		&i.Npis,
+		&i.AttesterName,
+	)
+	return i, err
+}
+
 const getItemDrugInfo = `-- name: GetItemDrugInfo :one
 SELECT drug_unit, drug_unit_type
 FROM standard_charge_items
@@ -197,19 +440,55 @@ func (q *Queries) InsertHospital(ctx context.Context, arg InsertHospitalParams)
 	return id, err
 }
 
-const insertItemCode = `-- name: InsertItemCode :exec
-INSERT INTO item_codes (item_id, code_id)
-VALUES ($1, $2)
-ON CONFLICT (item_id, code_id) DO NOTHING
-`
-
-type InsertItemCodeParams struct {
+type InsertItemCodesParams struct {
 	ItemID int32 `json:"item_id"`
 	CodeID int32 `json:"code_id"`
 }
 
-func (q *Queries) InsertItemCode(ctx context.Context, arg InsertItemCodeParams) error {
-	_, err := q.db.Exec(ctx, insertItemCode, arg.ItemID, arg.CodeID)
+const insertModifier = `-- name: InsertModifier :one
+INSERT INTO modifiers (hospital_id, code, description, setting)
+VALUES ($1, $2, $3, $4)
+RETURNING id
+`
+
+type InsertModifierParams struct {
+	HospitalID  int32       `json:"hospital_id"`
+	Code        string      `json:"code"`
+	Description string      `json:"description"`
+	Setting     pgtype.Text `json:"setting"`
+}
+
+func (q *Queries) InsertModifier(ctx context.Context, arg InsertModifierParams) (int32, error) {
+	row := q.db.QueryRow(ctx, insertModifier,
+		arg.HospitalID,
+		arg.Code,
+		arg.Description,
+		arg.Setting,
+	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const insertModifierPayerInfo = `-- name: InsertModifierPayerInfo :exec
+INSERT INTO modifier_payer_info (modifier_id, payer_id, plan_id, description)
+VALUES ($1, $2, $3, $4)
+`
+
+type InsertModifierPayerInfoParams struct {
+	ModifierID  int32  `json:"modifier_id"`
+	PayerID     int32  `json:"payer_id"`
+	PlanID      int32  `json:"plan_id"`
+	Description string `json:"description"`
+}
+
+func (q *Queries) InsertModifierPayerInfo(ctx context.Context, arg InsertModifierPayerInfoParams) error {
+	_, err := q.db.Exec(ctx, insertModifierPayerInfo,
+		arg.ModifierID,
+		arg.PayerID,
+		arg.PlanID,
+		arg.Description,
+	)
 	return err
 }
 
@@ -229,14 +508,16 @@ type InsertPayerChargesParams struct {
 	AdditionalNotes          pgtype.Text    `json:"additional_notes"`
 }
 
-const insertStandardCharge = `-- name: InsertStandardCharge :one
-INSERT INTO standard_charges
-  (item_id, setting, gross_charge, discounted_cash, minimum, maximum, modifier_codes, additional_notes)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id
-`
+type InsertStandardChargeItemsParams struct {
+	ID           int32          `json:"id"`
+	HospitalID   int32          `json:"hospital_id"`
+	Description  string         `json:"description"`
+	DrugUnit     pgtype.Numeric `json:"drug_unit"`
+	DrugUnitType pgtype.Text    `json:"drug_unit_type"`
+}
 
-type InsertStandardChargeParams struct {
+type InsertStandardChargesParams struct {
+	ID              int32          `json:"id"`
 	ItemID          int32          `json:"item_id"`
 	Setting         string         `json:"setting"`
 	GrossCharge     pgtype.Numeric `json:"gross_charge"`
@@ -247,47 +528,6 @@ type InsertStandardChargeParams struct {
 	AdditionalNotes pgtype.Text    `json:"additional_notes"`
 }
 
-func (q *Queries) InsertStandardCharge(ctx context.Context, arg InsertStandardChargeParams) (int32, error) {
-	row := q.db.QueryRow(ctx, insertStandardCharge,
-		arg.ItemID,
-		arg.Setting,
-		arg.GrossCharge,
-		arg.DiscountedCash,
-		arg.Minimum,
-		arg.Maximum,
-		arg.ModifierCodes,
-		arg.AdditionalNotes,
-	)
-	var id int32
-	err := row.Scan(&id)
-	return id, err
-}
-
-const insertStandardChargeItem = `-- name: InsertStandardChargeItem :one
-INSERT INTO standard_charge_items (hospital_id, description, drug_unit, drug_unit_type)
-VALUES ($1, $2, $3, $4)
-RETURNING id
-`
-
-type InsertStandardChargeItemParams struct {
-	HospitalID   int32          `json:"hospital_id"`
-	Description  string         `json:"description"`
-	DrugUnit     pgtype.Numeric `json:"drug_unit"`
-	DrugUnitType pgtype.Text    `json:"drug_unit_type"`
-}
-
-func (q *Queries) InsertStandardChargeItem(ctx context.Context, arg InsertStandardChargeItemParams) (int32, error) {
-	row := q.db.QueryRow(ctx, insertStandardChargeItem,
-		arg.HospitalID,
-		arg.Description,
-		arg.DrugUnit,
-		arg.DrugUnitType,
-	)
-	var id int32
-	err := row.Scan(&id)
-	return id, err
-}
-
 const listChargeValues = `-- name: ListChargeValues :many
 SELECT sci.description, sc.gross_charge
 FROM standard_charges sc
@@ -344,6 +584,49 @@ func (q *Queries) ListItemDescriptions(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
+const listModifierPayerInfo = `-- name: ListModifierPayerInfo :many
+SELECT m.code, m.setting, py.name as payer_name, p.name as plan_name, mpi.description
+FROM modifier_payer_info mpi
+JOIN modifiers m ON m.id = mpi.modifier_id
+JOIN payers py ON py.id = mpi.payer_id
+JOIN plans p ON p.id = mpi.plan_id
+ORDER BY m.code, py.name
+`
+
+type ListModifierPayerInfoRow struct {
+	Code        string      `json:"code"`
+	Setting     pgtype.Text `json:"setting"`
+	PayerName   string      `json:"payer_name"`
+	PlanName    string      `json:"plan_name"`
+	Description string      `json:"description"`
+}
+
+func (q *Queries) ListModifierPayerInfo(ctx context.Context) ([]ListModifierPayerInfoRow, error) {
+	rows, err := q.db.Query(ctx, listModifierPayerInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListModifierPayerInfoRow
+	for rows.Next() {
+		var i ListModifierPayerInfoRow
+		if err := rows.Scan(
+			&i.Code,
+			&i.Setting,
+			&i.PayerName,
+			&i.PlanName,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPayerDetails = `-- name: ListPayerDetails :many
 SELECT py.name as payer_name, p.name as plan_name, pc.standard_charge_dollar, pc.methodology
 FROM payer_charges pc
@@ -384,6 +667,92 @@ func (q *Queries) ListPayerDetails(ctx context.Context) ([]ListPayerDetailsRow,
 	return items, nil
 }
 
+const nextChargeIDs = `-- name: NextChargeIDs :many
+SELECT nextval(pg_get_serial_sequence('standard_charges', 'id'))::int AS id
+FROM generate_series(1, $1::int)
+`
+
+func (q *Queries) NextChargeIDs(ctx context.Context, count int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, nextChargeIDs, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const nextItemIDs = `-- name: NextItemIDs :many
+SELECT nextval(pg_get_serial_sequence('standard_charge_items', 'id'))::int AS id
+FROM generate_series(1, $1::int)
+`
+
+func (q *Queries) NextItemIDs(ctx context.Context, count int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, nextItemIDs, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateHospital = `-- name: UpdateHospital :exec
+UPDATE hospitals
+SET name = $2, addresses = $3, location_names = $4, npis = $5, license_number = $6,
+    license_state = $7, version = $8, last_updated_on = $9, attester_name = $10
+WHERE id = $1
+`
+
+type UpdateHospitalParams struct {
+	ID            int32       `json:"id"`
+	Name          string      `json:"name"`
+	Addresses     []string    `json:"addresses"`
+	LocationNames []string    `json:"location_names"`
+	Npis          []string    `json:"npis"`
+	LicenseNumber pgtype.Text `json:"license_number"`
+	LicenseState  pgtype.Text `json:"license_state"`
+	Version       string      `json:"version"`
+	LastUpdatedOn pgtype.Date `json:"last_updated_on"`
+	AttesterName  pgtype.Text `json:"attester_name"`
+}
+
+func (q *Queries) UpdateHospital(ctx context.Context, arg UpdateHospitalParams) error {
+	_, err := q.db.Exec(ctx, updateHospital,
+		arg.ID,
+		arg.Name,
+		arg.Addresses,
+		arg.LocationNames,
+		arg.Npis,
+		arg.LicenseNumber,
+		arg.LicenseState,
+		arg.Version,
+		arg.LastUpdatedOn,
+		arg.AttesterName,
+	)
+	return err
+}
+
 const upsertCode = `-- name: UpsertCode :one
 INSERT INTO codes (code, code_type)
 VALUES ($1, $2)
@@ -10,21 +10,26 @@ VALUES ($1, $2)
 ON CONFLICT (code, code_type) DO UPDATE SET code = EXCLUDED.code
 RETURNING id;
 
--- name: InsertItemCode :exec
-INSERT INTO item_codes (item_id, code_id)
-VALUES ($1, $2)
-ON CONFLICT (item_id, code_id) DO NOTHING;
+-- name: NextItemIDs :many
+SELECT nextval(pg_get_serial_sequence('standard_charge_items', 'id'))::int AS id
+FROM generate_series(1, sqlc.arg(count)::int);
 
--- name: InsertStandardChargeItem :one
-INSERT INTO standard_charge_items (hospital_id, description, drug_unit, drug_unit_type)
-VALUES ($1, $2, $3, $4)
-RETURNING id;
+-- name: NextChargeIDs :many
+SELECT nextval(pg_get_serial_sequence('standard_charges', 'id'))::int AS id
+FROM generate_series(1, sqlc.arg(count)::int);
 
--- name: InsertStandardCharge :one
+-- name: InsertStandardChargeItems :copyfrom
+INSERT INTO standard_charge_items (id, hospital_id, description, drug_unit, drug_unit_type)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: InsertItemCodes :copyfrom
+INSERT INTO item_codes (item_id, code_id)
+VALUES ($1, $2);
+
+-- name: InsertStandardCharges :copyfrom
 INSERT INTO standard_charges
-  (item_id, setting, gross_charge, discounted_cash, minimum, maximum, modifier_codes, additional_notes)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id;
+  (id, item_id, setting, gross_charge, discounted_cash, minimum, maximum, modifier_codes, additional_notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
 -- name: UpsertPlan :one
 INSERT INTO plans (name)
@@ -46,11 +51,99 @@ INSERT INTO payer_charges
    percentile_10th, percentile_90th, count, additional_notes)
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
 
+-- name: InsertModifier :one
+INSERT INTO modifiers (hospital_id, code, description, setting)
+VALUES ($1, $2, $3, $4)
+RETURNING id;
+
+-- name: InsertModifierPayerInfo :exec
+INSERT INTO modifier_payer_info (modifier_id, payer_id, plan_id, description)
+VALUES ($1, $2, $3, $4);
+
+-- name: FindHospitalCandidates :many
+SELECT id, name, addresses, npis, license_number, license_state, last_updated_on
+FROM hospitals
+WHERE (license_number = sqlc.narg(license_number) AND upper(license_state) = upper(sqlc.narg(license_state)))
+   OR npis && sqlc.arg(npis)::text[]
+   OR lower(name) = lower(sqlc.arg(name))
+ORDER BY last_updated_on DESC, id DESC;
+
+-- name: UpdateHospital :exec
+UPDATE hospitals
+SET name = $2, addresses = $3, location_names = $4, npis = $5, license_number = $6,
+    license_state = $7, version = $8, last_updated_on = $9, attester_name = $10
+WHERE id = $1;
+
+-- name: ArchiveHospital :one
+INSERT INTO archived_hospitals
+  (id, name, addresses, location_names, npis, license_number, license_state, version, last_updated_on, attester_name, created_at)
+SELECT id, name, addresses, location_names, npis, license_number, license_state, version, last_updated_on, attester_name, created_at
+FROM hospitals WHERE id = $1
+RETURNING archive_id;
+
+-- name: ArchiveHospitalItems :exec
+INSERT INTO archived_standard_charge_items
+  (id, hospital_id, description, drug_unit, drug_unit_type, created_at, archive_id)
+SELECT id, hospital_id, description, drug_unit, drug_unit_type, created_at, sqlc.arg(archive_id)::int
+FROM standard_charge_items WHERE hospital_id = sqlc.arg(hospital_id);
+
+-- name: ArchiveHospitalItemCodes :exec
+INSERT INTO archived_item_codes (id, item_id, code_id)
+SELECT ic.id, ic.item_id, ic.code_id
+FROM item_codes ic
+JOIN standard_charge_items sci ON sci.id = ic.item_id
+WHERE sci.hospital_id = $1;
+
+-- name: ArchiveHospitalCharges :exec
+INSERT INTO archived_standard_charges
+  (id, item_id, setting, gross_charge, discounted_cash, minimum, maximum, modifier_codes, additional_notes)
+SELECT sc.id, sc.item_id, sc.setting, sc.gross_charge, sc.discounted_cash, sc.minimum, sc.maximum,
+       sc.modifier_codes, sc.additional_notes
+FROM standard_charges sc
+JOIN standard_charge_items sci ON sci.id = sc.item_id
+WHERE sci.hospital_id = $1;
+
+-- name: ArchiveHospitalPayerCharges :exec
+INSERT INTO archived_payer_charges
+  (id, standard_charge_id, payer_id, plan_id, methodology,
+   standard_charge_dollar, standard_charge_percentage,
+   standard_charge_algorithm, estimated_amount, median_amount,
+   percentile_10th, percentile_90th, count, additional_notes)
+SELECT pc.id, pc.standard_charge_id, pc.payer_id, pc.plan_id, pc.methodology,
+       pc.standard_charge_dollar, pc.standard_charge_percentage,
+       pc.standard_charge_algorithm, pc.estimated_amount, pc.median_amount,
+       pc.percentile_10th, pc.percentile_90th, pc.count, pc.additional_notes
+FROM payer_charges pc
+JOIN standard_charges sc ON sc.id = pc.standard_charge_id
+JOIN standard_charge_items sci ON sci.id = sc.item_id
+WHERE sci.hospital_id = $1;
+
+-- name: ArchiveHospitalModifiers :exec
+INSERT INTO archived_modifiers (id, hospital_id, code, description, setting, archive_id)
+SELECT id, hospital_id, code, description, setting, sqlc.arg(archive_id)::int
+FROM modifiers WHERE hospital_id = sqlc.arg(hospital_id);
+
+-- name: ArchiveHospitalModifierPayerInfo :exec
+INSERT INTO archived_modifier_payer_info (id, modifier_id, payer_id, plan_id, description)
+SELECT mpi.id, mpi.modifier_id, mpi.payer_id, mpi.plan_id, mpi.description
+FROM modifier_payer_info mpi
+JOIN modifiers m ON m.id = mpi.modifier_id
+WHERE m.hospital_id = $1;
+
+-- name: DeleteHospitalItems :exec
+DELETE FROM standard_charge_items WHERE hospital_id = $1;
+
+-- name: DeleteHospitalModifiers :exec
+DELETE FROM modifiers WHERE hospital_id = $1;
+
 -- Read queries (used by tests)
 
 -- name: GetFirstHospital :one
 SELECT name, version, addresses[1]::text as first_address FROM hospitals LIMIT 1;
 
+-- name: GetHospitalLists :one
+SELECT addresses, location_names, npis, attester_name FROM hospitals LIMIT 1;
+
 -- name: CountItems :one
 SELECT count(*)::int FROM standard_charge_items;
 
@@ -96,9 +189,26 @@ SELECT drug_unit, drug_unit_type
 FROM standard_charge_items
 WHERE description = $1;
 
+-- name: CountModifiers :one
+SELECT count(*)::int FROM modifiers;
+
+-- name: ListModifierPayerInfo :many
+SELECT m.code, m.setting, py.name as payer_name, p.name as plan_name, mpi.description
+FROM modifier_payer_info mpi
+JOIN modifiers m ON m.id = mpi.modifier_id
+JOIN payers py ON py.id = mpi.payer_id
+JOIN plans p ON p.id = mpi.plan_id
+ORDER BY m.code, py.name;
+
 -- name: GetItemNotes :one
 SELECT sc.additional_notes as generic_notes, pc.additional_notes as payer_notes
 FROM standard_charges sc
 JOIN standard_charge_items sci ON sci.id = sc.item_id
 JOIN payer_charges pc ON pc.standard_charge_id = sc.id
 WHERE sci.description = $1;
+
+-- name: CountHospitals :one
+SELECT count(*)::int FROM hospitals;
+
+-- name: CountArchivedItems :one
+SELECT count(*)::int FROM archived_standard_charge_items;
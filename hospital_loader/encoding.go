@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// textEncoding is the character encoding of a hospital CSV. Many hospital
+// exports come from Excel on Windows and are Windows-1252, not UTF-8.
+type textEncoding int
+
+const (
+	// encodingAuto keeps valid UTF-8 and decodes bytes that are not part
+	// of a valid UTF-8 sequence as Windows-1252, cell by cell. A stray
+	// 0x96 en-dash or 0xE9 é is never valid UTF-8 on its own, so mixed
+	// files come out right too.
+	encodingAuto textEncoding = iota
+	encodingUTF8
+	encodingWindows1252
+	encodingLatin1
+)
+
+// parseEncoding parses an -encoding flag value.
+func parseEncoding(name string) (textEncoding, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return encodingAuto, nil
+	case "utf-8", "utf8":
+		return encodingUTF8, nil
+	case "windows-1252", "cp1252":
+		return encodingWindows1252, nil
+	case "latin-1", "latin1", "iso-8859-1":
+		return encodingLatin1, nil
+	}
+	return encodingAuto, fmt.Errorf("unknown encoding %q (want auto, utf-8, windows-1252 or latin-1)", name)
+}
+
+func (e textEncoding) String() string {
+	switch e {
+	case encodingUTF8:
+		return "utf-8"
+	case encodingWindows1252:
+		return "windows-1252"
+	case encodingLatin1:
+		return "latin-1"
+	}
+	return "auto"
+}
+
+// windows1252High maps bytes 0x80-0x9F, where Windows-1252 differs from
+// Latin-1. The five unassigned bytes map to the C1 control of the same
+// value, as the WHATWG decoder does.
+var windows1252High = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+// transcode returns s as UTF-8 and whether any byte needed decoding.
+// encodingUTF8 returns s unchanged; invalid bytes are left for the
+// callers' ToValidUTF8 replacement.
+func (e textEncoding) transcode(s string) (string, bool) {
+	switch e {
+	case encodingUTF8:
+		return s, false
+	case encodingAuto:
+		if utf8.ValidString(s) {
+			return s, false
+		}
+	default:
+		if isASCII(s) {
+			return s, false
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		if e == encodingAuto {
+			if r, size := utf8.DecodeRuneInString(s[i:]); r != utf8.RuneError || size > 1 {
+				b.WriteString(s[i : i+size])
+				i += size
+				continue
+			}
+		}
+		if e != encodingLatin1 && c < 0xA0 {
+			b.WriteRune(windows1252High[c-0x80])
+		} else {
+			b.WriteRune(rune(c))
+		}
+		i++
+	}
+	return b.String(), true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestTranscode(t *testing.T) {
+	for _, tc := range []struct {
+		enc  textEncoding
+		in   string
+		want string
+		ok   bool
+	}{
+		{encodingAuto, "plain ascii", "plain ascii", false},
+		{encodingAuto, "Café", "Café", false},
+		{encodingAuto, "Caf\xe9 \x80 5", "Café € 5", true},
+		{encodingAuto, "Café \x96 bar", "Café – bar", true},
+		{encodingWindows1252, "\x93quoted\x94 \x81", "“quoted” \u0081", true},
+		{encodingLatin1, "Caf\xe9 \x96", "Café \u0096", true},
+		{encodingLatin1, "CafÃ©", "CafÃ\u0083Â©", true},
+		{encodingUTF8, "Caf\xe9", "Caf\xe9", false},
+	} {
+		got, ok := tc.enc.transcode(tc.in)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("%s.transcode(%q) = %q, %v; want %q, %v", tc.enc, tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	for name, want := range map[string]textEncoding{
+		"":           encodingAuto,
+		"UTF-8":      encodingUTF8,
+		"cp1252":     encodingWindows1252,
+		"ISO-8859-1": encodingLatin1,
+	} {
+		if got, err := parseEncoding(name); err != nil || got != want {
+			t.Errorf("parseEncoding(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := parseEncoding("utf-16"); err == nil {
+		t.Error("parseEncoding(utf-16) succeeded, want error")
+	}
+}
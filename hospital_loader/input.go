@@ -0,0 +1,208 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	inputBufSize = 256 * 1024
+	zipMagic     = "PK\x03\x04"
+	gzipMagic    = "\x1f\x8b"
+	parquetMagic = "PAR1"
+)
+
+// inputMember is one hospital file within an input: the input file itself,
+// or one member of a zip archive. Hospitals publishing several campuses
+// often zip one CSV per campus; each member converts as its own hospital.
+type inputMember struct {
+	Name string // input path, or member path inside the zip
+	Size int64  // bytes on disk (compressed size for zip members)
+	open func() (io.ReadCloser, error)
+}
+
+// Open opens the member, decompressing gzip content.
+func (m inputMember) Open() (*inputReader, error) {
+	rc, err := m.open()
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", m.Name, err)
+	}
+	return newInputReader(rc)
+}
+
+// inputReader is a buffered, decompressed input stream.
+type inputReader struct {
+	*bufio.Reader
+	closers []io.Closer
+}
+
+// newInputReader buffers rc and unwraps gzip detected from the magic
+// bytes, so .gz files need no particular extension.
+func newInputReader(rc io.ReadCloser) (*inputReader, error) {
+	br := bufio.NewReaderSize(rc, inputBufSize)
+	in := &inputReader{Reader: br, closers: []io.Closer{rc}}
+	if magic, _ := br.Peek(len(gzipMagic)); string(magic) != gzipMagic {
+		return in, nil
+	}
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		rc.Close()
+		return nil, fmt.Errorf("create gzip reader: %w", err)
+	}
+	in.Reader = bufio.NewReaderSize(gz, inputBufSize)
+	in.closers = append([]io.Closer{gz}, in.closers...)
+	return in, nil
+}
+
+// Close closes the decompressor and the underlying file.
+func (in *inputReader) Close() error {
+	var err error
+	for _, c := range in.closers {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// IsJSON reports whether the content is JSON rather than CSV: the first
+// non-blank byte after an optional UTF-8 BOM is '{' or '['.
+func (in *inputReader) IsJSON() bool {
+	head, _ := in.Peek(512)
+	return looksLikeJSON(head)
+}
+
+func looksLikeJSON(head []byte) bool {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	head = bytes.TrimLeft(head, " \t\r\n")
+	return len(head) > 0 && (head[0] == '{' || head[0] == '[')
+}
+
+// openInputFile opens a single (optionally gzipped) file.
+func openInputFile(filename string) (*inputReader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", filename, err)
+	}
+	return newInputReader(f)
+}
+
+// isParquetFile reports whether filename starts with the Parquet magic
+// bytes, so -pg can tell converted output from a CSV/JSON source.
+func isParquetFile(filename string) bool {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	magic := make([]byte, len(parquetMagic))
+	n, _ := io.ReadFull(f, magic)
+	return string(magic[:n]) == parquetMagic
+}
+
+// listInputMembers returns the hospital files in filename: each member of
+// a zip archive, or the file itself. Zip members that are not hospital
+// files (READMEs, PDFs, spreadsheets) are skipped with a note on stderr.
+// The close function releases the archive.
+func listInputMembers(filename string) ([]inputMember, func() error, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open %s: %w", filename, err)
+	}
+	magic := make([]byte, len(zipMagic))
+	n, _ := io.ReadFull(f, magic)
+	fi, statErr := f.Stat()
+	f.Close()
+	if statErr != nil {
+		return nil, nil, fmt.Errorf("stat %s: %w", filename, statErr)
+	}
+
+	if string(magic[:n]) != zipMagic {
+		member := inputMember{
+			Name: filename,
+			Size: fi.Size(),
+			open: func() (io.ReadCloser, error) { return os.Open(filename) },
+		}
+		return []inputMember{member}, func() error { return nil }, nil
+	}
+
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open zip %s: %w", filename, err)
+	}
+	var members []inputMember
+	for _, zf := range zr.File {
+		if skipZipMember(zf) {
+			continue
+		}
+		if !isHospitalMember(zf) {
+			fmt.Fprintf(os.Stderr, "Skipping %s: not a CSV or JSON hospital file\n", zf.Name)
+			continue
+		}
+		members = append(members, inputMember{
+			Name: zf.Name,
+			Size: int64(zf.CompressedSize64),
+			open: zf.Open,
+		})
+	}
+	if len(members) == 0 {
+		zr.Close()
+		return nil, nil, fmt.Errorf("zip %s contains no CSV or JSON files", filename)
+	}
+	return members, zr.Close, nil
+}
+
+// skipZipMember reports whether a zip entry is a directory or archiver
+// metadata (macOS resource forks, dot files) rather than a hospital file.
+func skipZipMember(zf *zip.File) bool {
+	if zf.FileInfo().IsDir() || strings.HasPrefix(zf.Name, "__MACOSX/") {
+		return true
+	}
+	return strings.HasPrefix(path.Base(zf.Name), ".")
+}
+
+// isHospitalMember reports whether a zip member is a hospital file: named
+// .csv or .json (optionally .gz), or with gzip or JSON content. Hospital
+// zips often carry a README or PDF alongside the charges.
+func isHospitalMember(zf *zip.File) bool {
+	name := strings.ToLower(zf.Name)
+	if strings.HasSuffix(name, ".gz") {
+		return true
+	}
+	if ext := path.Ext(name); ext == ".csv" || ext == ".json" {
+		return true
+	}
+	rc, err := zf.Open()
+	if err != nil {
+		// Let the convert report it
+		return true
+	}
+	defer rc.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(rc, head)
+	head = head[:n]
+	return bytes.HasPrefix(head, []byte(gzipMagic)) || looksLikeJSON(head)
+}
+
+// trimInputExt strips a .gz suffix and the format extension:
+// "dir/hospital.csv.gz" → "dir/hospital".
+func trimInputExt(name string) string {
+	if strings.EqualFold(path.Ext(name), ".gz") {
+		name = name[:len(name)-len(".gz")]
+	}
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// memberOutputPath names the output for one member of a multi-file zip:
+// out.parquet + "campus/north.csv" → out_campus_north.parquet.
+func memberOutputPath(outputPath, memberName string) string {
+	stem := strings.NewReplacer("/", "_", " ", "_").Replace(trimInputExt(memberName))
+	return strings.TrimSuffix(outputPath, ".parquet") + "_" + stem + ".parquet"
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const inputTestCSV = `hospital_name,last_updated_on,version,hospital_location,hospital_address
+%s,2024-01-15,2.0.0,Somewhere,1 Main St
+description,setting,code|1,code|1|type,standard_charge|gross
+X-RAY CHEST,outpatient,71046,CPT,250.00
+`
+
+const inputTestJSON = `{"hospital_name": "Gzip Hospital", "last_updated_on": "2024-01-15", "version": "2.0.0",
+ "standard_charge_information": [{"description": "MRI BRAIN",
+   "code_information": [{"code": "70553", "type": "CPT"}],
+   "standard_charges": [{"setting": "inpatient", "gross_charges": 3500}]}]}`
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func csvFor(hospital string) string {
+	return fmt.Sprintf(inputTestCSV, hospital)
+}
+
+// TestConvertZipMembers converts a zip holding one plain and one gzipped
+// CSV and a JSON file without an extension, plus entries that must be
+// skipped, into one output per member.
+func TestConvertZipMembers(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "campuses.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, e := range []struct {
+		name string
+		data []byte
+	}{
+		{"north/standard_charges.csv", []byte(csvFor("North Campus"))},
+		{"south_standard_charges.csv.gz", gzipBytes(t, csvFor("South Campus"))},
+		{"__MACOSX/north/._standard_charges.csv", []byte("junk")},
+		{".DS_Store", []byte("junk")},
+		{"empty/", nil},
+		{"east", []byte(inputTestJSON)},
+		{"README.txt", []byte("Standard charges for North and South campuses.\n")},
+		{"notice.pdf", []byte("%PDF-1.7\n")},
+		{"charges.xlsx", []byte("PK\x03\x04junk")},
+	} {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	out := filepath.Join(dir, "campuses.parquet")
+	if err := convert(zipPath, out, 100, encodingAuto); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	for path, hospital := range map[string]string{
+		filepath.Join(dir, "campuses_north_standard_charges.parquet"): "North Campus",
+		filepath.Join(dir, "campuses_south_standard_charges.parquet"): "South Campus",
+		filepath.Join(dir, "campuses_east.parquet"):                   "Gzip Hospital",
+	} {
+		rows := readParquet(t, path)
+		if len(rows) != 1 || rows[0].HospitalName != hospital {
+			t.Errorf("%s: %d rows, want 1 from %s", filepath.Base(path), len(rows), hospital)
+		}
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("multi-member zip also wrote %s", out)
+	}
+}
+
+// TestConvertDetectsContent converts gzipped JSON whose name gives no hint
+// of either compression or format.
+func TestConvertDetectsContent(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "download.bin")
+	if err := os.WriteFile(input, gzipBytes(t, "\xef\xbb\xbf\n"+inputTestJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "out.parquet")
+	if err := convert(input, out, 100, encodingAuto); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	rows := readParquet(t, out)
+	if len(rows) != 1 || rows[0].HospitalName != "Gzip Hospital" {
+		t.Fatalf("got %d rows: %+v", len(rows), rows)
+	}
+	assertStrPtrEq(t, "CPTCode", rows[0].CPTCode, strPtr("70553"))
+}
+
+func TestMemberOutputPath(t *testing.T) {
+	for _, tc := range []struct{ member, want string }{
+		{"north.csv", "out_north.parquet"},
+		{"campus/South Side.json.gz", "out_campus_South_Side.parquet"},
+	} {
+		if got := memberOutputPath("out.parquet", tc.member); got != tc.want {
+			t.Errorf("memberOutputPath(%q) = %q, want %q", tc.member, got, tc.want)
+		}
+	}
+	if got := trimInputExt("hospital.csv.gz"); got != "hospital" {
+		t.Errorf("trimInputExt = %q, want hospital", got)
+	}
+}
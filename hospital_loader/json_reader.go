@@ -1,11 +1,9 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 )
 
@@ -13,21 +11,30 @@ import (
 // and emits HospitalChargeRow records one item at a time. Only one jsonItem
 // is in memory at a time — decoded, expanded, then discarded.
 type JSONReader struct {
-	file    *os.File
+	input   io.Closer
 	decoder *json.Decoder
 	meta    hospitalMeta
 	format  string // "json-v2" or "json-v3"
 	itemNum int64
-	done    bool
+	// modifiers collects modifier_information, which may come before or
+	// after standard_charge_information.
+	modifiers []ModifierRow
+	codes     []codePair // code_information of the last item, for -validate
+	done      bool
 }
 
+// NewJSONReader opens a JSON file, decompressing gzip content.
 func NewJSONReader(filepath string) (*JSONReader, error) {
-	file, err := os.Open(filepath)
+	in, err := openInputFile(filepath)
 	if err != nil {
-		return nil, fmt.Errorf("open %s: %w", filepath, err)
+		return nil, err
 	}
+	return newJSONReader(in)
+}
 
-	bufReader := bufio.NewReaderSize(file, 256*1024)
+// newJSONReader reads JSON from an opened input and takes ownership of it.
+func newJSONReader(in *inputReader) (*JSONReader, error) {
+	bufReader := in.Reader
 
 	// Skip UTF-8 BOM if present
 	bom, err := bufReader.Peek(3)
@@ -38,13 +45,13 @@ func NewJSONReader(filepath string) (*JSONReader, error) {
 	decoder := json.NewDecoder(bufReader)
 
 	r := &JSONReader{
-		file:    file,
+		input:   in,
 		decoder: decoder,
 		format:  "json",
 	}
 
 	if err := r.readHeader(); err != nil {
-		file.Close()
+		in.Close()
 		return nil, err
 	}
 
@@ -104,21 +111,28 @@ func (r *JSONReader) readHeader() error {
 			if err := r.decoder.Decode(&v); err != nil {
 				return fmt.Errorf("decode hospital_address: %w", err)
 			}
-			r.meta.hospitalAddress = strings.Join(v, "; ")
+			r.meta.hospitalAddresses = v
 
 		case "hospital_location":
 			var v []string
 			if err := r.decoder.Decode(&v); err != nil {
 				return fmt.Errorf("decode hospital_location: %w", err)
 			}
-			r.meta.hospitalLocation = strings.Join(v, "; ")
+			r.meta.hospitalLocations = v
 
 		case "location_name":
 			var v []string
 			if err := r.decoder.Decode(&v); err != nil {
 				return fmt.Errorf("decode location_name: %w", err)
 			}
-			r.meta.hospitalLocation = strings.Join(v, "; ")
+			r.meta.hospitalLocations = v
+
+		case "type_2_npi":
+			var v []string
+			if err := r.decoder.Decode(&v); err != nil {
+				return fmt.Errorf("decode type_2_npi: %w", err)
+			}
+			r.meta.type2NPIs = v
 
 		case "license_information":
 			var v jsonLicense
@@ -144,6 +158,10 @@ func (r *JSONReader) readHeader() error {
 				return fmt.Errorf("decode attestation: %w", err)
 			}
 			r.meta.affirmation = v.ConfirmAttestation
+			if v.AttesterName != "" {
+				name := v.AttesterName
+				r.meta.attesterName = &name
+			}
 
 		case "standard_charge_information":
 			// Read opening '[' — decoder is now positioned at first array element
@@ -156,8 +174,13 @@ func (r *JSONReader) readHeader() error {
 			}
 			return nil
 
+		case "modifier_information":
+			if err := r.readModifiers(); err != nil {
+				return err
+			}
+
 		default:
-			// Skip unknown fields (modifier_information, general_contract_provisions, etc.)
+			// Skip unknown fields (general_contract_provisions, etc.)
 			var skip json.RawMessage
 			if err := r.decoder.Decode(&skip); err != nil {
 				return fmt.Errorf("skip field %q: %w", key, err)
@@ -181,6 +204,9 @@ func (r *JSONReader) Next() ([]HospitalChargeRow, error) {
 		// Read closing ']'
 		r.decoder.Token()
 		r.done = true
+		if err := r.readTrailer(); err != nil {
+			return nil, err
+		}
 		return nil, io.EOF
 	}
 
@@ -197,21 +223,25 @@ func (r *JSONReader) Next() ([]HospitalChargeRow, error) {
 func (r *JSONReader) expandItem(item *jsonItem) []HospitalChargeRow {
 	// Build base row with hospital metadata
 	base := HospitalChargeRow{
-		HospitalName:     r.meta.hospitalName,
-		LastUpdatedOn:    r.meta.lastUpdatedOn,
-		Version:          r.meta.version,
-		HospitalLocation: r.meta.hospitalLocation,
-		HospitalAddress:  r.meta.hospitalAddress,
-		LicenseNumber:    r.meta.licenseNumber,
-		LicenseState:     r.meta.licenseState,
-		Affirmation:      r.meta.affirmation,
+		HospitalName:      r.meta.hospitalName,
+		LastUpdatedOn:     r.meta.lastUpdatedOn,
+		Version:           r.meta.version,
+		HospitalLocations: r.meta.hospitalLocations,
+		HospitalAddresses: r.meta.hospitalAddresses,
+		Type2NPIs:         r.meta.type2NPIs,
+		LicenseNumber:     r.meta.licenseNumber,
+		LicenseState:      r.meta.licenseState,
+		Affirmation:       r.meta.affirmation,
+		AttesterName:      r.meta.attesterName,
 
 		Description: strings.ToValidUTF8(item.Description, "\uFFFD"),
 	}
 
 	// Set codes
+	r.codes = r.codes[:0]
 	for _, ci := range item.CodeInformation {
 		base.SetCode(strings.ToUpper(ci.Type), ci.Code)
+		r.codes = append(r.codes, codePair{code: strings.TrimSpace(ci.Code), codeType: strings.ToUpper(strings.TrimSpace(ci.Type))})
 	}
 
 	// Set drug information
@@ -271,6 +301,12 @@ func (r *JSONReader) expandItem(item *jsonItem) []HospitalChargeRow {
 			prow.NegotiatedPercentage = p.StandardChargePercentage
 			prow.NegotiatedAlgorithm = p.StandardChargeAlgorithm
 			prow.EstimatedAmount = p.EstimatedAmount
+			prow.MedianAmount = p.MedianAmount
+			prow.Percentile10th = p.Percentile10th
+			prow.Percentile90th = p.Percentile90th
+			if p.Count != nil {
+				prow.Count = p.Count.Value
+			}
 
 			if p.Methodology != "" {
 				m := strings.ToValidUTF8(p.Methodology, "\uFFFD")
@@ -288,6 +324,85 @@ func (r *JSONReader) expandItem(item *jsonItem) []HospitalChargeRow {
 	return rows
 }
 
+// readTrailer reads the top-level fields after standard_charge_information,
+// picking up modifier_information when the file places it last.
+func (r *JSONReader) readTrailer() error {
+	for r.decoder.More() {
+		tok, err := r.decoder.Token()
+		if err != nil {
+			return fmt.Errorf("read field name: %w", err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected string key, got %T", tok)
+		}
+		if key == "modifier_information" {
+			if err := r.readModifiers(); err != nil {
+				return err
+			}
+			continue
+		}
+		var skip json.RawMessage
+		if err := r.decoder.Decode(&skip); err != nil {
+			return fmt.Errorf("skip field %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// readModifiers decodes modifier_information into one ModifierRow per
+// modifier × payer/plan.
+func (r *JSONReader) readModifiers() error {
+	var mods []jsonModifier
+	if err := r.decoder.Decode(&mods); err != nil {
+		return fmt.Errorf("decode modifier_information: %w", err)
+	}
+	for i := range mods {
+		m := &mods[i]
+		base := ModifierRow{
+			Code:        strings.ToValidUTF8(m.Code, "\uFFFD"),
+			Description: strings.ToValidUTF8(m.Description, "\uFFFD"),
+		}
+		if m.Setting != "" {
+			s := m.Setting
+			base.Setting = &s
+		}
+		if len(m.ModifierPayerInformation) == 0 {
+			r.modifiers = append(r.modifiers, base)
+			continue
+		}
+		for j := range m.ModifierPayerInformation {
+			p := &m.ModifierPayerInformation[j]
+			row := base // struct copy
+			pn := strings.ToValidUTF8(p.PayerName, "\uFFFD")
+			pl := strings.ToValidUTF8(p.PlanName, "\uFFFD")
+			d := strings.ToValidUTF8(p.Description, "\uFFFD")
+			row.PayerName = &pn
+			row.PlanName = &pl
+			row.PayerDescription = &d
+			r.modifiers = append(r.modifiers, row)
+		}
+	}
+	return nil
+}
+
+// Modifiers returns the modifier_information rows. Complete once Next has
+// returned io.EOF.
+func (r *JSONReader) Modifiers() []ModifierRow {
+	return r.modifiers
+}
+
+// Meta returns the hospital metadata read before the items.
+func (r *JSONReader) Meta() hospitalMeta {
+	return r.meta
+}
+
+// Codes returns the code_information of the last item as written,
+// including types SetCode does not know.
+func (r *JSONReader) Codes() []codePair {
+	return r.codes
+}
+
 // ItemNum returns the number of items read so far.
 func (r *JSONReader) ItemNum() int64 {
 	return r.itemNum
@@ -300,8 +415,8 @@ func (r *JSONReader) Format() string {
 
 // Close closes the underlying file.
 func (r *JSONReader) Close() error {
-	if r.file != nil {
-		return r.file.Close()
+	if r.input != nil {
+		return r.input.Close()
 	}
 	return nil
 }
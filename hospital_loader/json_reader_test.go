@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/parquet-go/parquet-go"
@@ -108,13 +109,12 @@ func TestJSONReaderV2ToParquet(t *testing.T) {
 		if row.Version != "2.0.0" {
 			t.Errorf("row[%d].Version = %q", i, row.Version)
 		}
-		// hospital_location is joined with "; "
-		if row.HospitalLocation != "Test Community Hospital; 456 Oak Ave, Brooklyn, NY 11201" {
-			t.Errorf("row[%d].HospitalLocation = %q", i, row.HospitalLocation)
+		// hospital_location and hospital_address stay lists
+		if want := []string{"Test Community Hospital", "456 Oak Ave, Brooklyn, NY 11201"}; !slices.Equal(row.HospitalLocations, want) {
+			t.Errorf("row[%d].HospitalLocations = %q, want %q", i, row.HospitalLocations, want)
 		}
-		// hospital_address is joined with "; "
-		if row.HospitalAddress != "456 Oak Ave; Brooklyn, NY 11201" {
-			t.Errorf("row[%d].HospitalAddress = %q", i, row.HospitalAddress)
+		if want := []string{"456 Oak Ave", "Brooklyn, NY 11201"}; !slices.Equal(row.HospitalAddresses, want) {
+			t.Errorf("row[%d].HospitalAddresses = %q, want %q", i, row.HospitalAddresses, want)
 		}
 		assertStrPtrEq(t, "LicenseNumber", row.LicenseNumber, strPtr("H-99887"))
 		assertStrPtrEq(t, "LicenseState", row.LicenseState, strPtr("NY"))
@@ -255,8 +255,8 @@ func TestJSONReaderV3ToParquet(t *testing.T) {
 			t.Errorf("row[%d].Version = %q", i, row.Version)
 		}
 		// V3 uses location_name instead of hospital_location
-		if row.HospitalLocation != "Metro Health Center; 789 Elm St, Manhattan, NY 10010" {
-			t.Errorf("row[%d].HospitalLocation = %q", i, row.HospitalLocation)
+		if want := []string{"Metro Health Center", "789 Elm St, Manhattan, NY 10010"}; !slices.Equal(row.HospitalLocations, want) {
+			t.Errorf("row[%d].HospitalLocations = %q, want %q", i, row.HospitalLocations, want)
 		}
 		assertStrPtrEq(t, "LicenseNumber", row.LicenseNumber, strPtr("MHC-5544"))
 		assertStrPtrEq(t, "LicenseState", row.LicenseState, strPtr("NY"))
@@ -315,3 +315,127 @@ func TestJSONReaderV3ToParquet(t *testing.T) {
 		assertStrPtrEq(t, "roundtrip Methodology", p.Methodology, j.Methodology)
 	}
 }
+
+// TestJSONReaderV3AllowedAmounts checks the V3 payer median, percentile and
+// count fields, including a count written as a bare number, and the V3
+// hospital lists and attester name.
+func TestJSONReaderV3AllowedAmounts(t *testing.T) {
+	jsonPath := filepath.Join(t.TempDir(), "v3_allowed.json")
+	content := `{
+  "hospital_name": "V3 Hospital",
+  "last_updated_on": "2025-01-01",
+  "version": "3.0.0",
+  "hospital_address": ["1 State St, Albany, NY", "2 East Rd, Albany, NY"],
+  "location_name": ["V3 Hospital", "V3 Hospital East"],
+  "type_2_npi": ["1234567890", "1987654321"],
+  "attestation": {"attestation": "To the best of its knowledge and belief...", "confirm_attestation": true, "attester_name": "Pat Smith"},
+  "standard_charge_information": [{
+    "description": "KNEE ARTHROSCOPY",
+    "code_information": [{"code": "29881", "type": "CPT"}],
+    "standard_charges": [{
+      "setting": "outpatient",
+      "gross_charge": 9000,
+      "payers_information": [
+        {"payer_name": "Aetna", "plan_name": "PPO", "methodology": "percent_of_total_billed_charges",
+         "standard_charge_percentage": 45, "median_amount": 4100.5,
+         "10th_percentile": 3200, "90th_percentile": 5300, "count": "1 through 10"},
+        {"payer_name": "Cigna", "plan_name": "HMO", "methodology": "other",
+         "standard_charge_algorithm": "see contract", "count": 0}
+      ]
+    }]
+  }]
+}`
+	if err := os.WriteFile(jsonPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parquetPath, _ := jsonToParquet(t, jsonPath)
+	rows := readParquetJSON(t, parquetPath)
+	if len(rows) != 2 {
+		t.Fatalf("parquet has %d rows, want 2", len(rows))
+	}
+
+	r := rows[0]
+	if want := []string{"1 State St, Albany, NY", "2 East Rd, Albany, NY"}; !slices.Equal(r.HospitalAddresses, want) {
+		t.Errorf("HospitalAddresses = %q, want %q", r.HospitalAddresses, want)
+	}
+	if want := []string{"V3 Hospital", "V3 Hospital East"}; !slices.Equal(r.HospitalLocations, want) {
+		t.Errorf("HospitalLocations = %q, want %q", r.HospitalLocations, want)
+	}
+	if want := []string{"1234567890", "1987654321"}; !slices.Equal(r.Type2NPIs, want) {
+		t.Errorf("Type2NPIs = %q, want %q", r.Type2NPIs, want)
+	}
+	assertStrPtrEq(t, "AttesterName", r.AttesterName, strPtr("Pat Smith"))
+	if !r.Affirmation {
+		t.Error("Affirmation = false, want true")
+	}
+	assertStrPtrEq(t, "row[0].PayerName", r.PayerName, strPtr("Aetna"))
+	assertF64PtrEq(t, "row[0].MedianAmount", r.MedianAmount, f64Ptr(4100.5))
+	assertF64PtrEq(t, "row[0].Percentile10th", r.Percentile10th, f64Ptr(3200))
+	assertF64PtrEq(t, "row[0].Percentile90th", r.Percentile90th, f64Ptr(5300))
+	assertStrPtrEq(t, "row[0].Count", r.Count, strPtr("1 through 10"))
+
+	r = rows[1]
+	assertStrPtrEq(t, "row[1].PayerName", r.PayerName, strPtr("Cigna"))
+	assertF64PtrEq(t, "row[1].MedianAmount", r.MedianAmount, nil)
+	assertStrPtrEq(t, "row[1].Count", r.Count, strPtr("0"))
+}
+
+// TestJSONReaderModifiers checks modifier_information placed after
+// standard_charge_information and its side file written by convert.
+func TestJSONReaderModifiers(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "modifiers.json")
+	content := `{
+  "hospital_name": "Modifier Hospital",
+  "last_updated_on": "2025-01-01",
+  "version": "3.0.0",
+  "standard_charge_information": [{
+    "description": "KNEE ARTHROSCOPY",
+    "code_information": [{"code": "29881", "type": "CPT"}],
+    "standard_charges": [{"setting": "outpatient", "gross_charge": 9000, "modifier_code": ["50"]}]
+  }],
+  "modifier_information": [
+    {"code": "50", "description": "Bilateral procedure", "setting": "outpatient",
+     "modifier_payer_information": [
+       {"payer_name": "Aetna", "plan_name": "PPO", "description": "150% of the fee schedule"},
+       {"payer_name": "Cigna", "plan_name": "HMO", "description": "Paid at 200%"}
+     ]},
+    {"code": "26", "description": "Professional component", "modifier_payer_information": []}
+  ]
+}`
+	if err := os.WriteFile(jsonPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outPath := filepath.Join(dir, "modifiers.parquet")
+	if err := convert(jsonPath, outPath, 100, encodingAuto); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if rows := readParquetJSON(t, outPath); len(rows) != 1 {
+		t.Fatalf("charges parquet has %d rows, want 1", len(rows))
+	}
+
+	mods, err := parquet.ReadFile[ModifierRow](modifiersPath(outPath))
+	if err != nil {
+		t.Fatalf("read modifiers: %v", err)
+	}
+	if len(mods) != 3 {
+		t.Fatalf("modifiers parquet has %d rows, want 3", len(mods))
+	}
+	m := mods[0]
+	if m.Code != "50" || m.Description != "Bilateral procedure" {
+		t.Errorf("mods[0] = %q %q", m.Code, m.Description)
+	}
+	assertStrPtrEq(t, "mods[0].Setting", m.Setting, strPtr("outpatient"))
+	assertStrPtrEq(t, "mods[0].PayerName", m.PayerName, strPtr("Aetna"))
+	assertStrPtrEq(t, "mods[0].PlanName", m.PlanName, strPtr("PPO"))
+	assertStrPtrEq(t, "mods[0].PayerDescription", m.PayerDescription, strPtr("150% of the fee schedule"))
+	assertStrPtrEq(t, "mods[1].PayerName", mods[1].PayerName, strPtr("Cigna"))
+	// No payer information: one row with null payer columns
+	m = mods[2]
+	if m.Code != "26" {
+		t.Errorf("mods[2].Code = %q, want 26", m.Code)
+	}
+	assertStrPtrEq(t, "mods[2].Setting", m.Setting, nil)
+	assertStrPtrEq(t, "mods[2].PayerName", m.PayerName, nil)
+}
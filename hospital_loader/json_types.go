@@ -36,14 +36,40 @@ func (f *FlexibleFloat) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// FlexibleString handles JSON values that may be a string or a bare
+// number. Needed for V3 count, which the schema defines as a string
+// ("1 through 10") but some files write as a number.
+type FlexibleString struct {
+	Value *string
+}
+
+func (f *FlexibleString) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		if str != "" {
+			f.Value = &str
+		}
+		return nil
+	}
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err == nil {
+		s := num.String()
+		f.Value = &s
+		return nil
+	}
+	f.Value = nil
+	return nil
+}
+
 type jsonLicense struct {
 	LicenseNumber *string `json:"license_number,omitempty"`
 	State         string  `json:"state"`
 }
 
 type jsonAttestation struct {
-	ConfirmAttestation bool `json:"confirm_attestation"`
-	ConfirmAffirmation bool `json:"confirm_affirmation"`
+	ConfirmAttestation bool   `json:"confirm_attestation"`
+	ConfirmAffirmation bool   `json:"confirm_affirmation"`
+	AttesterName       string `json:"attester_name"` // V3
 }
 
 type jsonCode struct {
@@ -57,26 +83,43 @@ type jsonDrug struct {
 }
 
 type jsonPayer struct {
-	PayerName                string   `json:"payer_name"`
-	PlanName                 string   `json:"plan_name"`
-	Methodology              string   `json:"methodology"`
-	StandardChargeDollar     *float64 `json:"standard_charge_dollar,omitempty"`
-	StandardChargePercentage *float64 `json:"standard_charge_percentage,omitempty"`
-	StandardChargeAlgorithm  *string  `json:"standard_charge_algorithm,omitempty"`
-	EstimatedAmount          *float64 `json:"estimated_amount,omitempty"`
-	AdditionalPayerNotes     *string  `json:"additional_payer_notes,omitempty"`
+	PayerName                string          `json:"payer_name"`
+	PlanName                 string          `json:"plan_name"`
+	Methodology              string          `json:"methodology"`
+	StandardChargeDollar     *float64        `json:"standard_charge_dollar,omitempty"`
+	StandardChargePercentage *float64        `json:"standard_charge_percentage,omitempty"`
+	StandardChargeAlgorithm  *string         `json:"standard_charge_algorithm,omitempty"`
+	EstimatedAmount          *float64        `json:"estimated_amount,omitempty"`
+	MedianAmount             *float64        `json:"median_amount,omitempty"`
+	Percentile10th           *float64        `json:"10th_percentile,omitempty"`
+	Percentile90th           *float64        `json:"90th_percentile,omitempty"`
+	Count                    *FlexibleString `json:"count,omitempty"`
+	AdditionalPayerNotes     *string         `json:"additional_payer_notes,omitempty"`
 }
 
 type jsonCharge struct {
-	Setting                string          `json:"setting"`
-	GrossCharge            *float64        `json:"gross_charge,omitempty"`
-	GrossCharges           *FlexibleFloat  `json:"gross_charges,omitempty"`
-	DiscountedCash         *float64        `json:"discounted_cash,omitempty"`
-	Minimum                *float64        `json:"minimum,omitempty"`
-	Maximum                *float64        `json:"maximum,omitempty"`
-	ModifierCode           []string        `json:"modifier_code,omitempty"`
-	PayersInformation      []jsonPayer     `json:"payers_information,omitempty"`
-	AdditionalGenericNotes *string         `json:"additional_generic_notes,omitempty"`
+	Setting                string         `json:"setting"`
+	GrossCharge            *float64       `json:"gross_charge,omitempty"`
+	GrossCharges           *FlexibleFloat `json:"gross_charges,omitempty"`
+	DiscountedCash         *float64       `json:"discounted_cash,omitempty"`
+	Minimum                *float64       `json:"minimum,omitempty"`
+	Maximum                *float64       `json:"maximum,omitempty"`
+	ModifierCode           []string       `json:"modifier_code,omitempty"`
+	PayersInformation      []jsonPayer    `json:"payers_information,omitempty"`
+	AdditionalGenericNotes *string        `json:"additional_generic_notes,omitempty"`
+}
+
+type jsonModifierPayer struct {
+	PayerName   string `json:"payer_name"`
+	PlanName    string `json:"plan_name"`
+	Description string `json:"description"`
+}
+
+type jsonModifier struct {
+	Description              string              `json:"description"`
+	Code                     string              `json:"code"`
+	Setting                  string              `json:"setting,omitempty"`
+	ModifierPayerInformation []jsonModifierPayer `json:"modifier_payer_information"`
 }
 
 type jsonItem struct {
@@ -6,8 +6,10 @@ import (
 	"io"
 	"math/big"
 	"os"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 
 	"hospital_loader/db"
 
@@ -17,9 +19,44 @@ import (
 	"github.com/parquet-go/parquet-go"
 )
 
-func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize int, skipPayerCharges bool) error {
-	start := time.Now()
+// rowSource feeds HospitalChargeRows to loadRowsToPg. Next may return rows
+// together with io.EOF.
+type rowSource interface {
+	Next() ([]HospitalChargeRow, error)
+}
+
+// parquetSource reads a Parquet file in fixed-size batches.
+type parquetSource struct {
+	reader *parquet.GenericReader[HospitalChargeRow]
+	buf    []HospitalChargeRow
+}
+
+func (s *parquetSource) Next() ([]HospitalChargeRow, error) {
+	n, err := s.reader.Read(s.buf)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("read parquet: %w", err)
+	}
+	return s.buf[:n], err
+}
 
+// chargeSource streams a CSV or JSON reader into loadRowsToPg, adding the
+// row or item number to read errors.
+type chargeSource struct {
+	chargeReader
+}
+
+func (s chargeSource) Next() ([]HospitalChargeRow, error) {
+	rows, err := s.chargeReader.Next()
+	if err != nil && err != io.EOF {
+		return nil, readError(s.chargeReader, err)
+	}
+	return rows, err
+}
+
+// loadParquetToPg loads a converted Parquet file, and its modifiers side
+// file if present, into PostgreSQL. See loadRowsToPg for how a hospital
+// that is already stored is handled.
+func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize int, skipPayerCharges, archive bool) error {
 	f, err := os.Open(parquetPath)
 	if err != nil {
 		return fmt.Errorf("open parquet: %w", err)
@@ -40,26 +77,128 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 	fmt.Printf("Rows:   %d\n", totalRows)
 	fmt.Println()
 
-	// Connect to PostgreSQL
+	pool, err := connectPg(ctx, connStr, skipPayerCharges)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	// Modifiers from the side file written next to the charges
+	modifiers := func() ([]ModifierRow, error) {
+		modPath := modifiersPath(parquetPath)
+		if _, err := os.Stat(modPath); err != nil {
+			return nil, nil
+		}
+		mods, err := parquet.ReadFile[ModifierRow](modPath)
+		if err != nil {
+			return nil, fmt.Errorf("read modifiers: %w", err)
+		}
+		return mods, nil
+	}
+
+	const readBatch = 8192
+	src := &parquetSource{reader: reader, buf: make([]HospitalChargeRow, readBatch)}
+	return loadRowsToPg(ctx, pool, src, totalRows, modifiers, batchSize, skipPayerCharges, archive)
+}
+
+// loadFileToPg streams a CSV or JSON file straight into PostgreSQL with no
+// intermediate Parquet file. Each member of a zip loads as its own hospital.
+func loadFileToPg(ctx context.Context, inputPath, connStr string, batchSize int, skipPayerCharges, archive bool, enc textEncoding) error {
+	members, closeInput, err := listInputMembers(inputPath)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	pool, err := connectPg(ctx, connStr, skipPayerCharges)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+	fmt.Println()
+
+	for i, m := range members {
+		if len(members) > 1 {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("── [%d/%d] %s\n", i+1, len(members), m.Name)
+		}
+		if err := loadMemberToPg(ctx, pool, m, enc, batchSize, skipPayerCharges, archive); err != nil {
+			if len(members) > 1 {
+				return fmt.Errorf("%s: %w", m.Name, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// loadMemberToPg loads one hospital file, detecting JSON or CSV from its
+// content.
+func loadMemberToPg(ctx context.Context, pool *pgxpool.Pool, m inputMember, enc textEncoding, batchSize int, skipPayerCharges, archive bool) error {
+	reader, err := openMemberReader(m, enc)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	fmt.Printf("Input:  %s\n", m.Name)
+	fmt.Printf("Format: %s\n", reader.Format())
+	if m.Size > 0 {
+		fmt.Printf("Size:   %.1f MB\n", float64(m.Size)/1024/1024)
+	}
+	fmt.Println()
+
+	// JSON modifier_information is complete once the reader hits EOF.
+	modifiers := func() ([]ModifierRow, error) {
+		if jr, ok := reader.(*JSONReader); ok {
+			return jr.Modifiers(), nil
+		}
+		return nil, nil
+	}
+	return loadRowsToPg(ctx, pool, chargeSource{reader}, 0, modifiers, batchSize, skipPayerCharges, archive)
+}
+
+// connectPg opens a small pool and checks the connection.
+func connectPg(ctx context.Context, connStr string, skipPayerCharges bool) (*pgxpool.Pool, error) {
 	poolConfig, err := pgxpool.ParseConfig(connStr)
 	if err != nil {
-		return fmt.Errorf("parse connection: %w", err)
+		return nil, fmt.Errorf("parse connection: %w", err)
 	}
 	poolConfig.MaxConns = 4
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
-		return fmt.Errorf("connect: %w", err)
+		return nil, fmt.Errorf("connect: %w", err)
 	}
-	defer pool.Close()
 
 	if err := pool.Ping(ctx); err != nil {
-		return fmt.Errorf("ping: %w", err)
+		pool.Close()
+		return nil, fmt.Errorf("ping: %w", err)
 	}
 	fmt.Println("Connected to PostgreSQL")
 	if skipPayerCharges {
 		fmt.Println("Skipping payer_charges (use -skip-payer-charges=false to include)")
 	}
+	return pool, nil
+}
+
+// loadRowsToPg loads one hospital's rows into the relational tables.
+// Adjacent rows with the same itemKeyOf form one standard_charge_item and,
+// within it, adjacent rows with the same chargeKeyOf one standard_charge.
+// totalRows is only used for progress and may be 0 when unknown.
+// modifiers is called once all rows are read.
+//
+// The whole file loads in a single transaction, flushed every batchSize
+// items, so a failed load leaves nothing behind and can simply be rerun.
+// The first row identifies the hospital (see matchHospital). A file no
+// newer than the stored hospital's last_updated_on is skipped. A newer one
+// replaces the stored items and modifiers, first copying them to the
+// archived_* tables when archive is set.
+func loadRowsToPg(ctx context.Context, pool *pgxpool.Pool, src rowSource, totalRows int64,
+	modifiers func() ([]ModifierRow, error), batchSize int, skipPayerCharges, archive bool) error {
+	start := time.Now()
 
 	// Code cache: "CODE\tTYPE" → code_id
 	codeCache := make(map[string]int32)
@@ -68,10 +207,8 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 	// Payer cache: payer_name → payer_id
 	payerCache := make(map[string]int32)
 
-	const readBatch = 8192
-	buf := make([]HospitalChargeRow, readBatch)
-
 	var (
+		err         error
 		hospitalID  int32
 		tx          pgx.Tx
 		q           *db.Queries
@@ -84,23 +221,49 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 		// Current item accumulator
 		curItemKey  string
 		curItemRows []HospitalChargeRow
-		txItemCount int
-
-		// Accumulated payer_charges for bulk COPY within a transaction
-		pendingPayers []db.InsertPayerChargesParams
+		batchItems  int
+
+		// Accumulated rows for bulk COPY within a transaction
+		pendingItems     []db.InsertStandardChargeItemsParams
+		pendingItemCodes []db.InsertItemCodesParams
+		pendingCharges   []db.InsertStandardChargesParams
+		pendingPayers    []db.InsertPayerChargesParams
+
+		// IDs reserved from the sequences, so children can reference
+		// items and charges before they are copied
+		itemIDs   = idBlock{next: (*db.Queries).NextItemIDs}
+		chargeIDs = idBlock{next: (*db.Queries).NextChargeIDs}
 	)
 
-	// flushPayers bulk-inserts accumulated payer_charges via COPY.
-	flushPayers := func() error {
-		if len(pendingPayers) == 0 {
-			return nil
+	// flushPending bulk-inserts accumulated rows via COPY, parents first so
+	// foreign keys hold.
+	flushPending := func() error {
+		if len(pendingItems) > 0 {
+			if _, err := q.InsertStandardChargeItems(ctx, pendingItems); err != nil {
+				return fmt.Errorf("copy standard_charge_items: %w", err)
+			}
+			pendingItems = pendingItems[:0]
 		}
-		copied, err := q.InsertPayerCharges(ctx, pendingPayers)
-		if err != nil {
-			return fmt.Errorf("copy payer_charges: %w", err)
+		if len(pendingItemCodes) > 0 {
+			if _, err := q.InsertItemCodes(ctx, pendingItemCodes); err != nil {
+				return fmt.Errorf("copy item_codes: %w", err)
+			}
+			pendingItemCodes = pendingItemCodes[:0]
+		}
+		if len(pendingCharges) > 0 {
+			if _, err := q.InsertStandardCharges(ctx, pendingCharges); err != nil {
+				return fmt.Errorf("copy standard_charges: %w", err)
+			}
+			pendingCharges = pendingCharges[:0]
+		}
+		if len(pendingPayers) > 0 {
+			copied, err := q.InsertPayerCharges(ctx, pendingPayers)
+			if err != nil {
+				return fmt.Errorf("copy payer_charges: %w", err)
+			}
+			payerCount += copied
+			pendingPayers = pendingPayers[:0]
 		}
-		payerCount += copied
-		pendingPayers = pendingPayers[:0]
 		return nil
 	}
 
@@ -112,20 +275,24 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 
 		first := curItemRows[0]
 
-		// Insert standard_charge_item
-		itemID, err := q.InsertStandardChargeItem(ctx, db.InsertStandardChargeItemParams{
+		// Accumulate standard_charge_item for bulk COPY
+		itemID, err := itemIDs.take(ctx, q)
+		if err != nil {
+			return fmt.Errorf("reserve item ids: %w", err)
+		}
+		pendingItems = append(pendingItems, db.InsertStandardChargeItemsParams{
+			ID:           itemID,
 			HospitalID:   hospitalID,
 			Description:  sanitizeUTF8(first.Description),
 			DrugUnit:     floatToNumeric(first.DrugUnitOfMeasurement),
 			DrugUnitType: optToPgText(first.DrugTypeOfMeasurement),
 		})
-		if err != nil {
-			return fmt.Errorf("insert item: %w", err)
-		}
 		itemCount++
 
-		// Upsert codes and link to item
+		// Upsert codes and link to item. COPY has no ON CONFLICT, so
+		// skip a code the item already links.
 		codes := collectCodes(&first)
+		linked := len(pendingItemCodes)
 		for _, cp := range codes {
 			cacheKey := cp[0] + "\t" + cp[1]
 			codeID, ok := codeCache[cacheKey]
@@ -140,11 +307,9 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 				codeCache[cacheKey] = codeID
 			}
 
-			if err := q.InsertItemCode(ctx, db.InsertItemCodeParams{
-				ItemID: itemID,
-				CodeID: codeID,
-			}); err != nil {
-				return fmt.Errorf("link item-code: %w", err)
+			link := db.InsertItemCodesParams{ItemID: itemID, CodeID: codeID}
+			if !slices.Contains(pendingItemCodes[linked:], link) {
+				pendingItemCodes = append(pendingItemCodes, link)
 			}
 		}
 
@@ -173,7 +338,12 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 				modifierCodes = strings.Split(*r.Modifiers, "|")
 			}
 
-			chargeID, err := q.InsertStandardCharge(ctx, db.InsertStandardChargeParams{
+			chargeID, err := chargeIDs.take(ctx, q)
+			if err != nil {
+				return fmt.Errorf("reserve charge ids: %w", err)
+			}
+			pendingCharges = append(pendingCharges, db.InsertStandardChargesParams{
+				ID:              chargeID,
 				ItemID:          itemID,
 				Setting:         r.Setting,
 				GrossCharge:     floatToNumeric(r.GrossCharge),
@@ -183,9 +353,6 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 				ModifierCodes:   modifierCodes,
 				AdditionalNotes: optToPgText(r.AdditionalGenericNotes),
 			})
-			if err != nil {
-				return fmt.Errorf("insert charge: %w", err)
-			}
 			chargeCount++
 
 			// Accumulate payer_charge rows for bulk COPY
@@ -231,10 +398,10 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 						StandardChargePercentage: floatToNumeric(pr.NegotiatedPercentage),
 						StandardChargeAlgorithm:  optToPgText(pr.NegotiatedAlgorithm),
 						EstimatedAmount:          floatToNumeric(pr.EstimatedAmount),
-						MedianAmount:             pgtype.Numeric{Valid: false},
-						Percentile10th:           pgtype.Numeric{Valid: false},
-						Percentile90th:           pgtype.Numeric{Valid: false},
-						Count:                    pgtype.Text{Valid: false},
+						MedianAmount:             floatToNumeric(pr.MedianAmount),
+						Percentile10th:           floatToNumeric(pr.Percentile10th),
+						Percentile90th:           floatToNumeric(pr.Percentile90th),
+						Count:                    optToPgText(pr.Count),
 						AdditionalNotes:          optToPgText(pr.AdditionalPayerNotes),
 					})
 				}
@@ -242,40 +409,47 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 		}
 
 		curItemRows = curItemRows[:0]
-		txItemCount++
+		batchItems++
 		return nil
 	}
 
-	// beginTx starts a new transaction and creates a Queries wrapper.
-	beginTx := func() error {
-		tx, err = pool.Begin(ctx)
-		if err != nil {
-			return fmt.Errorf("begin tx: %w", err)
-		}
-		q = db.New(tx)
-		return nil
-	}
-
-	// Start first transaction
-	if err := beginTx(); err != nil {
-		return err
+	tx, err = pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
 	}
+	q = db.New(tx)
 
 	for {
-		n, readErr := reader.Read(buf)
+		rows, readErr := src.Next()
 
-		for i := 0; i < n; i++ {
-			row := buf[i]
+		for i := range rows {
+			row := rows[i]
 			rowsRead++
 
-			// Insert hospital from first row
+			// Insert or replace the hospital from the first row
 			if hospitalID == 0 {
-				hospitalID, err = insertHospitalFromRow(ctx, q, &row)
+				var status hospitalStatus
+				hospitalID, status, err = loadHospitalFromRow(ctx, q, &row, archive)
 				if err != nil {
 					tx.Rollback(ctx)
-					return fmt.Errorf("insert hospital: %w", err)
+					return err
+				}
+				switch status {
+				case hospitalUnchanged:
+					tx.Rollback(ctx)
+					fmt.Printf("Hospital: %s (ID: %d) already loaded for %s, skipping\n",
+						row.HospitalName, hospitalID, row.LastUpdatedOn)
+					return nil
+				case hospitalOutdated:
+					tx.Rollback(ctx)
+					fmt.Printf("Hospital: %s (ID: %d) file dated %s is older than the stored version, skipping\n",
+						row.HospitalName, hospitalID, row.LastUpdatedOn)
+					return nil
+				case hospitalReplaced:
+					fmt.Printf("Hospital: %s (ID: %d, replacing older version)\n\n", row.HospitalName, hospitalID)
+				default:
+					fmt.Printf("Hospital: %s (ID: %d)\n\n", row.HospitalName, hospitalID)
 				}
-				fmt.Printf("Hospital: %s (ID: %d)\n\n", row.HospitalName, hospitalID)
 			}
 
 			ik := itemKeyOf(&row)
@@ -285,19 +459,13 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 					return err
 				}
 
-				// Commit periodically
-				if txItemCount >= batchSize {
-					if err := flushPayers(); err != nil {
+				// Flush periodically; the commit waits for the last row
+				if batchItems >= batchSize {
+					if err := flushPending(); err != nil {
 						tx.Rollback(ctx)
 						return err
 					}
-					if err := tx.Commit(ctx); err != nil {
-						return fmt.Errorf("commit: %w", err)
-					}
-					if err := beginTx(); err != nil {
-						return err
-					}
-					txItemCount = 0
+					batchItems = 0
 				}
 			}
 
@@ -306,9 +474,12 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 
 			if time.Since(lastLog) >= 5*time.Second {
 				elapsed := time.Since(start).Seconds()
-				pct := float64(rowsRead) / float64(totalRows) * 100
-				fmt.Printf("  progress: %d/%d rows (%.1f%%) | %d items, %d charges, %d payer (%.0f rows/s)\n",
-					rowsRead, totalRows, pct, itemCount, chargeCount, payerCount, float64(rowsRead)/elapsed)
+				progress := fmt.Sprintf("%d rows", rowsRead)
+				if totalRows > 0 {
+					progress = fmt.Sprintf("%d/%d rows (%.1f%%)", rowsRead, totalRows, float64(rowsRead)/float64(totalRows)*100)
+				}
+				fmt.Printf("  progress: %s | %d items, %d charges, %d payer (%.0f rows/s)\n",
+					progress, itemCount, chargeCount, payerCount, float64(rowsRead)/elapsed)
 				lastLog = time.Now()
 			}
 		}
@@ -318,7 +489,7 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 				break
 			}
 			tx.Rollback(ctx)
-			return fmt.Errorf("read parquet: %w", readErr)
+			return readErr
 		}
 	}
 
@@ -330,12 +501,26 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 		}
 	}
 
-	// Flush remaining payer_charges
-	if err := flushPayers(); err != nil {
+	// Flush remaining items, charges and payer_charges
+	if err := flushPending(); err != nil {
 		tx.Rollback(ctx)
 		return err
 	}
 
+	var modifierCount, modifierPayerCount int
+	if hospitalID != 0 {
+		mods, err := modifiers()
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+		modifierCount, modifierPayerCount, err = loadModifiers(ctx, q, hospitalID, mods, payerCache, planCache)
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+	}
+
 	// Final commit
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("final commit: %w", err)
@@ -348,6 +533,7 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 	fmt.Printf("  Items:          %d\n", itemCount)
 	fmt.Printf("  Charges:        %d\n", chargeCount)
 	fmt.Printf("  Payer charges:  %d\n", payerCount)
+	fmt.Printf("  Modifiers:      %d (%d payer rows)\n", modifierCount, modifierPayerCount)
 	fmt.Printf("  Codes cached:   %d\n", len(codeCache))
 	fmt.Printf("  Plans cached:   %d\n", len(planCache))
 	fmt.Printf("  Payers cached:  %d\n", len(payerCache))
@@ -356,40 +542,337 @@ func loadParquetToPg(ctx context.Context, parquetPath, connStr string, batchSize
 	return nil
 }
 
-func insertHospitalFromRow(ctx context.Context, q *db.Queries, row *HospitalChargeRow) (int32, error) {
-	date, err := time.Parse("2006-01-02", row.LastUpdatedOn)
+// idBlockSize is how many IDs an idBlock reserves per round trip.
+const idBlockSize = 1000
+
+// idBlock hands out IDs reserved from a table's sequence in blocks, so
+// rows can be COPYed with their IDs known up front. IDs left over when a
+// load ends are never used; the sequence just has a gap.
+type idBlock struct {
+	next func(q *db.Queries, ctx context.Context, count int32) ([]int32, error)
+	ids  []int32
+}
+
+func (b *idBlock) take(ctx context.Context, q *db.Queries) (int32, error) {
+	if len(b.ids) == 0 {
+		ids, err := b.next(q, ctx, idBlockSize)
+		if err != nil {
+			return 0, err
+		}
+		b.ids = ids
+	}
+	id := b.ids[0]
+	b.ids = b.ids[1:]
+	return id, nil
+}
+
+// loadModifiers inserts modifier rows into modifiers and modifier_payer_info.
+// Adjacent rows with the same code, description and setting are one
+// modifier; rows without a payer add no modifier_payer_info row.
+func loadModifiers(ctx context.Context, q *db.Queries, hospitalID int32, rows []ModifierRow,
+	payerCache, planCache map[string]int32) (modifiers, payerInfos int, err error) {
+	var (
+		modifierID int32
+		curKey     string
+	)
+	for i := range rows {
+		m := &rows[i]
+		setting := ""
+		if m.Setting != nil {
+			setting = *m.Setting
+		}
+		key := m.Code + "\t" + m.Description + "\t" + setting
+		if key != curKey || modifiers == 0 {
+			modifierID, err = q.InsertModifier(ctx, db.InsertModifierParams{
+				HospitalID:  hospitalID,
+				Code:        sanitizeUTF8(m.Code),
+				Description: sanitizeUTF8(m.Description),
+				Setting:     optToPgText(m.Setting),
+			})
+			if err != nil {
+				return modifiers, payerInfos, fmt.Errorf("insert modifier %q: %w", m.Code, err)
+			}
+			modifiers++
+			curKey = key
+		}
+		if m.PayerName == nil {
+			continue
+		}
+
+		payerName := sanitizeUTF8(*m.PayerName)
+		planName := ""
+		if m.PlanName != nil {
+			planName = sanitizeUTF8(*m.PlanName)
+		}
+		payerID, ok := payerCache[payerName]
+		if !ok {
+			payerID, err = q.UpsertPayer(ctx, payerName)
+			if err != nil {
+				return modifiers, payerInfos, fmt.Errorf("upsert payer %q: %w", payerName, err)
+			}
+			payerCache[payerName] = payerID
+		}
+		planID, ok := planCache[planName]
+		if !ok {
+			planID, err = q.UpsertPlan(ctx, planName)
+			if err != nil {
+				return modifiers, payerInfos, fmt.Errorf("upsert plan %q: %w", planName, err)
+			}
+			planCache[planName] = planID
+		}
+		description := ""
+		if m.PayerDescription != nil {
+			description = sanitizeUTF8(*m.PayerDescription)
+		}
+		if err := q.InsertModifierPayerInfo(ctx, db.InsertModifierPayerInfoParams{
+			ModifierID:  modifierID,
+			PayerID:     payerID,
+			PlanID:      planID,
+			Description: description,
+		}); err != nil {
+			return modifiers, payerInfos, fmt.Errorf("insert modifier payer info %q: %w", m.Code, err)
+		}
+		payerInfos++
+	}
+	return modifiers, payerInfos, nil
+}
+
+// hospitalStatus is what loadHospitalFromRow did with a file's hospital.
+type hospitalStatus int
+
+const (
+	hospitalInserted hospitalStatus = iota
+	hospitalReplaced
+	hospitalUnchanged // the file is the stored version
+	hospitalOutdated  // the file is older than the stored version
+)
+
+// loadHospitalFromRow inserts the hospital described by row, or finds the
+// stored one. A stored hospital the file is not newer than (see
+// fileIsNewer) is left alone; an older one is updated in place with its
+// items and modifiers deleted (archived first when archive is set), so the
+// file's rows can be loaded under the same ID.
+func loadHospitalFromRow(ctx context.Context, q *db.Queries, row *HospitalChargeRow, archive bool) (int32, hospitalStatus, error) {
+	p := hospitalParamsFromRow(row)
+	candidates, err := q.FindHospitalCandidates(ctx, db.FindHospitalCandidatesParams{
+		LicenseNumber: p.LicenseNumber,
+		LicenseState:  p.LicenseState,
+		Npis:          p.Npis,
+		Name:          p.Name,
+	})
 	if err != nil {
-		date, err = time.Parse("01/02/2006", row.LastUpdatedOn)
+		return 0, 0, fmt.Errorf("find hospital: %w", err)
+	}
+
+	stored, ok := matchHospital(row, candidates)
+	if !ok {
+		id, err := q.InsertHospital(ctx, p)
 		if err != nil {
-			date = time.Now()
+			return 0, 0, fmt.Errorf("insert hospital: %w", err)
+		}
+		return id, hospitalInserted, nil
+	}
+	if !fileIsNewer(stored.LastUpdatedOn, row.LastUpdatedOn) {
+		if date, ok := parseLastUpdated(row.LastUpdatedOn); ok && date.Before(stored.LastUpdatedOn.Time) {
+			return stored.ID, hospitalOutdated, nil
 		}
+		return stored.ID, hospitalUnchanged, nil
 	}
 
-	var addresses []string
-	if row.HospitalAddress != "" {
-		addresses = []string{sanitizeUTF8(row.HospitalAddress)}
+	if archive {
+		if err := archiveHospital(ctx, q, stored.ID); err != nil {
+			return 0, 0, err
+		}
+	}
+	// Items cascade to item_codes, standard_charges and payer_charges;
+	// modifiers to modifier_payer_info.
+	if err := q.DeleteHospitalItems(ctx, stored.ID); err != nil {
+		return 0, 0, fmt.Errorf("delete hospital items: %w", err)
 	}
-	var locationNames []string
-	if row.HospitalLocation != "" {
-		locationNames = []string{sanitizeUTF8(row.HospitalLocation)}
+	if err := q.DeleteHospitalModifiers(ctx, stored.ID); err != nil {
+		return 0, 0, fmt.Errorf("delete hospital modifiers: %w", err)
 	}
+	if err := q.UpdateHospital(ctx, db.UpdateHospitalParams{
+		ID:            stored.ID,
+		Name:          p.Name,
+		Addresses:     p.Addresses,
+		LocationNames: p.LocationNames,
+		Npis:          p.Npis,
+		LicenseNumber: p.LicenseNumber,
+		LicenseState:  p.LicenseState,
+		Version:       p.Version,
+		LastUpdatedOn: p.LastUpdatedOn,
+		AttesterName:  p.AttesterName,
+	}); err != nil {
+		return 0, 0, fmt.Errorf("update hospital: %w", err)
+	}
+	return stored.ID, hospitalReplaced, nil
+}
 
-	return q.InsertHospital(ctx, db.InsertHospitalParams{
+// archiveHospital copies a hospital and everything loaded for it to the
+// archived_* tables.
+func archiveHospital(ctx context.Context, q *db.Queries, hospitalID int32) error {
+	archiveID, err := q.ArchiveHospital(ctx, hospitalID)
+	if err != nil {
+		return fmt.Errorf("archive hospital: %w", err)
+	}
+	if err := q.ArchiveHospitalItems(ctx, db.ArchiveHospitalItemsParams{
+		ArchiveID:  archiveID,
+		HospitalID: hospitalID,
+	}); err != nil {
+		return fmt.Errorf("archive items: %w", err)
+	}
+	if err := q.ArchiveHospitalItemCodes(ctx, hospitalID); err != nil {
+		return fmt.Errorf("archive item codes: %w", err)
+	}
+	if err := q.ArchiveHospitalCharges(ctx, hospitalID); err != nil {
+		return fmt.Errorf("archive charges: %w", err)
+	}
+	if err := q.ArchiveHospitalPayerCharges(ctx, hospitalID); err != nil {
+		return fmt.Errorf("archive payer charges: %w", err)
+	}
+	if err := q.ArchiveHospitalModifiers(ctx, db.ArchiveHospitalModifiersParams{
+		ArchiveID:  archiveID,
+		HospitalID: hospitalID,
+	}); err != nil {
+		return fmt.Errorf("archive modifiers: %w", err)
+	}
+	if err := q.ArchiveHospitalModifierPayerInfo(ctx, hospitalID); err != nil {
+		return fmt.Errorf("archive modifier payer info: %w", err)
+	}
+	return nil
+}
+
+// matchHospital picks the stored hospital that row's file describes, from
+// candidates ordered newest first. In order of preference it matches by
+// license number and state, by a shared type 2 NPI, or by name and a
+// shared address. A license or NPI can cover several campuses that each
+// publish their own file, so those matches also need the same name or a
+// shared address.
+func matchHospital(row *HospitalChargeRow, candidates []db.FindHospitalCandidatesRow) (db.FindHospitalCandidatesRow, bool) {
+	sameName := func(c *db.FindHospitalCandidatesRow) bool {
+		return strings.EqualFold(strings.TrimSpace(c.Name), strings.TrimSpace(row.HospitalName))
+	}
+	sameAddress := func(c *db.FindHospitalCandidatesRow) bool {
+		for _, a := range c.Addresses {
+			na := normalizeAddress(a)
+			for _, b := range row.HospitalAddresses {
+				if na != "" && na == normalizeAddress(b) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	sameLicense := func(c *db.FindHospitalCandidatesRow) bool {
+		if row.LicenseNumber == nil || row.LicenseState == nil || !c.LicenseNumber.Valid || !c.LicenseState.Valid {
+			return false
+		}
+		return strings.TrimSpace(*row.LicenseNumber) != "" &&
+			strings.EqualFold(strings.TrimSpace(c.LicenseNumber.String), strings.TrimSpace(*row.LicenseNumber)) &&
+			strings.EqualFold(strings.TrimSpace(c.LicenseState.String), strings.TrimSpace(*row.LicenseState))
+	}
+	sharedNPI := func(c *db.FindHospitalCandidatesRow) bool {
+		for _, n := range c.Npis {
+			if n != "" && slices.Contains(row.Type2NPIs, n) {
+				return true
+			}
+		}
+		return false
+	}
+
+	tiers := []func(c *db.FindHospitalCandidatesRow) bool{
+		func(c *db.FindHospitalCandidatesRow) bool { return sameLicense(c) && (sameName(c) || sameAddress(c)) },
+		func(c *db.FindHospitalCandidatesRow) bool { return sharedNPI(c) && (sameName(c) || sameAddress(c)) },
+		func(c *db.FindHospitalCandidatesRow) bool { return sameName(c) && sameAddress(c) },
+	}
+	for _, match := range tiers {
+		for i := range candidates {
+			if match(&candidates[i]) {
+				return candidates[i], true
+			}
+		}
+	}
+	return db.FindHospitalCandidatesRow{}, false
+}
+
+// normalizeAddress folds case, whitespace and punctuation so "123 Main St."
+// and "123 MAIN ST" compare equal.
+func normalizeAddress(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// fileIsNewer reports whether a file's last_updated_on is later than the
+// stored hospital's. An unparseable date counts as the same as the stored
+// one, so rerunning such a file never replaces what is loaded, and any
+// real date is later than the undatedHospital sentinel.
+func fileIsNewer(stored pgtype.Date, lastUpdatedOn string) bool {
+	date, ok := parseLastUpdated(lastUpdatedOn)
+	if !ok {
+		return false
+	}
+	return !stored.Valid || stored.InfinityModifier == pgtype.NegativeInfinity || date.After(stored.Time)
+}
+
+// undatedHospital is stored as the last_updated_on of a file whose date
+// can't be parsed. It sorts before every real date, so a dated file of the
+// same hospital always replaces it.
+var undatedHospital = pgtype.Date{InfinityModifier: pgtype.NegativeInfinity, Valid: true}
+
+// parseLastUpdated parses a last_updated_on in either of the formats
+// hospitals publish.
+func parseLastUpdated(s string) (time.Time, bool) {
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		date, err = time.Parse("01/02/2006", s)
+		if err != nil {
+			return time.Time{}, false
+		}
+	}
+	return date, true
+}
+
+// hospitalParamsFromRow builds the hospitals row from a charge row's
+// hospital columns. An unparseable last_updated_on loads as
+// undatedHospital.
+func hospitalParamsFromRow(row *HospitalChargeRow) db.InsertHospitalParams {
+	lastUpdatedOn := undatedHospital
+	if date, ok := parseLastUpdated(row.LastUpdatedOn); ok {
+		lastUpdatedOn = pgtype.Date{Time: date, Valid: true}
+	}
+
+	return db.InsertHospitalParams{
 		Name:          sanitizeUTF8(row.HospitalName),
-		Addresses:     addresses,
-		LocationNames: locationNames,
-		Npis:          nil,
+		Addresses:     sanitizeList(row.HospitalAddresses),
+		LocationNames: sanitizeList(row.HospitalLocations),
+		Npis:          sanitizeList(row.Type2NPIs),
 		LicenseNumber: optToPgText(row.LicenseNumber),
 		LicenseState:  optToPgText(row.LicenseState),
 		Version:       sanitizeUTF8(row.Version),
-		LastUpdatedOn: pgtype.Date{Time: date, Valid: true},
-		AttesterName:  pgtype.Text{Valid: false},
-	})
+		LastUpdatedOn: lastUpdatedOn,
+		AttesterName:  optToPgText(row.AttesterName),
+	}
 }
 
-// sanitizeUTF8 replaces invalid UTF-8 bytes with spaces.
+// sanitizeUTF8 decodes bytes that are not valid UTF-8 as Windows-1252,
+// so Parquet files written before transcoding still load as "Café".
 func sanitizeUTF8(s string) string {
-	return strings.ToValidUTF8(s, " ")
+	s, _ = encodingAuto.transcode(s)
+	return s
+}
+
+// sanitizeList sanitizes each element, returning nil for an empty list.
+func sanitizeList(v []string) []string {
+	if len(v) == 0 {
+		return nil
+	}
+	out := make([]string, len(v))
+	for i, s := range v {
+		out[i] = sanitizeUTF8(s)
+	}
+	return out
 }
 
 // collectCodes extracts all non-nil code pairs from a row.
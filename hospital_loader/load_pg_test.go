@@ -3,9 +3,14 @@ package main
 import (
 	"context"
 	_ "embed"
+	"errors"
+	"fmt"
+	"io"
 	"math/big"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,7 +31,7 @@ type testDB struct {
 	pool *pgxpool.Pool
 }
 
-func setupTestDB(t *testing.T) *testDB {
+func setupTestDB(t testing.TB) *testDB {
 	t.Helper()
 
 	pg := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
@@ -95,48 +100,50 @@ func writeTestParquet(t *testing.T) (string, []HospitalChargeRow) {
 	rows := []HospitalChargeRow{
 		// Item 1: two payers sharing same setting/gross/min/max (one charge group, two payer rows)
 		{
-			Description:      "ECHOCARDIOGRAM COMPLETE",
-			Setting:          "outpatient",
-			CPTCode:          strPtr("93306"),
-			HCPCSCode:        strPtr("G0389"),
-			GrossCharge:      f64Ptr(1500.00),
-			DiscountedCash:   f64Ptr(750.00),
-			MinCharge:        f64Ptr(500.00),
-			MaxCharge:        f64Ptr(2000.00),
-			PayerName:        strPtr("Aetna"),
-			PlanName:         strPtr("Aetna PPO"),
-			NegotiatedDollar: f64Ptr(900.00),
-			Methodology:      strPtr("fee_schedule"),
-			HospitalName:     "Test General Hospital",
-			LastUpdatedOn:    "2024-01-15",
-			Version:          "2.0.0",
-			HospitalLocation: "New York, NY",
-			HospitalAddress:  "123 Main St, New York, NY 10001",
-			LicenseNumber:    strPtr("LIC-12345"),
-			LicenseState:     strPtr("NY"),
-			Affirmation:      true,
+			Description:       "ECHOCARDIOGRAM COMPLETE",
+			Setting:           "outpatient",
+			CPTCode:           strPtr("93306"),
+			HCPCSCode:         strPtr("G0389"),
+			GrossCharge:       f64Ptr(1500.00),
+			DiscountedCash:    f64Ptr(750.00),
+			MinCharge:         f64Ptr(500.00),
+			MaxCharge:         f64Ptr(2000.00),
+			PayerName:         strPtr("Aetna"),
+			PlanName:          strPtr("Aetna PPO"),
+			NegotiatedDollar:  f64Ptr(900.00),
+			Methodology:       strPtr("fee_schedule"),
+			HospitalName:      "Test General Hospital",
+			LastUpdatedOn:     "2024-01-15",
+			Version:           "2.0.0",
+			HospitalLocations: []string{"Test General Hospital", "Test General Brooklyn"},
+			HospitalAddresses: []string{"123 Main St, New York, NY 10001", "9 Court St, Brooklyn, NY 11201"},
+			Type2NPIs:         []string{"1234567890", "1987654321"},
+			LicenseNumber:     strPtr("LIC-12345"),
+			LicenseState:      strPtr("NY"),
+			Affirmation:       true,
+			AttesterName:      strPtr("Pat Smith"),
 		},
 		{
-			Description:      "ECHOCARDIOGRAM COMPLETE",
-			Setting:          "outpatient",
-			CPTCode:          strPtr("93306"),
-			HCPCSCode:        strPtr("G0389"),
-			GrossCharge:      f64Ptr(1500.00),
-			DiscountedCash:   f64Ptr(750.00),
-			MinCharge:        f64Ptr(500.00),
-			MaxCharge:        f64Ptr(2000.00),
-			PayerName:        strPtr("UnitedHealthcare"),
-			PlanName:         strPtr("UHC Choice Plus"),
-			NegotiatedDollar: f64Ptr(1100.00),
-			Methodology:      strPtr("case_rate"),
-			HospitalName:     "Test General Hospital",
-			LastUpdatedOn:    "2024-01-15",
-			Version:          "2.0.0",
-			HospitalLocation: "New York, NY",
-			HospitalAddress:  "123 Main St, New York, NY 10001",
-			LicenseNumber:    strPtr("LIC-12345"),
-			LicenseState:     strPtr("NY"),
-			Affirmation:      true,
+			Description:       "ECHOCARDIOGRAM COMPLETE",
+			Setting:           "outpatient",
+			CPTCode:           strPtr("93306"),
+			HCPCSCode:         strPtr("G0389"),
+			GrossCharge:       f64Ptr(1500.00),
+			DiscountedCash:    f64Ptr(750.00),
+			MinCharge:         f64Ptr(500.00),
+			MaxCharge:         f64Ptr(2000.00),
+			PayerName:         strPtr("UnitedHealthcare"),
+			PlanName:          strPtr("UHC Choice Plus"),
+			NegotiatedDollar:  f64Ptr(1100.00),
+			Methodology:       strPtr("case_rate"),
+			HospitalName:      "Test General Hospital",
+			LastUpdatedOn:     "2024-01-15",
+			Version:           "2.0.0",
+			HospitalLocations: []string{"New York, NY"},
+			HospitalAddresses: []string{"123 Main St, New York, NY 10001"},
+			LicenseNumber:     strPtr("LIC-12345"),
+			LicenseState:      strPtr("NY"),
+			Affirmation:       true,
 		},
 		// Item 2: different description/code, inpatient, single payer, with drug info
 		{
@@ -159,32 +166,39 @@ func writeTestParquet(t *testing.T) (string, []HospitalChargeRow) {
 			HospitalName:           "Test General Hospital",
 			LastUpdatedOn:          "2024-01-15",
 			Version:                "2.0.0",
-			HospitalLocation:       "New York, NY",
-			HospitalAddress:        "123 Main St, New York, NY 10001",
+			HospitalLocations:      []string{"New York, NY"},
+			HospitalAddresses:      []string{"123 Main St, New York, NY 10001"},
 			LicenseNumber:          strPtr("LIC-12345"),
 			LicenseState:           strPtr("NY"),
 			Affirmation:            true,
 		},
 		// Item 3: MS-DRG code, no payer (gross/discounted only)
 		{
-			Description:      "HEART TRANSPLANT WITH MCC",
-			Setting:          "inpatient",
-			MSDRGCode:        strPtr("001"),
-			GrossCharge:      f64Ptr(500000.00),
-			DiscountedCash:   f64Ptr(250000.00),
-			MinCharge:        f64Ptr(200000.00),
-			MaxCharge:        f64Ptr(750000.00),
-			HospitalName:     "Test General Hospital",
-			LastUpdatedOn:    "2024-01-15",
-			Version:          "2.0.0",
-			HospitalLocation: "New York, NY",
-			HospitalAddress:  "123 Main St, New York, NY 10001",
-			LicenseNumber:    strPtr("LIC-12345"),
-			LicenseState:     strPtr("NY"),
-			Affirmation:      true,
+			Description:       "HEART TRANSPLANT WITH MCC",
+			Setting:           "inpatient",
+			MSDRGCode:         strPtr("001"),
+			GrossCharge:       f64Ptr(500000.00),
+			DiscountedCash:    f64Ptr(250000.00),
+			MinCharge:         f64Ptr(200000.00),
+			MaxCharge:         f64Ptr(750000.00),
+			HospitalName:      "Test General Hospital",
+			LastUpdatedOn:     "2024-01-15",
+			Version:           "2.0.0",
+			HospitalLocations: []string{"New York, NY"},
+			HospitalAddresses: []string{"123 Main St, New York, NY 10001"},
+			LicenseNumber:     strPtr("LIC-12345"),
+			LicenseState:      strPtr("NY"),
+			Affirmation:       true,
 		},
 	}
 
+	return writeParquetRows(t, rows), rows
+}
+
+// writeParquetRows writes rows to a parquet file in a temp dir and returns its path.
+func writeParquetRows(t *testing.T, rows []HospitalChargeRow) string {
+	t.Helper()
+
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test_charges.parquet")
 
@@ -199,7 +213,7 @@ func writeTestParquet(t *testing.T) (string, []HospitalChargeRow) {
 		t.Fatalf("close writer: %v", err)
 	}
 
-	return path, rows
+	return path
 }
 
 func TestLoadParquetToPg(t *testing.T) {
@@ -211,7 +225,7 @@ func TestLoadParquetToPg(t *testing.T) {
 
 	ctx := context.Background()
 
-	err := loadParquetToPg(ctx, parquetPath, testConnStr, 100, false)
+	err := loadParquetToPg(ctx, parquetPath, testConnStr, 100, false, false)
 	if err != nil {
 		t.Fatalf("loadParquetToPg: %v", err)
 	}
@@ -229,8 +243,26 @@ func TestLoadParquetToPg(t *testing.T) {
 	if hospital.Version != srcRows[0].Version {
 		t.Errorf("version = %q, want %q", hospital.Version, srcRows[0].Version)
 	}
-	if hospital.FirstAddress != srcRows[0].HospitalAddress {
-		t.Errorf("address = %q, want %q", hospital.FirstAddress, srcRows[0].HospitalAddress)
+	if hospital.FirstAddress != srcRows[0].HospitalAddresses[0] {
+		t.Errorf("address = %q, want %q", hospital.FirstAddress, srcRows[0].HospitalAddresses[0])
+	}
+
+	// Address, location and NPI lists keep one element per entry
+	lists, err := q.GetHospitalLists(ctx)
+	if err != nil {
+		t.Fatalf("GetHospitalLists: %v", err)
+	}
+	if !slices.Equal(lists.Addresses, srcRows[0].HospitalAddresses) {
+		t.Errorf("addresses = %q, want %q", lists.Addresses, srcRows[0].HospitalAddresses)
+	}
+	if !slices.Equal(lists.LocationNames, srcRows[0].HospitalLocations) {
+		t.Errorf("location_names = %q, want %q", lists.LocationNames, srcRows[0].HospitalLocations)
+	}
+	if !slices.Equal(lists.Npis, srcRows[0].Type2NPIs) {
+		t.Errorf("npis = %q, want %q", lists.Npis, srcRows[0].Type2NPIs)
+	}
+	if !lists.AttesterName.Valid || lists.AttesterName.String != "Pat Smith" {
+		t.Errorf("attester_name = %+v, want Pat Smith", lists.AttesterName)
 	}
 
 	// ── Verify standard_charge_items count ─────────────────────────
@@ -439,7 +471,7 @@ func TestLoadParquetToPg_SkipPayerCharges(t *testing.T) {
 
 	ctx := context.Background()
 
-	err := loadParquetToPg(ctx, parquetPath, testConnStr, 100, true)
+	err := loadParquetToPg(ctx, parquetPath, testConnStr, 100, true, false)
 	if err != nil {
 		t.Fatalf("loadParquetToPg: %v", err)
 	}
@@ -500,3 +532,430 @@ func TestLoadParquetToPg_SkipPayerCharges(t *testing.T) {
 		t.Errorf("plans = %d, want 0", planCount)
 	}
 }
+
+func TestLoadParquetToPg_Modifiers(t *testing.T) {
+	tdb := setupTestDB(t)
+	defer tdb.teardown()
+
+	parquetPath, _ := writeTestParquet(t)
+	defer os.Remove(parquetPath)
+
+	mods := []ModifierRow{
+		{Code: "50", Description: "Bilateral procedure", Setting: strPtr("outpatient"),
+			PayerName: strPtr("Aetna"), PlanName: strPtr("Aetna PPO"), PayerDescription: strPtr("150% of the fee schedule")},
+		{Code: "50", Description: "Bilateral procedure", Setting: strPtr("outpatient"),
+			PayerName: strPtr("Cigna"), PlanName: strPtr("Cigna HMO"), PayerDescription: strPtr("Paid at 200%")},
+		{Code: "26", Description: "Professional component"},
+	}
+	if err := WriteModifiers(modifiersPath(parquetPath), mods); err != nil {
+		t.Fatalf("WriteModifiers: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := loadParquetToPg(ctx, parquetPath, testConnStr, 100, true, false); err != nil {
+		t.Fatalf("loadParquetToPg: %v", err)
+	}
+
+	q := db.New(tdb.pool)
+
+	// Two distinct modifiers; code 26 has no payer rows
+	modCount, err := q.CountModifiers(ctx)
+	if err != nil {
+		t.Fatalf("CountModifiers: %v", err)
+	}
+	if modCount != 2 {
+		t.Errorf("modifiers = %d, want 2", modCount)
+	}
+
+	infos, err := q.ListModifierPayerInfo(ctx)
+	if err != nil {
+		t.Fatalf("ListModifierPayerInfo: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("modifier_payer_info = %d rows, want 2", len(infos))
+	}
+	if infos[0].PayerName != "Aetna" || infos[0].PlanName != "Aetna PPO" || infos[0].Description != "150% of the fee schedule" {
+		t.Errorf("infos[0] = %+v", infos[0])
+	}
+	if infos[1].PayerName != "Cigna" || infos[1].PlanName != "Cigna HMO" {
+		t.Errorf("infos[1] = %+v", infos[1])
+	}
+	if infos[0].Code != "50" || infos[0].Setting.String != "outpatient" {
+		t.Errorf("infos[0] modifier = %q/%q", infos[0].Code, infos[0].Setting.String)
+	}
+}
+
+// TestLoadParquetToPg_Reload loads the same file twice, then a newer
+// version of it, and checks the hospital is never duplicated.
+func TestLoadParquetToPg_Reload(t *testing.T) {
+	tdb := setupTestDB(t)
+	defer tdb.teardown()
+
+	parquetPath, srcRows := writeTestParquet(t)
+	ctx := context.Background()
+	q := db.New(tdb.pool)
+
+	counts := func() (hospitals, items, archived int32) {
+		t.Helper()
+		var err error
+		if hospitals, err = q.CountHospitals(ctx); err != nil {
+			t.Fatalf("CountHospitals: %v", err)
+		}
+		if items, err = q.CountItems(ctx); err != nil {
+			t.Fatalf("CountItems: %v", err)
+		}
+		if archived, err = q.CountArchivedItems(ctx); err != nil {
+			t.Fatalf("CountArchivedItems: %v", err)
+		}
+		return hospitals, items, archived
+	}
+
+	// Same last_updated_on twice: the second load is skipped
+	for range 2 {
+		if err := loadParquetToPg(ctx, parquetPath, testConnStr, 100, false, true); err != nil {
+			t.Fatalf("loadParquetToPg: %v", err)
+		}
+	}
+	if h, items, archived := counts(); h != 1 || items != 3 || archived != 0 {
+		t.Fatalf("after reload: hospitals=%d items=%d archived=%d, want 1/3/0", h, items, archived)
+	}
+
+	// A newer file with only the echocardiogram replaces the old items.
+	// Batch size 1 checks the replacement is not committed part-way.
+	newer := slices.Clone(srcRows[:2])
+	for i := range newer {
+		newer[i].LastUpdatedOn = "2024-06-01"
+		newer[i].HospitalAddresses = []string{"123 MAIN ST., New York, NY 10001"}
+	}
+	if err := loadParquetToPg(ctx, writeParquetRows(t, newer), testConnStr, 1, false, true); err != nil {
+		t.Fatalf("loadParquetToPg newer: %v", err)
+	}
+	if h, items, archived := counts(); h != 1 || items != 1 || archived != 3 {
+		t.Errorf("after newer load: hospitals=%d items=%d archived=%d, want 1/1/3", h, items, archived)
+	}
+
+	lists, err := q.GetHospitalLists(ctx)
+	if err != nil {
+		t.Fatalf("GetHospitalLists: %v", err)
+	}
+	if len(lists.Addresses) != 1 || lists.Addresses[0] != "123 MAIN ST., New York, NY 10001" {
+		t.Errorf("addresses = %v, want the newer file's", lists.Addresses)
+	}
+}
+
+// TestLoadParquetToPg_RerunAfterFailure fails a first-time load after
+// several batches and checks nothing was kept, so the rerun loads it all.
+func TestLoadParquetToPg_RerunAfterFailure(t *testing.T) {
+	tdb := setupTestDB(t)
+	defer tdb.teardown()
+
+	ctx := context.Background()
+	q := db.New(tdb.pool)
+	noModifiers := func() ([]ModifierRow, error) { return nil, nil }
+	rows := benchmarkRows(10)
+
+	src := &failingSource{rows: rows[:len(rows)/2], err: errors.New("read failed")}
+	if err := loadRowsToPg(ctx, tdb.pool, src, 0, noModifiers, 2, false, false); err == nil {
+		t.Fatal("loadRowsToPg succeeded on a failing source")
+	}
+	if h, err := q.CountHospitals(ctx); err != nil || h != 0 {
+		t.Fatalf("after failed load: hospitals=%d (%v), want 0", h, err)
+	}
+
+	if err := loadRowsToPg(ctx, tdb.pool, &sliceSource{rows: rows}, 0, noModifiers, 2, false, false); err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	h, err := q.CountHospitals(ctx)
+	if err != nil {
+		t.Fatalf("CountHospitals: %v", err)
+	}
+	items, err := q.CountItems(ctx)
+	if err != nil {
+		t.Fatalf("CountItems: %v", err)
+	}
+	if h != 1 || items != 10 {
+		t.Errorf("after rerun: hospitals=%d items=%d, want 1/10", h, items)
+	}
+}
+
+// TestLoadParquetToPg_UndatedReload loads a file whose last_updated_on
+// can't be parsed, reruns it, then loads a dated file from before the
+// load day, which must replace it.
+func TestLoadParquetToPg_UndatedReload(t *testing.T) {
+	tdb := setupTestDB(t)
+	defer tdb.teardown()
+
+	_, srcRows := writeTestParquet(t)
+	ctx := context.Background()
+	q := db.New(tdb.pool)
+
+	undated := slices.Clone(srcRows)
+	for i := range undated {
+		undated[i].LastUpdatedOn = "last week"
+	}
+	undatedPath := writeParquetRows(t, undated)
+	for range 2 {
+		if err := loadParquetToPg(ctx, undatedPath, testConnStr, 100, false, false); err != nil {
+			t.Fatalf("loadParquetToPg undated: %v", err)
+		}
+	}
+
+	dated := slices.Clone(srcRows[:2])
+	for i := range dated {
+		dated[i].LastUpdatedOn = "2001-02-03"
+	}
+	if err := loadParquetToPg(ctx, writeParquetRows(t, dated), testConnStr, 100, false, false); err != nil {
+		t.Fatalf("loadParquetToPg dated: %v", err)
+	}
+
+	hospitals, err := q.CountHospitals(ctx)
+	if err != nil {
+		t.Fatalf("CountHospitals: %v", err)
+	}
+	items, err := q.CountItems(ctx)
+	if err != nil {
+		t.Fatalf("CountItems: %v", err)
+	}
+	if hospitals != 1 || items != 1 {
+		t.Errorf("after dated load: hospitals=%d items=%d, want 1/1", hospitals, items)
+	}
+	candidates, err := q.FindHospitalCandidates(ctx, db.FindHospitalCandidatesParams{Name: srcRows[0].HospitalName})
+	if err != nil {
+		t.Fatalf("FindHospitalCandidates: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].LastUpdatedOn.Time.Format("2006-01-02") != "2001-02-03" {
+		t.Errorf("stored hospitals = %+v, want one dated 2001-02-03", candidates)
+	}
+}
+
+func TestMatchHospital(t *testing.T) {
+	stored := []db.FindHospitalCandidatesRow{
+		{ID: 3, Name: "Test General Hospital", Addresses: []string{"9 Court St, Brooklyn, NY 11201"},
+			Npis: []string{"1234567890"}, LicenseNumber: pgtype.Text{String: "LIC-12345", Valid: true}, LicenseState: pgtype.Text{String: "NY", Valid: true}},
+		{ID: 2, Name: "Test General Brooklyn", Addresses: []string{"1 Other Ave, Brooklyn, NY 11201"},
+			Npis: []string{"1234567890"}, LicenseNumber: pgtype.Text{String: "LIC-12345", Valid: true}, LicenseState: pgtype.Text{String: "NY", Valid: true}},
+		{ID: 1, Name: "Unlicensed Clinic", Addresses: []string{"5 Elm St, Albany, NY 12207"}},
+	}
+
+	tests := []struct {
+		name string
+		row  HospitalChargeRow
+		want int32
+	}{
+		{"license and name", HospitalChargeRow{HospitalName: "TEST GENERAL HOSPITAL", LicenseNumber: strPtr("lic-12345"), LicenseState: strPtr("ny")}, 3},
+		{"license, other campus", HospitalChargeRow{HospitalName: "Test General Brooklyn", LicenseNumber: strPtr("LIC-12345"), LicenseState: strPtr("NY")}, 2},
+		{"license, unknown campus", HospitalChargeRow{HospitalName: "Test General Queens", LicenseNumber: strPtr("LIC-12345"), LicenseState: strPtr("NY")}, 0},
+		{"npi and address", HospitalChargeRow{HospitalName: "TGH", Type2NPIs: []string{"1234567890"}, HospitalAddresses: []string{"1 Other Ave., Brooklyn NY 11201"}}, 2},
+		{"name and address", HospitalChargeRow{HospitalName: "unlicensed clinic", HospitalAddresses: []string{"5 ELM ST, ALBANY, NY 12207"}}, 1},
+		{"name only", HospitalChargeRow{HospitalName: "Unlicensed Clinic", HospitalAddresses: []string{"7 Oak St, Albany, NY 12207"}}, 0},
+	}
+	for _, tt := range tests {
+		got, ok := matchHospital(&tt.row, stored)
+		if ok != (tt.want != 0) || got.ID != tt.want {
+			t.Errorf("%s: matchHospital = %d (%v), want %d", tt.name, got.ID, ok, tt.want)
+		}
+	}
+}
+
+func TestFileIsNewer(t *testing.T) {
+	stored := pgtype.Date{Time: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Valid: true}
+	tests := []struct {
+		stored        pgtype.Date
+		lastUpdatedOn string
+		want          bool
+	}{
+		{stored, "2024-02-01", true},
+		{stored, "02/01/2024", true},
+		{stored, "2024-01-15", false},
+		{stored, "2023-12-31", false},
+		{stored, "last week", false},
+		{stored, "", false},
+		{pgtype.Date{}, "2024-01-15", true},
+		{pgtype.Date{}, "last week", false},
+		{undatedHospital, "1999-01-01", true},
+		{undatedHospital, "last week", false},
+	}
+	for _, tt := range tests {
+		if got := fileIsNewer(tt.stored, tt.lastUpdatedOn); got != tt.want {
+			t.Errorf("fileIsNewer(%v, %q) = %v, want %v", tt.stored.Valid, tt.lastUpdatedOn, got, tt.want)
+		}
+	}
+}
+
+// loadedTables are dumped in full by dumpLoadedTables.
+var loadedTables = []string{
+	"hospitals", "codes", "plans", "payers", "standard_charge_items", "item_codes",
+	"standard_charges", "payer_charges", "modifiers", "modifier_payer_info",
+}
+
+// dumpLoadedTables returns every loaded row as JSON, in id order, then
+// empties the tables and restarts their ids so the next load is comparable.
+func dumpLoadedTables(t *testing.T, pool *pgxpool.Pool) []string {
+	t.Helper()
+	ctx := context.Background()
+	var dump []string
+	for _, table := range loadedTables {
+		rows, err := pool.Query(ctx, "SELECT '"+table+" ' || (to_jsonb(t) - 'created_at')::text FROM "+table+" t ORDER BY id")
+		if err != nil {
+			t.Fatalf("dump %s: %v", table, err)
+		}
+		for rows.Next() {
+			var s string
+			if err := rows.Scan(&s); err != nil {
+				t.Fatalf("scan %s: %v", table, err)
+			}
+			dump = append(dump, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			t.Fatalf("dump %s: %v", table, err)
+		}
+	}
+	if _, err := pool.Exec(ctx, "TRUNCATE "+strings.Join(loadedTables, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatalf("truncate: %v", err)
+	}
+	return dump
+}
+
+// TestLoadFileToPg loads CSV and JSON inputs straight into PG and through
+// Parquet, and checks both leave identical rows in every table.
+func TestLoadFileToPg(t *testing.T) {
+	tdb := setupTestDB(t)
+	defer tdb.teardown()
+
+	jsonPath := filepath.Join(t.TempDir(), "direct.json")
+	content := `{
+  "hospital_name": "Direct Hospital", "last_updated_on": "2025-01-01", "version": "3.0.0",
+  "location_name": ["Direct Hospital"], "hospital_address": ["1 Main St"], "type_2_npi": ["1234567890"],
+  "license_information": {"license_number": "1", "state": "CA"},
+  "attestation": {"attestation": "...", "confirm_attestation": true, "attester_name": "Pat Smith"},
+  "standard_charge_information": [
+    {"description": "KNEE ARTHROSCOPY", "code_information": [{"code": "29881", "type": "CPT"}, {"code": "0360", "type": "RC"}],
+     "standard_charges": [
+       {"setting": "outpatient", "gross_charge": 9000, "minimum": 4000, "maximum": 7000, "modifier_code": ["50"],
+        "payers_information": [
+          {"payer_name": "Aetna", "plan_name": "PPO", "methodology": "case rate", "standard_charge_dollar": 7000},
+          {"payer_name": "Cigna", "plan_name": "HMO", "methodology": "percent of total billed charges",
+           "standard_charge_percentage": 45, "median_amount": 4100, "10th_percentile": 3900, "90th_percentile": 4500, "count": "11"}]},
+       {"setting": "inpatient", "gross_charge": 12000}]},
+    {"description": "ACETAMINOPHEN 500MG", "code_information": [{"code": "0904-1982-61", "type": "NDC"}],
+     "drug_information": {"unit": 1, "type": "EA"},
+     "standard_charges": [{"setting": "both", "gross_charge": 2, "discounted_cash": 1}]}],
+  "modifier_information": [
+    {"code": "50", "description": "Bilateral procedure", "setting": "outpatient",
+     "modifier_payer_information": [{"payer_name": "Aetna", "plan_name": "PPO", "description": "150% of the fee schedule"}]}]
+}`
+	if err := os.WriteFile(jsonPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for _, input := range []string{writeTallCSV(t), writeWideCSV(t), jsonPath} {
+		name := filepath.Base(input)
+
+		parquetPath := filepath.Join(t.TempDir(), "two_step.parquet")
+		if err := convert(input, parquetPath, 100, encodingAuto); err != nil {
+			t.Fatalf("%s: convert: %v", name, err)
+		}
+		if err := loadParquetToPg(ctx, parquetPath, testConnStr, 2, false, false); err != nil {
+			t.Fatalf("%s: loadParquetToPg: %v", name, err)
+		}
+		want := dumpLoadedTables(t, tdb.pool)
+
+		if err := loadFileToPg(ctx, input, testConnStr, 2, false, false, encodingAuto); err != nil {
+			t.Fatalf("%s: loadFileToPg: %v", name, err)
+		}
+		got := dumpLoadedTables(t, tdb.pool)
+
+		if len(want) == 0 {
+			t.Fatalf("%s: two-step load wrote nothing", name)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: direct load differs from two-step load\n got: %q\nwant: %q", name, got, want)
+		}
+	}
+}
+
+// sliceSource is a rowSource over rows already in memory.
+type sliceSource struct {
+	rows []HospitalChargeRow
+}
+
+func (s *sliceSource) Next() ([]HospitalChargeRow, error) {
+	rows := s.rows
+	s.rows = nil
+	return rows, io.EOF
+}
+
+// failingSource returns its rows, then fails with err.
+type failingSource struct {
+	rows []HospitalChargeRow
+	err  error
+}
+
+func (s *failingSource) Next() ([]HospitalChargeRow, error) {
+	if s.rows == nil {
+		return nil, s.err
+	}
+	rows := s.rows
+	s.rows = nil
+	return rows, nil
+}
+
+// benchmarkRows returns a hospital with n items, each with an inpatient and
+// an outpatient charge negotiated by two payers.
+func benchmarkRows(n int) []HospitalChargeRow {
+	rows := make([]HospitalChargeRow, 0, n*4)
+	for i := range n {
+		for _, setting := range []string{"inpatient", "outpatient"} {
+			for _, payer := range []string{"Aetna", "Cigna"} {
+				rows = append(rows, HospitalChargeRow{
+					HospitalName:      "Benchmark Hospital",
+					LastUpdatedOn:     "2024-01-15",
+					Version:           "2.0.0",
+					HospitalAddresses: []string{"1 Bench St, New York, NY 10001"},
+					Description:       fmt.Sprintf("PROCEDURE %d", i),
+					Setting:           setting,
+					CPTCode:           strPtr(fmt.Sprintf("%05d", i)),
+					RCCode:            strPtr("0360"),
+					GrossCharge:       f64Ptr(float64(1000 + i)),
+					DiscountedCash:    f64Ptr(float64(500 + i)),
+					PayerName:         strPtr(payer),
+					PlanName:          strPtr(payer + " PPO"),
+					Methodology:       strPtr("fee schedule"),
+					NegotiatedDollar:  f64Ptr(float64(700 + i)),
+				})
+			}
+		}
+	}
+	return rows
+}
+
+// BenchmarkLoadRowsToPg loads a 5,000-item hospital into an empty database
+// and reports the load throughput.
+func BenchmarkLoadRowsToPg(b *testing.B) {
+	tdb := setupTestDB(b)
+	defer tdb.teardown()
+
+	ctx := context.Background()
+	rows := benchmarkRows(5000)
+	noModifiers := func() ([]ModifierRow, error) { return nil, nil }
+
+	for _, skip := range []bool{true, false} {
+		b.Run(fmt.Sprintf("skip-payer-charges=%v", skip), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				if _, err := tdb.pool.Exec(ctx, "TRUNCATE "+strings.Join(loadedTables, ", ")+" RESTART IDENTITY CASCADE"); err != nil {
+					b.Fatalf("truncate: %v", err)
+				}
+				b.StartTimer()
+
+				src := &sliceSource{rows: rows}
+				if err := loadRowsToPg(ctx, tdb.pool, src, int64(len(rows)), noModifiers, 500, skip, false); err != nil {
+					b.Fatalf("loadRowsToPg: %v", err)
+				}
+			}
+			b.ReportMetric(float64(len(rows)*b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
@@ -80,6 +80,10 @@ type HospitalChargeRow struct {
 	NegotiatedPercentage *float64 `parquet:"negotiated_percentage,optional"`
 	NegotiatedAlgorithm  *string  `parquet:"negotiated_algorithm,optional"`
 	EstimatedAmount      *float64 `parquet:"estimated_amount,optional"`
+	MedianAmount         *float64 `parquet:"median_amount,optional"`   // V3: median allowed amount
+	Percentile10th       *float64 `parquet:"percentile_10th,optional"` // V3: 10th percentile allowed amount
+	Percentile90th       *float64 `parquet:"percentile_90th,optional"` // V3: 90th percentile allowed amount
+	Count                *string  `parquet:"count,optional"`           // V3: claim count, "0"|"1 through 10"|"11"...
 	MinCharge            *float64 `parquet:"min_charge,optional"`
 	MaxCharge            *float64 `parquet:"max_charge,optional"`
 	Methodology          *string  `parquet:"methodology,optional"` // case_rate|fee_schedule|percent_of_total_billed_charges|per_diem|other
@@ -102,14 +106,29 @@ type HospitalChargeRow struct {
 	// Identical across all rows in a single file. Dictionary + RLE
 	// compresses to near-zero. Placed last — rarely filtered, only
 	// needed in SELECT projections.
-	HospitalName     string  `parquet:"hospital_name"`
-	LastUpdatedOn    string  `parquet:"last_updated_on"`
-	Version          string  `parquet:"version"`
-	HospitalLocation string  `parquet:"hospital_location"`
-	HospitalAddress  string  `parquet:"hospital_address"`
-	LicenseNumber    *string `parquet:"license_number,optional"`
-	LicenseState     *string `parquet:"license_state,optional"`
-	Affirmation      bool    `parquet:"affirmation"`
+	HospitalName      string   `parquet:"hospital_name"`
+	LastUpdatedOn     string   `parquet:"last_updated_on"`
+	Version           string   `parquet:"version"`
+	HospitalLocations []string `parquet:"hospital_location,list"` // location names, one per element
+	HospitalAddresses []string `parquet:"hospital_address,list"`
+	Type2NPIs         []string `parquet:"type_2_npi,list"` // V3
+	LicenseNumber     *string  `parquet:"license_number,optional"`
+	LicenseState      *string  `parquet:"license_state,optional"`
+	Affirmation       bool     `parquet:"affirmation"`
+	AttesterName      *string  `parquet:"attester_name,optional"` // V3
+}
+
+// ModifierRow is one modifier_information entry × one of its
+// modifier_payer_information entries, written to the <base>_modifiers.parquet
+// side file next to the charges. A modifier without payer information has
+// a single row with null payer columns.
+type ModifierRow struct {
+	Code             string  `parquet:"code"`
+	Description      string  `parquet:"description"`
+	Setting          *string `parquet:"setting,optional"` // inpatient | outpatient | both
+	PayerName        *string `parquet:"payer_name,optional"`
+	PlanName         *string `parquet:"plan_name,optional"`
+	PayerDescription *string `parquet:"payer_description,optional"`
 }
 
 // codeTypeToField maps CSV code|type values to their dedicated Parquet column.
@@ -145,3 +164,10 @@ func (r *HospitalChargeRow) SetCode(codeType, codeValue string) bool {
 	}
 	return false
 }
+
+// codePair is one code and code type as written in the source file. Unlike
+// the dedicated code columns it keeps incomplete pairs and unknown types.
+type codePair struct {
+	code     string
+	codeType string
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// conformanceRule is one CMS hospital price transparency requirement
+// checked by -validate. IDs are stable so analysts can track a rule across
+// reports; descriptions follow the wording of the V2.2 README and the
+// V2.2.1 / V3.0.0 JSON schemas in schemas/.
+type conformanceRule struct {
+	id   string
+	desc string
+}
+
+var (
+	ruleHospitalName    = conformanceRule{"hospital_name", "hospital_name is required"}
+	ruleLastUpdatedOn   = conformanceRule{"last_updated_on", "last_updated_on must be a date (YYYY-MM-DD; CSV also accepts M/D/YYYY)"}
+	ruleVersion         = conformanceRule{"version", "version is required"}
+	ruleLocation        = conformanceRule{"hospital_location", "hospital_location (V3: location_name) is required"}
+	ruleAddress         = conformanceRule{"hospital_address", "hospital_address is required"}
+	ruleLicenseState    = conformanceRule{"license_state", "license_number|[state] must name a two-letter state code"}
+	ruleType2NPI        = conformanceRule{"type_2_npi", "V3: type_2_npi is required"}
+	ruleAttesterName    = conformanceRule{"attester_name", "V3: attester_name is required"}
+	ruleAffirmation     = conformanceRule{"affirmation", "the affirmation (V3: attestation) statement must be true"}
+	ruleDescription     = conformanceRule{"description", "every item or service needs a description"}
+	ruleCodeMissing     = conformanceRule{"code_missing", "every item or service needs a code and code type"}
+	ruleCodeIncomplete  = conformanceRule{"code_incomplete", "code and code type must be encoded together"}
+	ruleCodeType        = conformanceRule{"code_type", "code type must be one of the CMS code types"}
+	ruleNDCDrug         = conformanceRule{"ndc_drug_information", "NDC items need drug_unit_of_measurement and drug_type_of_measurement"}
+	ruleDrugIncomplete  = conformanceRule{"drug_incomplete", "drug unit and drug type of measurement must be encoded together"}
+	ruleDrugType        = conformanceRule{"drug_type", "drug_type_of_measurement must be GR, ML, ME, UN, F2, GM or EA"}
+	ruleSetting         = conformanceRule{"setting", "setting must be inpatient, outpatient or both"}
+	ruleChargeMissing   = conformanceRule{"charge_missing", "every item needs a gross charge, discounted cash price or payer-specific negotiated charge"}
+	rulePositive        = conformanceRule{"not_positive", "numeric charges must be greater than zero"}
+	ruleMinMax          = conformanceRule{"min_max", "a negotiated dollar amount requires standard_charge|min and standard_charge|max"}
+	rulePayerPlan       = conformanceRule{"payer_plan", "payer-specific charges need payer_name and plan_name"}
+	ruleMethodology     = conformanceRule{"methodology", "payer-specific charges need standard_charge|methodology"}
+	ruleMethodologyEnum = conformanceRule{"methodology_enum", "methodology must be case rate, fee schedule, percent of total billed charges, per diem or other"}
+	ruleOtherNotes      = conformanceRule{"methodology_other_notes", "methodology \"other\" requires additional_payer_notes"}
+	ruleNegotiated      = conformanceRule{"negotiated_missing", "V3: payer-specific charges need a negotiated dollar, percentage or algorithm"}
+	ruleEstimated       = conformanceRule{"estimated_amount", "V2: a percentage or algorithm without a dollar amount requires estimated_amount"}
+	ruleCount           = conformanceRule{"count", "V3: a percentage or algorithm requires count"}
+	ruleCountFormat     = conformanceRule{"count_format", "V3: count must be 0, \"1 through 10\" or a number of 11 or more"}
+	ruleAllowedAmounts  = conformanceRule{"allowed_amounts", "V3: a percentage or algorithm with a nonzero count requires median_amount, 10th_percentile and 90th_percentile"}
+	ruleCountZeroNotes  = conformanceRule{"count_zero_notes", "V3: count 0 requires additional_payer_notes"}
+)
+
+// conformanceRules is the report order.
+var conformanceRules = []conformanceRule{
+	ruleHospitalName, ruleLastUpdatedOn, ruleVersion, ruleLocation, ruleAddress,
+	ruleLicenseState, ruleType2NPI, ruleAttesterName, ruleAffirmation,
+	ruleDescription, ruleCodeMissing, ruleCodeIncomplete, ruleCodeType,
+	ruleNDCDrug, ruleDrugIncomplete, ruleDrugType, ruleSetting,
+	ruleChargeMissing, rulePositive, ruleMinMax,
+	rulePayerPlan, ruleMethodology, ruleMethodologyEnum, ruleOtherNotes,
+	ruleNegotiated, ruleEstimated, ruleCount, ruleCountFormat,
+	ruleAllowedAmounts, ruleCountZeroNotes,
+}
+
+var (
+	v2CodeTypes = setOf("CPT", "HCPCS", "ICD", "DRG", "MS-DRG", "R-DRG", "S-DRG", "APS-DRG", "AP-DRG",
+		"APR-DRG", "TRIS-DRG", "APC", "NDC", "HIPPS", "LOCAL", "EAPG", "CDT", "RC", "CDM")
+	v3CodeTypes   = setOf(append(keysOf(v2CodeTypes), "CMG", "MS-LTC-DRG")...)
+	drugTypes     = setOf("GR", "ML", "ME", "UN", "F2", "GM", "EA")
+	settings      = setOf("inpatient", "outpatient", "both")
+	methodologies = setOf("case rate", "fee schedule", "percent of total billed charges", "per diem", "other")
+	states        = setOf("AL", "AK", "AS", "AZ", "AR", "CA", "CO", "CT", "DE", "DC", "FM", "FL", "GA", "GU",
+		"HI", "ID", "IL", "IN", "IA", "KS", "KY", "LA", "ME", "MH", "MD", "MA", "MI", "MN", "MS", "MO",
+		"MT", "NE", "NV", "NH", "NJ", "NM", "NY", "NC", "ND", "MP", "OH", "OK", "OR", "PW", "PA", "PR",
+		"RI", "SC", "SD", "TN", "TX", "UT", "VT", "VI", "VA", "WA", "WV", "WI", "WY")
+
+	countRe = regexp.MustCompile(`^(0|1 through 10|1[1-9]|[2-9]\d+|[1-9]\d{2,})$`)
+)
+
+func setOf(vals ...string) map[string]bool {
+	m := make(map[string]bool, len(vals))
+	for _, v := range vals {
+		m[v] = true
+	}
+	return m
+}
+
+func keysOf(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// maxViolationSamples is how many locations the report lists per rule.
+const maxViolationSamples = 5
+
+type ruleViolations struct {
+	count   int64
+	samples []string
+}
+
+// conformanceReport collects the violations in one hospital file.
+type conformanceReport struct {
+	input   string
+	format  string
+	v3      bool
+	csv     bool
+	meta    hospitalMeta
+	records int64
+	byRule  map[string]*ruleViolations
+}
+
+// validatingReader is a chargeReader that also exposes what the
+// conformance checks need beyond HospitalChargeRow.
+type validatingReader interface {
+	chargeReader
+	Meta() hospitalMeta
+	Codes() []codePair
+}
+
+// validate checks each hospital file in inputPath against the CMS rules
+// and writes one report per file to w. It returns the total violations.
+func validate(inputPath string, enc textEncoding, w io.Writer) (int64, error) {
+	members, closeInput, err := listInputMembers(inputPath)
+	if err != nil {
+		return 0, err
+	}
+	defer closeInput()
+
+	var total int64
+	for i, m := range members {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		rep, err := validateMember(m, enc)
+		if err != nil {
+			if len(members) > 1 {
+				return total, fmt.Errorf("%s: %w", m.Name, err)
+			}
+			return total, err
+		}
+		rep.write(w)
+		total += rep.total()
+	}
+	return total, nil
+}
+
+// validateMember reads one hospital file and checks every record.
+func validateMember(m inputMember, enc textEncoding) (*conformanceReport, error) {
+	reader, err := openMemberReader(m, enc)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	vr := reader.(validatingReader)
+
+	csvReader, isCSV := reader.(*CSVReader)
+	rep := &conformanceReport{
+		input:  m.Name,
+		format: reader.Format(),
+		v3:     reader.Format() == "json-v3" || (isCSV && csvReader.TemplateVersion() == "v3"),
+		csv:    isCSV,
+		meta:   vr.Meta(),
+		byRule: make(map[string]*ruleViolations),
+	}
+	rep.checkHeader()
+
+	for {
+		rows, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, readError(reader, err)
+		}
+		rep.records++
+		where := fmt.Sprintf("item %d", rep.records)
+		if isCSV {
+			where = fmt.Sprintf("row %d", csvReader.RowNum())
+		}
+		rep.checkRecord(where, rows, vr.Codes())
+	}
+	return rep, nil
+}
+
+func (rep *conformanceReport) add(rule conformanceRule, where, detail string) {
+	v := rep.byRule[rule.id]
+	if v == nil {
+		v = &ruleViolations{}
+		rep.byRule[rule.id] = v
+	}
+	v.count++
+	if len(v.samples) < maxViolationSamples {
+		if detail != "" {
+			where += ": " + detail
+		}
+		v.samples = append(v.samples, where)
+	}
+}
+
+func (rep *conformanceReport) total() int64 {
+	var n int64
+	for _, v := range rep.byRule {
+		n += v.count
+	}
+	return n
+}
+
+// checkHeader checks the general data elements (CSV rows 1-2, JSON
+// top-level fields).
+func (rep *conformanceReport) checkHeader() {
+	const where = "header"
+	m := &rep.meta
+	if m.hospitalName == "" {
+		rep.add(ruleHospitalName, where, "")
+	}
+	if !validDate(m.lastUpdatedOn, rep.csv) {
+		rep.add(ruleLastUpdatedOn, where, quoted(m.lastUpdatedOn))
+	}
+	if m.version == "" {
+		rep.add(ruleVersion, where, "")
+	}
+	if len(m.hospitalLocations) == 0 {
+		rep.add(ruleLocation, where, "")
+	}
+	if len(m.hospitalAddresses) == 0 {
+		rep.add(ruleAddress, where, "")
+	}
+	if m.licenseState == nil || !states[strings.ToUpper(*m.licenseState)] {
+		rep.add(ruleLicenseState, where, quoted(deref(m.licenseState)))
+	}
+	if rep.v3 && len(m.type2NPIs) == 0 {
+		rep.add(ruleType2NPI, where, "")
+	}
+	if rep.v3 && m.attesterName == nil {
+		rep.add(ruleAttesterName, where, "")
+	}
+	if !m.affirmation {
+		rep.add(ruleAffirmation, where, "")
+	}
+}
+
+func validDate(s string, csv bool) bool {
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return true
+	}
+	if csv {
+		_, err := time.Parse("1/2/2006", s)
+		return err == nil
+	}
+	return false
+}
+
+// checkRecord checks one CSV row or JSON item. rows all describe the same
+// item: one per payer/plan, and for JSON one set per standard_charges
+// entry. Item checks run once and charge checks once per distinct charge.
+func (rep *conformanceReport) checkRecord(where string, rows []HospitalChargeRow, codes []codePair) {
+	if len(rows) == 0 {
+		return
+	}
+	item := &rows[0]
+	if item.Description == "" {
+		rep.add(ruleDescription, where, "")
+	}
+	rep.checkCodes(where, item, codes)
+
+	hasCharge := false
+	var prev *HospitalChargeRow
+	for i := range rows {
+		r := &rows[i]
+		if prev == nil || !sameCharge(prev, r) {
+			rep.checkCharge(where, r, rows[i:])
+		}
+		prev = r
+		if r.GrossCharge != nil || r.DiscountedCash != nil || r.NegotiatedDollar != nil ||
+			r.NegotiatedPercentage != nil || r.NegotiatedAlgorithm != nil {
+			hasCharge = true
+		}
+		if r.PayerName != nil || r.PlanName != nil {
+			rep.checkPayer(where, r)
+		}
+	}
+	if !hasCharge {
+		rep.add(ruleChargeMissing, where, "")
+	}
+}
+
+func (rep *conformanceReport) checkCodes(where string, item *HospitalChargeRow, codes []codePair) {
+	known := v2CodeTypes
+	if rep.v3 {
+		known = v3CodeTypes
+	}
+	complete, ndc := 0, false
+	for _, c := range codes {
+		switch {
+		case c.code == "" || c.codeType == "":
+			rep.add(ruleCodeIncomplete, where, fmt.Sprintf("code %q, type %q", c.code, c.codeType))
+		case !known[c.codeType]:
+			rep.add(ruleCodeType, where, quoted(c.codeType))
+		default:
+			complete++
+			ndc = ndc || c.codeType == "NDC"
+		}
+	}
+	if complete == 0 {
+		rep.add(ruleCodeMissing, where, "")
+	}
+
+	unit, typ := item.DrugUnitOfMeasurement, item.DrugTypeOfMeasurement
+	switch {
+	case ndc && (unit == nil || typ == nil):
+		rep.add(ruleNDCDrug, where, "")
+	case (unit == nil) != (typ == nil):
+		rep.add(ruleDrugIncomplete, where, "")
+	}
+	if typ != nil && !drugTypes[*typ] {
+		rep.add(ruleDrugType, where, quoted(*typ))
+	}
+	if unit != nil && *unit <= 0 {
+		rep.add(rulePositive, where, fmt.Sprintf("drug_unit_of_measurement %g", *unit))
+	}
+}
+
+// sameCharge reports whether a and b come from the same standard charge:
+// the payer rows of one Wide row or one JSON standard_charges entry.
+func sameCharge(a, b *HospitalChargeRow) bool {
+	return a.Setting == b.Setting && eqFloat(a.GrossCharge, b.GrossCharge) &&
+		eqFloat(a.DiscountedCash, b.DiscountedCash) &&
+		eqFloat(a.MinCharge, b.MinCharge) && eqFloat(a.MaxCharge, b.MaxCharge)
+}
+
+func eqFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// checkCharge checks the item-level charges of r. payers holds r and the
+// rows after it, which share its charge when sameCharge says so.
+func (rep *conformanceReport) checkCharge(where string, r *HospitalChargeRow, payers []HospitalChargeRow) {
+	if !settings[r.Setting] {
+		rep.add(ruleSetting, where, quoted(r.Setting))
+	}
+	for _, f := range []struct {
+		name string
+		v    *float64
+	}{
+		{"gross charge", r.GrossCharge},
+		{"discounted cash", r.DiscountedCash},
+		{"min", r.MinCharge},
+		{"max", r.MaxCharge},
+	} {
+		if f.v != nil && *f.v <= 0 {
+			rep.add(rulePositive, where, fmt.Sprintf("%s %g", f.name, *f.v))
+		}
+	}
+	if r.MinCharge != nil && r.MaxCharge != nil {
+		return
+	}
+	for i := range payers {
+		if !sameCharge(r, &payers[i]) {
+			break
+		}
+		if payers[i].NegotiatedDollar != nil {
+			rep.add(ruleMinMax, where, "")
+			return
+		}
+	}
+}
+
+// checkPayer checks one payer/plan's negotiated charge.
+func (rep *conformanceReport) checkPayer(where string, r *HospitalChargeRow) {
+	payer := fmt.Sprintf("%s / %s", deref(r.PayerName), deref(r.PlanName))
+	if deref(r.PayerName) == "" || deref(r.PlanName) == "" {
+		rep.add(rulePayerPlan, where, payer)
+	}
+
+	negotiated := r.NegotiatedDollar != nil || r.NegotiatedPercentage != nil || r.NegotiatedAlgorithm != nil
+	switch {
+	case r.Methodology == nil:
+		if negotiated {
+			rep.add(ruleMethodology, where, payer)
+		}
+	case !methodologies[*r.Methodology]:
+		rep.add(ruleMethodologyEnum, where, payer+": "+quoted(*r.Methodology))
+	case *r.Methodology == "other" && r.AdditionalPayerNotes == nil:
+		rep.add(ruleOtherNotes, where, payer)
+	}
+
+	for _, f := range []struct {
+		name string
+		v    *float64
+	}{
+		{"negotiated dollar", r.NegotiatedDollar},
+		{"negotiated percentage", r.NegotiatedPercentage},
+		{"estimated amount", r.EstimatedAmount},
+		{"median amount", r.MedianAmount},
+		{"10th percentile", r.Percentile10th},
+		{"90th percentile", r.Percentile90th},
+	} {
+		if f.v != nil && *f.v <= 0 {
+			rep.add(rulePositive, where, fmt.Sprintf("%s: %s %g", payer, f.name, *f.v))
+		}
+	}
+
+	pctOrAlgo := r.NegotiatedPercentage != nil || r.NegotiatedAlgorithm != nil
+	if !rep.v3 {
+		if pctOrAlgo && r.NegotiatedDollar == nil && r.EstimatedAmount == nil {
+			rep.add(ruleEstimated, where, payer)
+		}
+		return
+	}
+
+	if !negotiated {
+		rep.add(ruleNegotiated, where, payer)
+	}
+	if r.Count != nil && !countRe.MatchString(*r.Count) {
+		rep.add(ruleCountFormat, where, payer+": "+quoted(*r.Count))
+	}
+	if r.Count != nil && *r.Count == "0" && r.AdditionalPayerNotes == nil {
+		rep.add(ruleCountZeroNotes, where, payer)
+	}
+	if !pctOrAlgo {
+		return
+	}
+	switch {
+	case r.Count == nil:
+		rep.add(ruleCount, where, payer)
+	case *r.Count != "0" && (r.MedianAmount == nil || r.Percentile10th == nil || r.Percentile90th == nil):
+		rep.add(ruleAllowedAmounts, where, payer)
+	}
+}
+
+// write prints the report: the hospital, then each violated rule with its
+// count and the first few locations.
+func (rep *conformanceReport) write(w io.Writer) {
+	template := "V2"
+	if rep.v3 {
+		template = "V3"
+	}
+	records := "CSV rows"
+	if !rep.csv {
+		records = "JSON items"
+	}
+	name := rep.meta.hospitalName
+	if name == "" {
+		name = "(no hospital_name)"
+	}
+
+	fmt.Fprintf(w, "Hospital: %s\n", name)
+	fmt.Fprintf(w, "Input:    %s\n", rep.input)
+	fmt.Fprintf(w, "Format:   %s, %s template\n", rep.format, template)
+	fmt.Fprintf(w, "Checked:  %d %s\n", rep.records, records)
+
+	total := rep.total()
+	if total == 0 {
+		fmt.Fprintf(w, "Result:   no violations\n")
+		return
+	}
+	fmt.Fprintf(w, "Result:   %d violations of %d rules\n", total, len(rep.byRule))
+	for _, rule := range conformanceRules {
+		v := rep.byRule[rule.id]
+		if v == nil {
+			continue
+		}
+		fmt.Fprintf(w, "\n  [%s] %s — %d\n", rule.id, rule.desc, v.count)
+		for _, s := range v.samples {
+			fmt.Fprintf(w, "      %s\n", s)
+		}
+		if more := v.count - int64(len(v.samples)); more > 0 {
+			fmt.Fprintf(w, "      … %d more\n", more)
+		}
+	}
+}
+
+func quoted(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
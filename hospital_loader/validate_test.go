@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func validateFile(t *testing.T, name, content string) *conformanceReport {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	members, closeInput, err := listInputMembers(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeInput()
+	rep, err := validateMember(members[0], encodingAuto)
+	if err != nil {
+		t.Fatalf("validateMember: %v", err)
+	}
+	return rep
+}
+
+// assertViolations checks the violation count per rule ID; rules not
+// listed must have none.
+func assertViolations(t *testing.T, rep *conformanceReport, want map[string]int64) {
+	t.Helper()
+	for _, rule := range conformanceRules {
+		var got int64
+		if v := rep.byRule[rule.id]; v != nil {
+			got = v.count
+		}
+		if got != want[rule.id] {
+			t.Errorf("[%s] %d violations, want %d (%v)", rule.id, got, want[rule.id], rep.byRule[rule.id])
+		}
+	}
+}
+
+func TestValidateCSV(t *testing.T) {
+	content := `hospital_name,last_updated_on,version,hospital_location,hospital_address,license_number|ZZ,"To the best of its knowledge and belief, the hospital has included all applicable standard charge information in accordance with the requirements of 45 CFR 180.50, and the information encoded is true, accurate, and complete as of the date indicated."
+Test Hospital,7/1/2024,2.2.0,Main,1 Main St,123,false
+description,code|1,code|1|type,code|2,code|2|type,setting,drug_unit_of_measurement,drug_type_of_measurement,standard_charge|gross,standard_charge|discounted_cash,payer_name,plan_name,standard_charge|negotiated_dollar,standard_charge|negotiated_percentage,standard_charge|negotiated_algorithm,estimated_amount,standard_charge|min,standard_charge|max,standard_charge|methodology,additional_generic_notes,additional_payer_notes
+X-RAY,71046,CPT,,,outpatient,,,250,200,Aetna,PPO,180,,,,150,300,fee schedule,,
+DRUG,0001-0001,NDC,,XYZ,Outpatient,,,10,,Aetna,PPO,,50,,,,,Fee Schedule,,
+MRI,70553,CPT,,,inpatient,,,-5,,Cigna,HMO,400,,,,,,other,,
+,,,,,both,,,,,,,,,,,,,,,
+`
+	rep := validateFile(t, "v2.csv", content)
+	if rep.records != 4 || rep.v3 {
+		t.Fatalf("records = %d, v3 = %v", rep.records, rep.v3)
+	}
+	assertViolations(t, rep, map[string]int64{
+		"license_state":           1,
+		"affirmation":             1,
+		"description":             1,
+		"code_missing":            1,
+		"code_incomplete":         1,
+		"ndc_drug_information":    1,
+		"setting":                 1,
+		"charge_missing":          1,
+		"not_positive":            1,
+		"min_max":                 1,
+		"methodology_enum":        1,
+		"methodology_other_notes": 1,
+		"estimated_amount":        1,
+	})
+	if got := rep.byRule["setting"].samples; len(got) != 1 || got[0] != `row 5: "Outpatient"` {
+		t.Errorf("setting samples = %q", got)
+	}
+
+	var out bytes.Buffer
+	rep.write(&out)
+	for _, want := range []string{"Hospital: Test Hospital", "Result:   13 violations of 13 rules", `[methodology_enum]`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+// TestValidateCSVV3 checks the template-style fixtures, whose Region
+// Health Insurance rows leave out what V3 requires alongside count.
+func TestValidateCSVV3(t *testing.T) {
+	assertViolations(t, validateFile(t, "v3_tall.csv", v3TallCSV), map[string]int64{
+		"count_zero_notes": 1,
+	})
+	assertViolations(t, validateFile(t, "v3_wide.csv", v3WideCSV), map[string]int64{
+		"allowed_amounts": 1,
+	})
+}
+
+func TestValidateJSONV3(t *testing.T) {
+	content := `{"hospital_name": "JSON Hospital", "last_updated_on": "2025-01-01", "version": "3.0.0",
+ "location_name": ["JSON Hospital"], "hospital_address": ["1 Main St"],
+ "license_information": {"license_number": "1", "state": "CA"},
+ "attestation": {"attestation": "...", "confirm_attestation": true},
+ "standard_charge_information": [
+  {"description": "ROOM", "code_information": [{"code": "0110", "type": "RC"}, {"code": "A1", "type": "CMG"}],
+   "standard_charges": [
+    {"setting": "inpatient", "gross_charge": 1000, "minimum": 500, "maximum": 900,
+     "payers_information": [
+      {"payer_name": "Aetna", "plan_name": "PPO", "methodology": "per diem", "standard_charge_dollar": 900},
+      {"payer_name": "Cigna", "plan_name": "HMO", "methodology": "percent of total billed charges", "standard_charge_percentage": 60},
+      {"payer_name": "BCBS", "plan_name": "PPO", "methodology": "case rate", "standard_charge_algorithm": "DRG x 1.2", "count": "0"},
+      {"payer_name": "UHC", "plan_name": "EPO", "methodology": "case rate", "standard_charge_percentage": 70, "count": "5",
+       "median_amount": 1, "10th_percentile": 1, "90th_percentile": 1}]},
+    {"setting": "outpatient", "gross_charge": 800,
+     "payers_information": [
+      {"payer_name": "Aetna", "plan_name": "PPO", "methodology": "per diem", "standard_charge_dollar": 700},
+      {"payer_name": "Cigna", "plan_name": "HMO", "methodology": "per diem", "standard_charge_dollar": 650}]}]},
+  {"description": "DRUG", "code_information": [{"code": "0002", "type": "NDC"}],
+   "standard_charges": [{"setting": "outpatient", "gross_charge": 5}]}]}`
+	rep := validateFile(t, "v3.json", content)
+	if !rep.v3 || rep.records != 2 {
+		t.Fatalf("v3 = %v, records = %d", rep.v3, rep.records)
+	}
+	assertViolations(t, rep, map[string]int64{
+		"type_2_npi":           1,
+		"attester_name":        1,
+		"ndc_drug_information": 1,
+		"min_max":              1,
+		"count":                1,
+		"count_format":         1,
+		"count_zero_notes":     1,
+	})
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/parquet-go/parquet-go"
 	"github.com/parquet-go/parquet-go/compress/zstd"
@@ -77,6 +78,34 @@ func (w *ChargeWriter) Close() error {
 	return w.file.Close()
 }
 
+// modifiersPath returns the modifiers side file for a charges Parquet path:
+// out.parquet → out_modifiers.parquet.
+func modifiersPath(chargesPath string) string {
+	return strings.TrimSuffix(chargesPath, ".parquet") + "_modifiers.parquet"
+}
+
+// WriteModifiers writes modifier rows to a Parquet file. Modifier lists are
+// small (tens to hundreds of codes), so they are written in one call.
+func WriteModifiers(filename string, rows []ModifierRow) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("create modifiers parquet file: %w", err)
+	}
+	writer := parquet.NewGenericWriter[ModifierRow](file,
+		parquet.Compression(&zstd.Codec{Level: zstd.SpeedDefault}),
+		parquet.CreatedBy("pricetool", "1.0", ""),
+	)
+	if _, err := writer.Write(rows); err != nil {
+		file.Close()
+		return fmt.Errorf("write modifier rows: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return fmt.Errorf("close modifiers parquet writer: %w", err)
+	}
+	return file.Close()
+}
+
 // Count returns the total number of rows written.
 func (w *ChargeWriter) Count() int {
 	return w.count
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// runManifestName is the default run manifest file name.
+const runManifestName = "_run_manifest.json"
+
+// Run manifest file statuses.
+const (
+	StatusConverted = "converted"
+	StatusSkipped   = "skipped"
+	StatusFailed    = "failed"
+)
+
+// BatchOptions configures a multi-file conversion.
+type BatchOptions struct {
+	Convert  ConvertOptions
+	OutDir   string // "" writes outputs next to each input
+	Workers  int
+	Hash     bool   // also fingerprint inputs by SHA-256
+	Force    bool   // reconvert files the previous run already converted
+	Manifest string // run manifest path
+}
+
+// RunManifest records the outcome of a batch run. It is rewritten after
+// every file, so an interrupted run still records finished files and the
+// next run skips them.
+type RunManifest struct {
+	StartedAt  string    `json:"started_at"`
+	FinishedAt string    `json:"finished_at,omitempty"` // empty while running
+	Converted  int       `json:"converted"`
+	Skipped    int       `json:"skipped"`
+	Failed     int       `json:"failed"`
+	Files      []RunFile `json:"files"`
+}
+
+// RunFile is one input's entry in the run manifest.
+type RunFile struct {
+	Input      string          `json:"input"`
+	Size       int64           `json:"size"`
+	ModTime    string          `json:"mod_time"`
+	SHA256     string          `json:"sha256,omitempty"`
+	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Outputs    *ConvertOutputs `json:"outputs,omitempty"`
+	Stats      *ConvertStats   `json:"stats,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+}
+
+// isBatchInput reports whether -file names a directory or glob rather
+// than a single file.
+func isBatchInput(spec string) bool {
+	if strings.ContainsAny(spec, "*?[") {
+		return true
+	}
+	info, err := os.Stat(spec)
+	return err == nil && info.IsDir()
+}
+
+// isInNetworkInput reports whether a directory entry looks like an
+// in-network JSON input. Names starting with "_" (manifests) are skipped.
+func isInNetworkInput(name string) bool {
+	if strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") {
+		return false
+	}
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".json") || strings.HasSuffix(lower, ".json.gz")
+}
+
+// resolveBatchInputs expands a directory (walked recursively), a glob, or
+// a list file (one path per line, # comments; relative paths resolve
+// against the list's directory) into a sorted, de-duplicated input list.
+// It also returns the directory the run manifest defaults to.
+func resolveBatchInputs(spec, listFile string) ([]string, string, error) {
+	var (
+		inputs []string
+		dir    string
+	)
+	switch {
+	case listFile != "":
+		f, err := os.Open(listFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("open input list: %w", err)
+		}
+		defer f.Close()
+		dir = filepath.Dir(listFile)
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(dir, line)
+			}
+			inputs = append(inputs, line)
+		}
+		if err := sc.Err(); err != nil {
+			return nil, "", fmt.Errorf("read input list: %w", err)
+		}
+	case strings.ContainsAny(spec, "*?["):
+		matches, err := filepath.Glob(spec)
+		if err != nil {
+			return nil, "", fmt.Errorf("glob %s: %w", spec, err)
+		}
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && !info.IsDir() {
+				inputs = append(inputs, m)
+			}
+		}
+		dir = filepath.Dir(spec)
+		if strings.ContainsAny(dir, "*?[") {
+			dir = "."
+		}
+	default:
+		dir = spec
+		err := filepath.WalkDir(spec, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isInNetworkInput(d.Name()) {
+				inputs = append(inputs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("walk %s: %w", spec, err)
+		}
+	}
+
+	sort.Strings(inputs)
+	out := inputs[:0]
+	for i, in := range inputs {
+		if i == 0 || in != inputs[i-1] {
+			out = append(out, in)
+		}
+	}
+	return out, dir, nil
+}
+
+// batchOutputBase returns the output base for input. With an output
+// directory, bases are the input names (without .json/.gz) inside it.
+func batchOutputBase(input, outDir string) string {
+	base := defaultOutputBase(input)
+	if outDir == "" {
+		return base
+	}
+	return filepath.Join(outDir, filepath.Base(base))
+}
+
+// readRunManifest loads a previous run manifest; a missing file yields an
+// empty manifest.
+func readRunManifest(path string) (*RunManifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &RunManifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read run manifest: %w", err)
+	}
+	var m RunManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse run manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// writeRunManifest writes m atomically via a temp file and rename.
+func writeRunManifest(path string, m *RunManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode run manifest: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write run manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write run manifest: %w", err)
+	}
+	return nil
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// outputsExist reports whether every output path is present.
+func outputsExist(o *ConvertOutputs) bool {
+	if o == nil {
+		return false
+	}
+	for _, p := range o.Paths() {
+		if _, err := os.Stat(p); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// upToDate reports whether prev records a successful conversion of the
+// same input content to the expected outputs.
+func upToDate(prev *RunFile, cur *RunFile, want ConvertOutputs, useHash bool) bool {
+	if prev == nil || (prev.Status != StatusConverted && prev.Status != StatusSkipped) {
+		return false
+	}
+	if prev.Size != cur.Size || prev.ModTime != cur.ModTime {
+		return false
+	}
+	if useHash && (prev.SHA256 == "" || prev.SHA256 != cur.SHA256) {
+		return false
+	}
+	return prev.Outputs != nil && *prev.Outputs == want && outputsExist(prev.Outputs)
+}
+
+// runBatch converts inputs with up to opts.Workers files in flight. A
+// failing file is recorded in the manifest and does not stop the batch;
+// the returned error is reserved for setup and manifest I/O failures.
+func runBatch(inputs []string, opts BatchOptions) (*RunManifest, error) {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+
+	bases := make([]string, len(inputs))
+	seen := make(map[string]string, len(inputs))
+	for i, in := range inputs {
+		bases[i] = batchOutputBase(in, opts.OutDir)
+		if other, dup := seen[bases[i]]; dup {
+			return nil, fmt.Errorf("inputs %s and %s both write to %s", other, in, bases[i])
+		}
+		seen[bases[i]] = in
+	}
+	if opts.OutDir != "" {
+		if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+			return nil, fmt.Errorf("create output dir: %w", err)
+		}
+	}
+
+	prevManifest, err := readRunManifest(opts.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	prev := make(map[string]*RunFile, len(prevManifest.Files))
+	for i := range prevManifest.Files {
+		prev[prevManifest.Files[i].Input] = &prevManifest.Files[i]
+	}
+
+	m := &RunManifest{
+		StartedAt: time.Now().UTC().Format(time.RFC3339),
+		Files:     make([]RunFile, len(inputs)),
+	}
+	// Until an input is processed its entry carries the previous run's
+	// record, so an interrupted run does not forget earlier results.
+	for i, in := range inputs {
+		if p := prev[in]; p != nil {
+			m.Files[i] = *p
+		} else {
+			m.Files[i] = RunFile{Input: in}
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		finished int
+		saveErr  error
+	)
+	record := func(i int, rf RunFile) {
+		mu.Lock()
+		defer mu.Unlock()
+		m.Files[i] = rf
+		finished++
+		switch rf.Status {
+		case StatusFailed:
+			log.Printf("[%d/%d] %s failed: %s", finished, len(inputs), rf.Input, rf.Error)
+		case StatusSkipped:
+			log.Printf("[%d/%d] %s skipped (already converted)", finished, len(inputs), rf.Input)
+		default:
+			log.Printf("[%d/%d] %s converted in %s: %d rate rows, %d provider rows",
+				finished, len(inputs), rf.Input, time.Duration(rf.DurationMs)*time.Millisecond,
+				rf.Stats.RateRows, rf.Stats.ProviderRows)
+		}
+		m.tally()
+		if err := writeRunManifest(opts.Manifest, m); err != nil && saveErr == nil {
+			saveErr = err
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				record(i, convertBatchFile(inputs[i], bases[i], prev[inputs[i]], opts))
+			}
+		}()
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	m.FinishedAt = time.Now().UTC().Format(time.RFC3339)
+	m.tally()
+	if err := writeRunManifest(opts.Manifest, m); err != nil {
+		return m, err
+	}
+	return m, saveErr
+}
+
+// tally recounts file statuses.
+func (m *RunManifest) tally() {
+	m.Converted, m.Skipped, m.Failed = 0, 0, 0
+	for _, f := range m.Files {
+		switch f.Status {
+		case StatusConverted:
+			m.Converted++
+		case StatusSkipped:
+			m.Skipped++
+		case StatusFailed:
+			m.Failed++
+		}
+	}
+}
+
+// convertBatchFile converts one batch input, or skips it when prev shows
+// it is up to date. Panics are recovered into a failed entry.
+func convertBatchFile(input, base string, prev *RunFile, opts BatchOptions) (rf RunFile) {
+	start := time.Now()
+	rf = RunFile{Input: input}
+	defer func() {
+		if r := recover(); r != nil {
+			rf.Status = StatusFailed
+			rf.Error = fmt.Sprintf("panic: %v", r)
+		}
+		rf.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	info, err := os.Stat(input)
+	if err != nil {
+		rf.Status, rf.Error = StatusFailed, err.Error()
+		return rf
+	}
+	rf.Size = info.Size()
+	rf.ModTime = info.ModTime().UTC().Format(time.RFC3339Nano)
+	if opts.Hash {
+		if rf.SHA256, err = fileSHA256(input); err != nil {
+			rf.Status, rf.Error = StatusFailed, fmt.Sprintf("hash input: %v", err)
+			return rf
+		}
+	}
+
+	want := outputPaths(base, opts.Convert)
+	if !opts.Force && upToDate(prev, &rf, want, opts.Hash) {
+		rf.Status = StatusSkipped
+		rf.Outputs = prev.Outputs
+		rf.Stats = prev.Stats
+		if rf.SHA256 == "" {
+			rf.SHA256 = prev.SHA256
+		}
+		return rf
+	}
+
+	res, err := convertFile(input, base, opts.Convert)
+	if err != nil {
+		rf.Status, rf.Error = StatusFailed, err.Error()
+		return rf
+	}
+	rf.Status = StatusConverted
+	rf.Outputs = &res.Outputs
+	rf.Stats = res.Stats
+	return rf
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// batchFixture copies the named examples plus a truncated file into a
+// temp input directory.
+func batchFixture(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(examplesDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"in_network": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func batchStatuses(m *RunManifest) map[string]string {
+	st := make(map[string]string, len(m.Files))
+	for _, f := range m.Files {
+		st[filepath.Base(f.Input)] = f.Status
+	}
+	return st
+}
+
+func TestRunBatch(t *testing.T) {
+	const (
+		ffs    = "in-network-rates-fee-for-service-single-plan-sample.json"
+		bundle = "in-network-rates-bundle-single-plan-sample.json"
+	)
+	inDir := batchFixture(t, ffs, bundle)
+	outDir := filepath.Join(t.TempDir(), "out")
+
+	inputs, _, err := resolveBatchInputs(inDir, "")
+	if err != nil {
+		t.Fatalf("resolveBatchInputs: %v", err)
+	}
+	if len(inputs) != 3 {
+		t.Fatalf("inputs = %v, want 3", inputs)
+	}
+	opts := BatchOptions{
+		Convert:  ConvertOptions{BufferSize: 1 << 16, CodesJSON: true},
+		OutDir:   outDir,
+		Workers:  2,
+		Manifest: filepath.Join(outDir, runManifestName),
+	}
+
+	m, err := runBatch(inputs, opts)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if m.Converted != 2 || m.Failed != 1 || m.Skipped != 0 || m.FinishedAt == "" {
+		t.Errorf("manifest counts = %d/%d/%d, finished %q", m.Converted, m.Skipped, m.Failed, m.FinishedAt)
+	}
+	for _, f := range m.Files {
+		switch filepath.Base(f.Input) {
+		case "broken.json":
+			if f.Status != StatusFailed || !strings.Contains(f.Error, "in_network") {
+				t.Errorf("broken.json = %s %q", f.Status, f.Error)
+			}
+			if _, err := os.Stat(filepath.Join(outDir, "broken_rates.parquet")); !os.IsNotExist(err) {
+				t.Errorf("partial output of failed file not removed: %v", err)
+			}
+		case ffs:
+			if f.Stats == nil || f.Stats.RateRows != 5 || f.Stats.ProviderRows != 15 {
+				t.Errorf("ffs stats = %+v", f.Stats)
+			}
+			want := filepath.Join(outDir, "in-network-rates-fee-for-service-single-plan-sample_rates.parquet")
+			if f.Outputs == nil || f.Outputs.Rates != want || !outputsExist(f.Outputs) {
+				t.Errorf("ffs outputs = %+v", f.Outputs)
+			}
+		}
+	}
+
+	// The manifest on disk matches the returned one.
+	saved, err := readRunManifest(opts.Manifest)
+	if err != nil {
+		t.Fatalf("readRunManifest: %v", err)
+	}
+	if len(saved.Files) != 3 || saved.Failed != 1 {
+		t.Errorf("saved manifest = %+v", saved)
+	}
+
+	// A second run skips converted files and retries the failed one.
+	m, err = runBatch(inputs, opts)
+	if err != nil {
+		t.Fatalf("second runBatch: %v", err)
+	}
+	st := batchStatuses(m)
+	if st[ffs] != StatusSkipped || st[bundle] != StatusSkipped || st["broken.json"] != StatusFailed {
+		t.Errorf("second run statuses = %v", st)
+	}
+	for _, f := range m.Files {
+		if f.Status == StatusSkipped && (f.Stats == nil || f.Outputs == nil) {
+			t.Errorf("skipped %s lost its stats or outputs", f.Input)
+		}
+	}
+
+	// Touching an input reconverts it; -force reconverts everything.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(inDir, ffs), later, later); err != nil {
+		t.Fatal(err)
+	}
+	m, err = runBatch(inputs, opts)
+	if err != nil {
+		t.Fatalf("third runBatch: %v", err)
+	}
+	if st := batchStatuses(m); st[ffs] != StatusConverted || st[bundle] != StatusSkipped {
+		t.Errorf("after touch statuses = %v", st)
+	}
+	opts.Force = true
+	m, err = runBatch(inputs, opts)
+	if err != nil {
+		t.Fatalf("forced runBatch: %v", err)
+	}
+	if m.Converted != 2 || m.Skipped != 0 {
+		t.Errorf("forced counts = %d converted, %d skipped", m.Converted, m.Skipped)
+	}
+}
+
+func TestRunBatchHash(t *testing.T) {
+	const ffs = "in-network-rates-fee-for-service-single-plan-sample.json"
+	inDir := batchFixture(t, ffs)
+	input := filepath.Join(inDir, ffs)
+	opts := BatchOptions{
+		Convert:  ConvertOptions{BufferSize: 1 << 16},
+		Workers:  1,
+		Hash:     true,
+		Manifest: filepath.Join(inDir, runManifestName),
+	}
+
+	m, err := runBatch([]string{input}, opts)
+	if err != nil {
+		t.Fatalf("runBatch: %v", err)
+	}
+	if len(m.Files[0].SHA256) != 64 {
+		t.Fatalf("sha256 = %q", m.Files[0].SHA256)
+	}
+
+	// Same size and mtime but different content: only the hash notices.
+	info, _ := os.Stat(input)
+	data, _ := os.ReadFile(input)
+	data = []byte(strings.Replace(string(data), "123.45", "543.21", 1))
+	if err := os.WriteFile(input, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(input, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	m, err = runBatch([]string{input}, opts)
+	if err != nil {
+		t.Fatalf("second runBatch: %v", err)
+	}
+	if m.Files[0].Status != StatusConverted {
+		t.Errorf("status = %s, want converted after content change", m.Files[0].Status)
+	}
+}
+
+func TestResolveBatchInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.json", "b.json.gz", "notes.txt", "_run_manifest.json", "sub/c.json"} {
+		p := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, manifestDir, err := resolveBatchInputs(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json.gz"), filepath.Join(dir, "sub", "c.json")}
+	if strings.Join(got, ",") != strings.Join(want, ",") || manifestDir != dir {
+		t.Errorf("directory inputs = %v (%s), want %v", got, manifestDir, want)
+	}
+
+	got, _, err = resolveBatchInputs(filepath.Join(dir, "*.json"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 { // a.json and _run_manifest.json: globs are taken literally
+		t.Errorf("glob inputs = %v", got)
+	}
+
+	list := filepath.Join(dir, "files.txt")
+	os.WriteFile(list, []byte("# monthly drop\na.json\n\nsub/c.json\na.json\n"), 0644)
+	got, _, err = resolveBatchInputs("", list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != filepath.Join(dir, "a.json")+","+filepath.Join(dir, "sub", "c.json") {
+		t.Errorf("list inputs = %v", got)
+	}
+
+	if _, err := runBatch([]string{"x/a.json", "y/a.json.gz"}, BatchOptions{OutDir: t.TempDir()}); err == nil {
+		t.Error("expected error for inputs sharing an output base")
+	}
+}
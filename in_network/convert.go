@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ConvertOptions configures the conversion of one in-network file.
+type ConvertOptions struct {
+	BufferSize    int // read buffer in bytes
+	Verbose       bool
+	Partition     PartitionOptions
+	GroupIndexMem int
+	TmpDir        string
+	CodesJSON     bool
+	Summary       bool           // write <base>_summary.parquet
+	Networks      bool           // write <base>_networks.parquet
+	Format        string         // FormatParquet (default), FormatCSV or FormatCSVGzip
+	NPIFilter     map[int64]bool // nil converts all providers
+	AsOf          time.Time      // zero keeps expired prices
+
+	// TOC, when set, adds a <base>_plans.parquet bridge listing the plans
+	// whose reporting structures reference the input. SourceURL pins the
+	// match to one URL instead of matching by file name.
+	TOC       *TOCIndex
+	SourceURL string
+}
+
+// ConvertOutputs lists the outputs of one conversion. Each is a file, or a
+// part directory when partitioning is enabled. With a CSV format the rate,
+// provider, item and contained code outputs are CSV files; the summary and
+// plans outputs are always Parquet.
+type ConvertOutputs struct {
+	Rates          string `json:"rates"`
+	Providers      string `json:"providers"`
+	Items          string `json:"items"`
+	ContainedCodes string `json:"contained_codes"`
+	Summary        string `json:"summary,omitempty"`
+	Networks       string `json:"networks,omitempty"`
+	Plans          string `json:"plans,omitempty"` // only with a TOC
+}
+
+// Paths returns the outputs in a fixed order.
+func (o ConvertOutputs) Paths() []string {
+	paths := []string{o.Rates, o.Providers, o.Items, o.ContainedCodes}
+	for _, p := range []string{o.Summary, o.Networks, o.Plans} {
+		if p != "" {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// ConvertResult is returned by convertFile.
+type ConvertResult struct {
+	Stats             *ConvertStats
+	Outputs           ConvertOutputs
+	GroupIndexSpilled bool
+	RateParts         int // 0 unless partitioned
+	ProviderParts     int
+}
+
+// defaultOutputBase derives the output base from an input path.
+func defaultOutputBase(input string) string {
+	if input == stdioPath {
+		return stdinOutputBase
+	}
+	base := input
+	for _, ext := range []string{".gz", ".json"} {
+		base = strings.TrimSuffix(base, ext)
+	}
+	return base
+}
+
+// isCSV reports whether the row outputs are written as CSV.
+func (o ConvertOptions) isCSV() bool {
+	return o.Format == FormatCSV || o.Format == FormatCSVGzip
+}
+
+// validateFormat checks Format and the options that need Parquet outputs.
+func (o ConvertOptions) validateFormat() error {
+	switch o.Format {
+	case "", FormatParquet:
+		return nil
+	case FormatCSV, FormatCSVGzip:
+	default:
+		return fmt.Errorf("unknown output format %q (want %s, %s or %s)", o.Format, FormatParquet, FormatCSV, FormatCSVGzip)
+	}
+	if o.Partition.Enabled() {
+		return fmt.Errorf("part files and partitioning require %s output", FormatParquet)
+	}
+	if o.Networks {
+		return fmt.Errorf("the networks output is built from Parquet provider rows and requires %s output", FormatParquet)
+	}
+	return nil
+}
+
+// outputPaths returns the output locations for base.
+func outputPaths(base string, opts ConvertOptions) ConvertOutputs {
+	ext := ".parquet"
+	if opts.isCSV() {
+		ext = "." + opts.Format
+	}
+	out := ConvertOutputs{
+		Rates:          base + "_rates" + ext,
+		Providers:      base + "_providers" + ext,
+		Items:          base + "_items" + ext,
+		ContainedCodes: base + "_contained_codes" + ext,
+	}
+	if opts.Partition.Enabled() {
+		out = ConvertOutputs{
+			Rates:          base + "_rates",
+			Providers:      base + "_providers",
+			Items:          base + "_items",
+			ContainedCodes: base + "_contained_codes",
+		}
+	}
+	if opts.Summary {
+		out.Summary = base + "_summary.parquet"
+	}
+	if opts.Networks {
+		out.Networks = base + "_networks.parquet"
+	}
+	if opts.TOC != nil {
+		out.Plans = base + "_plans.parquet"
+	}
+	return out
+}
+
+// openInput opens path with a buffered reader, decompressing .gz files.
+// A path of "-" reads stdin, detecting gzip from the stream's magic bytes.
+// The returned close function releases the file.
+func openInput(path string, bufSize int) (io.Reader, func() error, error) {
+	if path == stdioPath {
+		return gunzipIfCompressed(bufio.NewReaderSize(os.Stdin, bufSize))
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open input: %w", err)
+	}
+	br := bufio.NewReaderSize(file, bufSize)
+	if !strings.HasSuffix(strings.ToLower(path), ".gz") {
+		return br, file.Close, nil
+	}
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("create gzip reader: %w", err)
+	}
+	return gz, func() error {
+		gz.Close()
+		return file.Close()
+	}, nil
+}
+
+// convertFile converts input to the Parquet outputs under base. On error
+// it removes any outputs it wrote, so they aren't mistaken for a good
+// result.
+func convertFile(input, base string, opts ConvertOptions) (res *ConvertResult, err error) {
+	if err := opts.validateFormat(); err != nil {
+		return nil, err
+	}
+	reader, closeInput, err := openInput(input, opts.BufferSize)
+	if err != nil {
+		return nil, err
+	}
+	defer closeInput()
+
+	out := outputPaths(base, opts)
+	part := opts.Partition
+
+	// Match the TOC before converting, so an ambiguous input fails fast.
+	var plans []PlanRow
+	if opts.TOC != nil {
+		if plans, err = opts.TOC.PlansFor(input, opts.SourceURL, filepath.Base(base)); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		rateWriter     RowWriter[RateRow]
+		providerWriter RowWriter[ProviderRow]
+		itemWriter     RowWriter[ItemRow]
+		codeWriter     RowWriter[ContainedCodeRow]
+		closed         int // writers already closed, in creation order
+	)
+	closeWriters := func() {
+		for _, w := range []interface{ Close() error }{rateWriter, providerWriter, itemWriter, codeWriter}[closed:] {
+			if w != nil {
+				w.Close()
+			}
+		}
+	}
+	defer func() {
+		if err != nil {
+			for _, p := range out.Paths() {
+				os.RemoveAll(p)
+			}
+		}
+	}()
+
+	switch {
+	case opts.isCSV():
+		rateWriter, err = asRowWriter[RateRow](NewRateCSVWriter(out.Rates))
+	case part.Enabled():
+		rateWriter, err = asRowWriter[RateRow](NewPartitionedRateWriter(out.Rates, part))
+	default:
+		rateWriter, err = asRowWriter[RateRow](NewRateParquetWriter(out.Rates))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create rate writer: %w", err)
+	}
+
+	switch {
+	case opts.isCSV():
+		providerWriter, err = asRowWriter[ProviderRow](NewProviderCSVWriter(out.Providers))
+	case part.Enabled():
+		providerWriter, err = asRowWriter[ProviderRow](NewPartitionedProviderWriter(out.Providers, part))
+	default:
+		providerWriter, err = asRowWriter[ProviderRow](NewProviderParquetWriter(out.Providers))
+	}
+	if err != nil {
+		closeWriters()
+		return nil, fmt.Errorf("create provider writer: %w", err)
+	}
+
+	switch {
+	case opts.isCSV():
+		itemWriter, err = asRowWriter[ItemRow](NewItemCSVWriter(out.Items))
+	case part.Enabled():
+		itemWriter, err = asRowWriter[ItemRow](NewPartitionedItemWriter(out.Items, part))
+	default:
+		itemWriter, err = asRowWriter[ItemRow](NewItemParquetWriter(out.Items))
+	}
+	if err != nil {
+		closeWriters()
+		return nil, fmt.Errorf("create item writer: %w", err)
+	}
+
+	switch {
+	case opts.isCSV():
+		codeWriter, err = asRowWriter[ContainedCodeRow](NewContainedCodeCSVWriter(out.ContainedCodes))
+	case part.Enabled():
+		codeWriter, err = asRowWriter[ContainedCodeRow](NewPartitionedContainedCodeWriter(out.ContainedCodes, part))
+	default:
+		codeWriter, err = asRowWriter[ContainedCodeRow](NewContainedCodeParquetWriter(out.ContainedCodes))
+	}
+	if err != nil {
+		closeWriters()
+		return nil, fmt.Errorf("create contained code writer: %w", err)
+	}
+
+	converter := NewStreamConverter(reader, opts.Verbose)
+	converter.SetGroupIndexLimit(opts.GroupIndexMem, opts.TmpDir)
+	converter.SetItemWriters(itemWriter, codeWriter)
+	converter.SetCodesJSON(opts.CodesJSON)
+	var summary *RateSummary
+	if opts.Summary {
+		summary = NewRateSummary()
+		converter.SetSummary(summary)
+	}
+	var networks *NetworkIndex
+	if opts.Networks {
+		networks = NewNetworkIndex()
+		converter.SetNetworks(networks)
+	}
+	if opts.NPIFilter != nil {
+		converter.SetNPIFilter(opts.NPIFilter)
+	}
+	if !opts.AsOf.IsZero() {
+		converter.SetAsOf(opts.AsOf)
+	}
+
+	stats, err := converter.Convert(rateWriter, providerWriter)
+	if err != nil {
+		closeWriters()
+		return nil, fmt.Errorf("convert: %w", err)
+	}
+
+	for i, w := range []struct {
+		name string
+		w    interface{ Close() error }
+	}{
+		{"rate", rateWriter},
+		{"provider", providerWriter},
+		{"item", itemWriter},
+		{"contained code", codeWriter},
+	} {
+		err := w.w.Close()
+		closed = i + 1
+		if err != nil {
+			closeWriters()
+			return nil, fmt.Errorf("close %s writer: %w", w.name, err)
+		}
+	}
+
+	if summary != nil {
+		n, err := summary.WriteParquet(out.Summary)
+		if err != nil {
+			return nil, err
+		}
+		stats.SummaryRows = n
+	}
+	if networks != nil {
+		parts, err := parquetParts(out.Providers)
+		if err != nil {
+			return nil, fmt.Errorf("list provider parts: %w", err)
+		}
+		n, err := networks.WriteParquet(out.Networks, parts)
+		if err != nil {
+			return nil, err
+		}
+		stats.NetworkRows = n
+	}
+	if opts.TOC != nil {
+		n, err := writePlans(out.Plans, plans)
+		if err != nil {
+			return nil, err
+		}
+		stats.PlanRows = n
+	}
+
+	res = &ConvertResult{
+		Stats:             stats,
+		Outputs:           out,
+		GroupIndexSpilled: converter.GroupIndexSpilled(),
+	}
+	if part.Enabled() {
+		res.RateParts = len(rateWriter.(*RateParquetWriter).Manifest().Parts)
+		res.ProviderParts = len(providerWriter.(*ProviderParquetWriter).Manifest().Parts)
+	}
+	return res, nil
+}
+
+// asRowWriter returns a writer constructor's result as a RowWriter, keeping
+// the nil pointer of a failed constructor out of the interface.
+func asRowWriter[T any, W RowWriter[T]](w W, err error) (RowWriter[T], error) {
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// writePlans writes the plan bridge rows to path.
+func writePlans(path string, plans []PlanRow) (int64, error) {
+	w, err := NewPlanParquetWriter(path)
+	if err != nil {
+		return 0, fmt.Errorf("create plan writer: %w", err)
+	}
+	for _, p := range plans {
+		if err := w.Write(p); err != nil {
+			w.Close()
+			return 0, fmt.Errorf("write plan row: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return 0, fmt.Errorf("close plan writer: %w", err)
+	}
+	return int64(len(plans)), nil
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/parquet-go/parquet-go"
 )
@@ -69,7 +74,7 @@ func readRateRows(t *testing.T, path string) []RateRow {
 		t.Fatalf("open parquet: %v", err)
 	}
 
-	reader := parquet.NewGenericReader[RateRow](pf)
+	reader := parquet.NewGenericReader[RateRow](pf, rateSchema)
 	defer reader.Close()
 
 	rows := make([]RateRow, reader.NumRows())
@@ -156,8 +161,8 @@ func TestFeeForServiceSinglePlan(t *testing.T) {
 	if r.Setting != "inpatient" {
 		t.Errorf("setting = %q, want inpatient", r.Setting)
 	}
-	if r.ExpirationDate != "2022-01-01" {
-		t.Errorf("expiration_date = %q, want 2022-01-01", r.ExpirationDate)
+	if exp, ok := r.Expiration(); !ok || exp.Format("2006-01-02") != "2022-01-01" || r.NoExpiration {
+		t.Errorf("expiration_date = %v (no_expiration %v), want 2022-01-01", exp, r.NoExpiration)
 	}
 
 	// Check provider_group_ids
@@ -482,3 +487,267 @@ func TestNoNPI(t *testing.T) {
 		t.Errorf("negotiated_rate = %v, want 123.45", r.NegotiatedRate)
 	}
 }
+
+// decodedFile holds a file's provider references and items.
+type decodedFile struct {
+	Refs  []ProviderReference
+	Items []InNetworkItem
+}
+
+// decodeWithEncodingJSON is the reflection-based reference decoder the
+// hand-written scanner replaced.
+func decodeWithEncodingJSON(data []byte) (decodedFile, error) {
+	var out decodedFile
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil {
+		return out, err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return out, err
+		}
+		var err2 error
+		switch t {
+		case "provider_references":
+			err2 = decodeArrayWithEncodingJSON(dec, &out.Refs)
+		case "in_network":
+			err2 = decodeArrayWithEncodingJSON(dec, &out.Items)
+		default:
+			var skip json.RawMessage
+			err2 = dec.Decode(&skip)
+		}
+		if err2 != nil {
+			return out, err2
+		}
+	}
+	return out, nil
+}
+
+func decodeArrayWithEncodingJSON[T any](dec *json.Decoder, out *[]T) error {
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	for dec.More() {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		*out = append(*out, v)
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func decodeWithScanner(data []byte) (decodedFile, error) {
+	return decodeReaderWithScanner(bytes.NewReader(data))
+}
+
+func decodeReaderWithScanner(r io.Reader) (decodedFile, error) {
+	var out decodedFile
+	s := newJSONScanner(r)
+	err := s.Object(func(key []byte) error {
+		switch string(key) {
+		case "provider_references":
+			_, err := s.Array(func() error {
+				out.Refs = append(out.Refs, ProviderReference{})
+				return decodeProviderReference(s, &out.Refs[len(out.Refs)-1])
+			})
+			return err
+		case "in_network":
+			_, err := s.Array(func() error {
+				out.Items = append(out.Items, InNetworkItem{})
+				return decodeInNetworkItem(s, &out.Items[len(out.Items)-1])
+			})
+			return err
+		}
+		return s.Skip()
+	})
+	return out, err
+}
+
+// scannerEdgeCases exercises the encoding/json behaviours the scanner
+// mirrors: escapes, surrogate pairs, invalid UTF-8, case-insensitive keys,
+// nulls, empty arrays and unknown fields.
+var scannerEdgeCases = []string{
+	`{"in_network": [{"name": "a\"b\\c\/d\n\té😀 \ud800x", "Billing_Code": "X1",
+	  "description": "caf` + "\xe9" + ` \u0000", "unknown": {"deep": [1, {"x": null}, "s"]}, "negotiated_rates": []}]}`,
+	`{"in_network": [{"negotiated_rates": [{"provider_references": null, "negotiated_prices": [null,
+	  {"negotiated_rate": -1.5e-3, "service_code": [], "billing_code_modifier": null, "setting": null}]}],
+	  "bundled_codes": null, "covered_services": [{}]}]}`,
+	`{"provider_references": [{"provider_group_id": 7, "network_name": [],
+	  "provider_groups": [{"npi": [0, 1234567890], "tin": {"type": "ein", "value": "1", "BUSINESS_NAME": "Acme"}}, {"npi": null}]}],
+	 "in_network": []}`,
+}
+
+func TestDecodeMatchesEncodingJSON(t *testing.T) {
+	inputs := map[string][]byte{}
+	paths, _ := filepath.Glob(filepath.Join(examplesDir, "*.json"))
+	paths = append(paths, allFieldsSample)
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[filepath.Base(p)] = data
+	}
+	for i, s := range scannerEdgeCases {
+		inputs[fmt.Sprintf("edge case %d", i)] = []byte(s)
+	}
+
+	for name, data := range inputs {
+		want, err := decodeWithEncodingJSON(data)
+		if err != nil {
+			t.Fatalf("%s: encoding/json: %v", name, err)
+		}
+		forEachSplitReader(data, func(reader string, r io.Reader) bool {
+			got, err := decodeReaderWithScanner(r)
+			if err != nil {
+				t.Errorf("%s, %s: scanner: %v", name, reader, err)
+				return false
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s, %s: scanner decoded\n%+v\nencoding/json decoded\n%+v", name, reader, got, want)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+// forEachSplitReader calls fn with readers over data that deliver it
+// whole, a byte at a time, half a read at a time, and in two reads split
+// at every offset, so the scanner refills its buffer at every position.
+// It stops at the first reader fn returns false for.
+func forEachSplitReader(data []byte, fn func(name string, r io.Reader) bool) {
+	if !fn("bytes.Reader", bytes.NewReader(data)) ||
+		!fn("OneByteReader", iotest.OneByteReader(bytes.NewReader(data))) ||
+		!fn("HalfReader", iotest.HalfReader(bytes.NewReader(data))) {
+		return
+	}
+	for i := 1; i < len(data); i++ {
+		r := io.MultiReader(bytes.NewReader(data[:i]), bytes.NewReader(data[i:]))
+		if !fn(fmt.Sprintf("split at %d", i), r) {
+			return
+		}
+	}
+}
+
+func TestDecodeRejectsInvalidJSON(t *testing.T) {
+	for _, in := range []string{
+		`{"in_network": [{"name": "unterminated}]}`,
+		`{"in_network": [{"negotiated_rates": [{"negotiated_prices": [{"negotiated_rate": 01}]}]}]}`,
+		`{"in_network": [{"negotiated_rates": [{"negotiated_prices": [{"negotiated_rate": "12"}]}]}]}`,
+		`{"provider_references": [{"provider_group_id": 1.5}]}`,
+		`{"in_network": [{"name": "bad \x escape"}]}`,
+		`{"in_network": [{"name" "missing colon"}]}`,
+		`{"in_network": [{"name": "a",}]}`,
+	} {
+		if _, err := decodeWithScanner([]byte(in)); err == nil {
+			t.Errorf("scanner accepted %s", in)
+		}
+		if _, err := decodeWithEncodingJSON([]byte(in)); err == nil {
+			t.Errorf("encoding/json accepted %s", in)
+		}
+	}
+}
+
+func TestConvertFileRemovesPartialOutputs(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(input, []byte(`{"in_network": [{"name": "unterminated}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(dir, "broken")
+	opts := ConvertOptions{BufferSize: 1 << 16, Summary: true}
+	if _, err := convertFile(input, base, opts); err == nil {
+		t.Fatal("convertFile accepted invalid JSON")
+	}
+	for _, p := range outputPaths(base, opts).Paths() {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s left behind: %v", filepath.Base(p), err)
+		}
+	}
+}
+
+// syntheticInNetwork builds an in-network file with n items shaped like
+// production files: referenced provider groups and several prices each.
+func syntheticInNetwork(n int) []byte {
+	var b bytes.Buffer
+	b.WriteString(`{"reporting_entity_name": "Bench Health", "reporting_entity_type": "health insurance issuer",
+  "plan_name": "Bench PPO", "plan_id_type": "hios", "plan_id": "12345NY0010001", "plan_market_type": "individual",
+  "issuer_name": "Bench Health", "last_updated_on": "2024-01-01", "version": "1.0.0",
+  "provider_references": [`)
+	for g := 1; g <= 100; g++ {
+		if g > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `{"provider_group_id": %d, "network_name": ["Bench Network"], "provider_groups": [{"npi": [%d, %d], "tin": {"type": "ein", "value": "12-%07d", "business_name": "Group %d"}}]}`,
+			g, 1000000000+g, 1500000000+g, g, g)
+	}
+	b.WriteString("],\n  \"in_network\": [")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `
+    {"negotiation_arrangement": "ffs", "name": "Procedure %d", "billing_code_type": "CPT", "billing_code_type_version": "2024",
+     "billing_code": "%05d", "description": "Synthetic procedure description number %d",
+     "negotiated_rates": [`, i, 10000+i%90000, i)
+		for r := 0; r < 4; r++ {
+			if r > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, `{"provider_references": [%d, %d, %d], "negotiated_prices": [
+        {"negotiated_type": "negotiated", "negotiated_rate": %d.%02d, "expiration_date": "9999-12-31", "service_code": ["11", "22"], "billing_class": "professional", "setting": "outpatient", "billing_code_modifier": ["26"]},
+        {"negotiated_type": "fee schedule", "negotiated_rate": %d.50, "expiration_date": "2025-12-31", "billing_class": "institutional", "setting": "both"}]}`,
+				1+(i+r)%100, 1+(i+r+1)%100, 1+(i+r+2)%100, 50+i%500, r, 60+i%400)
+		}
+		b.WriteString("]}")
+	}
+	b.WriteString("]}\n")
+	return b.Bytes()
+}
+
+func BenchmarkDecodeInNetwork(b *testing.B) {
+	data := syntheticInNetwork(2000)
+	for _, bc := range []struct {
+		name   string
+		decode func([]byte) (decodedFile, error)
+	}{
+		{"encoding_json", decodeWithEncodingJSON},
+		{"scanner", decodeWithScanner},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := bc.decode(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkConvert(b *testing.B) {
+	data := syntheticInNetwork(2000)
+	dir := b.TempDir()
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rw, err := NewRateParquetWriter(filepath.Join(dir, "rates.parquet"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		pw, err := NewProviderParquetWriter(filepath.Join(dir, "providers.parquet"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := NewStreamConverter(bytes.NewReader(data), false).Convert(rw, pw); err != nil {
+			b.Fatal(err)
+		}
+		rw.Close()
+		pw.Close()
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Output formats for ConvertOptions.Format.
+const (
+	FormatParquet = "parquet"
+	FormatCSV     = "csv"
+	FormatCSVGzip = "csv.gz"
+)
+
+// csvListSep joins the elements of list columns (service_code,
+// billing_code_modifier, provider_group_ids, network_names) within one CSV
+// field. Codes never contain it, but free-text elements such as network
+// names can, so csvList escapes '|' and '\' in elements with a backslash:
+// a field splits back on every '|' not preceded by an escaping '\'.
+const csvListSep = "|"
+
+// csvListEscaper escapes csvListSep and the escape character itself.
+var csvListEscaper = strings.NewReplacer(`\`, `\\`, csvListSep, `\`+csvListSep)
+
+// CSVWriter writes rows of one type to a CSV file with a header row named
+// like the Parquet columns. Fields are quoted per RFC 4180 as needed; NULL
+// is an empty field, list columns are joined by csvList and dates are
+// YYYY-MM-DD. Paths ending in .gz are gzip-compressed.
+type CSVWriter[T any] struct {
+	file   *os.File
+	buf    *bufio.Writer
+	gz     *gzip.Writer
+	w      *csv.Writer
+	record func(row *T, rec []string) []string
+	rec    []string
+	count  int
+}
+
+func newCSVWriter[T any](label, path string, header []string, record func(*T, []string) []string) (*CSVWriter[T], error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create %s CSV file: %w", label, err)
+	}
+	w := &CSVWriter[T]{file: f, buf: bufio.NewWriterSize(f, 1<<20), record: record}
+	if strings.HasSuffix(path, ".gz") {
+		w.gz = gzip.NewWriter(w.buf)
+		w.w = csv.NewWriter(w.gz)
+	} else {
+		w.w = csv.NewWriter(w.buf)
+	}
+	if err := w.w.Write(header); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("write %s CSV header: %w", label, err)
+	}
+	return w, nil
+}
+
+// Write writes a single row.
+func (w *CSVWriter[T]) Write(row T) error {
+	w.rec = w.record(&row, w.rec[:0])
+	if err := w.w.Write(w.rec); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Close flushes and closes the file.
+func (w *CSVWriter[T]) Close() error {
+	w.w.Flush()
+	err := w.w.Error()
+	if w.gz != nil {
+		if cerr := w.gz.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if ferr := w.buf.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Count returns the number of rows written.
+func (w *CSVWriter[T]) Count() int { return w.count }
+
+// NewRateCSVWriter creates a CSV writer for rate rows.
+func NewRateCSVWriter(path string) (*CSVWriter[RateRow], error) {
+	return newCSVWriter("rate", path, rateCSVHeader, rateCSVRecord)
+}
+
+// NewProviderCSVWriter creates a CSV writer for provider rows.
+func NewProviderCSVWriter(path string) (*CSVWriter[ProviderRow], error) {
+	return newCSVWriter("provider", path, providerCSVHeader, providerCSVRecord)
+}
+
+// NewItemCSVWriter creates a CSV writer for item rows.
+func NewItemCSVWriter(path string) (*CSVWriter[ItemRow], error) {
+	return newCSVWriter("item", path, itemCSVHeader, itemCSVRecord)
+}
+
+// NewContainedCodeCSVWriter creates a CSV writer for contained code rows.
+func NewContainedCodeCSVWriter(path string) (*CSVWriter[ContainedCodeRow], error) {
+	return newCSVWriter("contained code", path, containedCodeCSVHeader, containedCodeCSVRecord)
+}
+
+// The headers list the Parquet column names in struct order;
+// TestCSVHeadersMatchParquet keeps them in step with parquet_types.go.
+
+var rateCSVHeader = []string{
+	"reporting_entity_name", "reporting_entity_type", "plan_name", "issuer_name",
+	"plan_sponsor_name", "plan_id_type", "plan_id", "plan_market_type",
+	"last_updated_on", "version", "item_id", "negotiation_arrangement", "name",
+	"billing_code_type", "billing_code_type_version", "severity_of_illness",
+	"billing_code", "description", "negotiated_rate", "negotiated_type", "rate_unit",
+	"billing_class", "setting", "expiration_date", "no_expiration",
+	"expiration_date_raw", "service_code", "billing_code_modifier",
+	"additional_information", "provider_group_ids", "bundled_codes_json",
+	"covered_services_json",
+}
+
+func rateCSVRecord(r *RateRow, rec []string) []string {
+	return append(rec,
+		r.ReportingEntityName,
+		r.ReportingEntityType,
+		optString(r.PlanName),
+		optString(r.IssuerName),
+		optString(r.PlanSponsorName),
+		optString(r.PlanIDType),
+		optString(r.PlanID),
+		optString(r.PlanMarketType),
+		r.LastUpdatedOn,
+		r.Version,
+		strconv.FormatInt(r.ItemID, 10),
+		r.NegotiationArrangement,
+		r.Name,
+		r.BillingCodeType,
+		r.BillingCodeTypeVersion,
+		optString(r.SeverityOfIllness),
+		r.BillingCode,
+		r.Description,
+		strconv.FormatFloat(r.NegotiatedRate, 'f', -1, 64),
+		r.NegotiatedType,
+		r.RateUnit,
+		r.BillingClass,
+		r.Setting,
+		csvDate(r),
+		strconv.FormatBool(r.NoExpiration),
+		optString(r.ExpirationDateRaw),
+		csvList(r.ServiceCode),
+		csvList(r.BillingCodeModifier),
+		optString(r.AdditionalInformation),
+		csvInt32s(r.ProviderGroupIDs),
+		optString(r.BundledCodesJSON),
+		optString(r.CoveredServicesJSON),
+	)
+}
+
+var providerCSVHeader = []string{
+	"provider_group_id", "npi", "tin_type", "tin_value", "business_name", "network_names",
+}
+
+func providerCSVRecord(r *ProviderRow, rec []string) []string {
+	return append(rec,
+		strconv.FormatInt(int64(r.ProviderGroupID), 10),
+		strconv.FormatInt(r.NPI, 10),
+		r.TINType,
+		r.TINValue,
+		optString(r.BusinessName),
+		csvList(r.NetworkNames),
+	)
+}
+
+var itemCSVHeader = []string{
+	"item_id", "negotiation_arrangement", "name", "billing_code_type",
+	"billing_code_type_version", "severity_of_illness", "billing_code",
+	"description", "bundled_code_count", "covered_service_count",
+}
+
+func itemCSVRecord(r *ItemRow, rec []string) []string {
+	return append(rec,
+		strconv.FormatInt(r.ItemID, 10),
+		r.NegotiationArrangement,
+		r.Name,
+		r.BillingCodeType,
+		r.BillingCodeTypeVersion,
+		optString(r.SeverityOfIllness),
+		r.BillingCode,
+		r.Description,
+		strconv.FormatInt(int64(r.BundledCodeCount), 10),
+		strconv.FormatInt(int64(r.CoveredServiceCount), 10),
+	)
+}
+
+var containedCodeCSVHeader = []string{
+	"item_id", "relation", "billing_code_type", "billing_code_type_version",
+	"billing_code", "description",
+}
+
+func containedCodeCSVRecord(r *ContainedCodeRow, rec []string) []string {
+	return append(rec,
+		strconv.FormatInt(r.ItemID, 10),
+		r.Relation,
+		r.BillingCodeType,
+		r.BillingCodeTypeVersion,
+		r.BillingCode,
+		r.Description,
+	)
+}
+
+// csvDate formats the expiration date, or "" when it is NULL.
+func csvDate(r *RateRow) string {
+	t, ok := r.Expiration()
+	if !ok {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// csvList joins v with csvListSep, escaping each element.
+func csvList(v []string) string {
+	var b strings.Builder
+	for i, s := range v {
+		if i > 0 {
+			b.WriteString(csvListSep)
+		}
+		csvListEscaper.WriteString(&b, s)
+	}
+	return b.String()
+}
+
+func csvInt32s(v []int32) string {
+	var b strings.Builder
+	for i, n := range v {
+		if i > 0 {
+			b.WriteString(csvListSep)
+		}
+		b.WriteString(strconv.FormatInt(int64(n), 10))
+	}
+	return b.String()
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestCSVHeadersMatchParquet(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		schema *parquet.Schema
+		header []string
+	}{
+		{"rate", parquet.SchemaOf(RateRow{}), rateCSVHeader},
+		{"provider", parquet.SchemaOf(ProviderRow{}), providerCSVHeader},
+		{"item", parquet.SchemaOf(ItemRow{}), itemCSVHeader},
+		{"contained code", parquet.SchemaOf(ContainedCodeRow{}), containedCodeCSVHeader},
+	} {
+		var want []string
+		for _, f := range tc.schema.Fields() {
+			want = append(want, f.Name())
+		}
+		if !reflect.DeepEqual(tc.header, want) {
+			t.Errorf("%s CSV header = %v, want %v", tc.name, tc.header, want)
+		}
+	}
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(gz).ReadAll()
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return records
+}
+
+// TestConvertCSV converts the all-fields fixture to Parquet and gzipped
+// CSV and checks every CSV field against the Parquet row it came from.
+func TestConvertCSV(t *testing.T) {
+	input := filepath.Join("testdata", "in-network-all-fields.json")
+	dir := t.TempDir()
+	pq, err := convertFile(input, filepath.Join(dir, "pq"), ConvertOptions{BufferSize: 1 << 16, CodesJSON: true})
+	if err != nil {
+		t.Fatalf("convert parquet: %v", err)
+	}
+	res, err := convertFile(input, filepath.Join(dir, "csv"), ConvertOptions{BufferSize: 1 << 16, CodesJSON: true, Format: FormatCSVGzip})
+	if err != nil {
+		t.Fatalf("convert csv: %v", err)
+	}
+	if filepath.Base(res.Outputs.Rates) != "csv_rates.csv.gz" {
+		t.Errorf("rates output = %s", res.Outputs.Rates)
+	}
+
+	rates := readCSV(t, res.Outputs.Rates)
+	if !reflect.DeepEqual(rates[0], rateCSVHeader) {
+		t.Errorf("rates header = %v", rates[0])
+	}
+	pqRates := readRateRows(t, pq.Outputs.Rates)
+	if len(rates)-1 != len(pqRates) || int64(len(pqRates)) != res.Stats.RateRows {
+		t.Fatalf("%d CSV rate rows, %d Parquet, stats %d", len(rates)-1, len(pqRates), res.Stats.RateRows)
+	}
+	var sawList, sawDate bool
+	for i := range pqRates {
+		want := rateCSVRecord(&pqRates[i], nil)
+		if !reflect.DeepEqual(rates[i+1], want) {
+			t.Errorf("rate row %d = %q, want %q", i, rates[i+1], want)
+		}
+		if len(pqRates[i].ServiceCode) > 1 || len(pqRates[i].ProviderGroupIDs) > 1 {
+			sawList = true
+		}
+		if pqRates[i].ExpirationDate != nil {
+			sawDate = true
+		}
+	}
+	if !sawList || !sawDate {
+		t.Errorf("fixture does not exercise list columns (%v) or dates (%v)", sawList, sawDate)
+	}
+
+	providers := readCSV(t, res.Outputs.Providers)
+	pqProviders := readProviderRows(t, pq.Outputs.Providers)
+	if len(providers)-1 != len(pqProviders) {
+		t.Fatalf("%d CSV provider rows, %d Parquet", len(providers)-1, len(pqProviders))
+	}
+	for i := range pqProviders {
+		if want := providerCSVRecord(&pqProviders[i], nil); !reflect.DeepEqual(providers[i+1], want) {
+			t.Errorf("provider row %d = %q, want %q", i, providers[i+1], want)
+		}
+	}
+	if items := readCSV(t, res.Outputs.Items); int64(len(items)-1) != res.Stats.ItemRows {
+		t.Errorf("%d CSV item rows, want %d", len(items)-1, res.Stats.ItemRows)
+	}
+}
+
+func TestCSVWriterQuoting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "p.csv")
+	w, err := NewProviderCSVWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "Smith, \"Jones\"\nand Co"
+	row := ProviderRow{ProviderGroupID: 7, NPI: 1234567890, TINType: "ein", TINValue: "12-3456789",
+		BusinessName: &name, NetworkNames: []string{"Gold", "Silver, PPO", `Tier 1|2 \ EPO`}}
+	if err := w.Write(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r := csv.NewReader(f)
+	if _, err := r.Read(); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"7", "1234567890", "ein", "12-3456789", name, `Gold|Silver, PPO|Tier 1\|2 \\ EPO`}
+	if !reflect.DeepEqual(rec, want) {
+		t.Errorf("record = %q, want %q", rec, want)
+	}
+	if got := splitCSVList(rec[5]); !reflect.DeepEqual(got, row.NetworkNames) {
+		t.Errorf("network_names split back to %q, want %q", got, row.NetworkNames)
+	}
+	if _, err := r.Read(); err != io.EOF {
+		t.Errorf("expected one row, got err %v", err)
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	if err := (ConvertOptions{Format: "xlsx"}).validateFormat(); err == nil {
+		t.Error("unknown format accepted")
+	}
+	if err := (ConvertOptions{Format: FormatCSV, Partition: PartitionOptions{MaxRows: 10}}).validateFormat(); err == nil {
+		t.Error("partitioned CSV accepted")
+	}
+	if err := (ConvertOptions{Format: FormatCSV, Networks: true}).validateFormat(); err == nil {
+		t.Error("CSV with networks accepted")
+	}
+	if err := (ConvertOptions{Format: FormatCSVGzip, Summary: true}).validateFormat(); err != nil {
+		t.Errorf("CSV with summary rejected: %v", err)
+	}
+}
+
+// splitCSVList reverses csvList.
+func splitCSVList(field string) []string {
+	var out []string
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		switch c := field[i]; {
+		case c == '\\' && i+1 < len(field):
+			i++
+			b.WriteByte(field[i])
+		case c == csvListSep[0]:
+			out = append(out, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(out, b.String())
+}
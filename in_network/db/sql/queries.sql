@@ -0,0 +1,83 @@
+-- name: UpsertReportingEntity :one
+INSERT INTO reporting_entities (name, entity_type)
+VALUES ($1, $2)
+ON CONFLICT (name, entity_type) DO UPDATE SET name = EXCLUDED.name
+RETURNING id;
+
+-- name: UpsertPayerPlan :one
+INSERT INTO payer_plans
+  (reporting_entity_id, plan_name, issuer_name, plan_sponsor_name, plan_id_type, plan_id, plan_market_type)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (reporting_entity_id, plan_name, issuer_name, plan_sponsor_name, plan_id_type, plan_id, plan_market_type)
+  DO UPDATE SET plan_name = EXCLUDED.plan_name
+RETURNING id;
+
+-- name: InsertInNetworkFile :one
+INSERT INTO in_network_files (reporting_entity_id, payer_plan_id, last_updated_on, version, source)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id;
+
+-- name: InsertInNetworkItems :copyfrom
+INSERT INTO in_network_items
+  (file_id, item_id, negotiation_arrangement, name, billing_code_type,
+   billing_code_type_version, billing_code, description, bundled_codes, covered_services,
+   severity_of_illness)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: InsertProviderGroups :copyfrom
+INSERT INTO provider_groups (file_id, provider_group_id, network_names)
+VALUES ($1, $2, $3);
+
+-- name: InsertProviderGroupNPIs :copyfrom
+INSERT INTO provider_group_npis
+  (file_id, provider_group_id, npi, tin_type, tin_value, business_name)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: InsertNegotiatedPrices :copyfrom
+INSERT INTO negotiated_prices
+  (file_id, item_id, negotiated_type, negotiated_rate, expiration_date,
+   billing_class, setting, service_code, billing_code_modifier,
+   additional_information, provider_group_ids)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- Read queries (used by tests)
+
+-- name: GetFirstInNetworkFile :one
+SELECT re.name AS reporting_entity_name, re.entity_type, p.plan_name, p.plan_id,
+       f.last_updated_on, f.version
+FROM in_network_files f
+JOIN reporting_entities re ON re.id = f.reporting_entity_id
+LEFT JOIN payer_plans p ON p.id = f.payer_plan_id
+ORDER BY f.id
+LIMIT 1;
+
+-- name: CountInNetworkFiles :one
+SELECT count(*)::int FROM in_network_files;
+
+-- name: CountInNetworkItems :one
+SELECT count(*)::int FROM in_network_items;
+
+-- name: CountNegotiatedPrices :one
+SELECT count(*)::int FROM negotiated_prices;
+
+-- name: CountProviderGroups :one
+SELECT count(*)::int FROM provider_groups;
+
+-- name: CountProviderGroupNPIs :one
+SELECT count(*)::int FROM provider_group_npis;
+
+-- name: ListNegotiatedPriceDetails :many
+SELECT i.billing_code, np.negotiated_type, np.negotiated_rate, np.billing_class,
+       np.expiration_date, np.provider_group_ids
+FROM negotiated_prices np
+JOIN in_network_items i ON i.file_id = np.file_id AND i.item_id = np.item_id
+ORDER BY np.id;
+
+-- name: ListNPIsForBillingCode :many
+SELECT DISTINCT n.npi
+FROM negotiated_prices np
+JOIN in_network_items i ON i.file_id = np.file_id AND i.item_id = np.item_id
+JOIN provider_group_npis n
+  ON n.file_id = np.file_id AND n.provider_group_id = ANY(np.provider_group_ids)
+WHERE i.billing_code = $1
+ORDER BY n.npi;
@@ -0,0 +1,202 @@
+package main
+
+// Hand-written decoders for the in-network types, used by StreamConverter
+// in place of encoding/json reflection. They must stay in step with the
+// json tags in types.go; TestDecodeMatchesEncodingJSON checks both agree.
+
+func decodeProviderReference(s *jsonScanner, ref *ProviderReference) error {
+	return s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "provider_group_id":
+			var v int64
+			v, err = s.Int64()
+			ref.ProviderGroupID = int(v)
+		case "network_name":
+			ref.NetworkName, err = s.Strings()
+		case "provider_groups":
+			ref.ProviderGroups, err = decodeProviderGroups(s)
+		default:
+			err = s.Skip()
+		}
+		return err
+	})
+}
+
+func decodeProviderGroups(s *jsonScanner) ([]ProviderGroup, error) {
+	out := []ProviderGroup{}
+	isArray, err := s.Array(func() error {
+		out = append(out, ProviderGroup{})
+		return decodeProviderGroup(s, &out[len(out)-1])
+	})
+	if !isArray {
+		return nil, err
+	}
+	return out, err
+}
+
+func decodeProviderGroup(s *jsonScanner, pg *ProviderGroup) error {
+	return s.Object(func(key []byte) error {
+		switch string(key) {
+		case "npi":
+			npis := []int64{}
+			isArray, err := s.Array(func() error {
+				v, err := s.Int64()
+				npis = append(npis, v)
+				return err
+			})
+			if !isArray {
+				npis = nil
+			}
+			pg.NPI = npis
+			return err
+		case "tin":
+			return s.Object(func(key []byte) error {
+				var err error
+				switch string(key) {
+				case "type":
+					pg.TIN.Type, err = s.Interned()
+				case "value":
+					pg.TIN.Value, err = s.String()
+				case "business_name":
+					pg.TIN.BusinessName, err = s.String()
+				default:
+					err = s.Skip()
+				}
+				return err
+			})
+		}
+		return s.Skip()
+	})
+}
+
+func decodeInNetworkItem(s *jsonScanner, item *InNetworkItem) error {
+	return s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "negotiation_arrangement":
+			item.NegotiationArrangement, err = s.Interned()
+		case "name":
+			item.Name, err = s.String()
+		case "billing_code_type":
+			item.BillingCodeType, err = s.Interned()
+		case "billing_code_type_version":
+			item.BillingCodeTypeVersion, err = s.Interned()
+		case "severity_of_illness":
+			item.SeverityOfIllness, err = s.Interned()
+		case "billing_code":
+			item.BillingCode, err = s.String()
+		case "description":
+			item.Description, err = s.String()
+		case "negotiated_rates":
+			item.NegotiatedRates, err = decodeNegotiatedRates(s)
+		case "bundled_codes":
+			item.BundledCodes, err = decodeContainedCodes(s)
+		case "covered_services":
+			item.CoveredServices, err = decodeContainedCodes(s)
+		default:
+			err = s.Skip()
+		}
+		return err
+	})
+}
+
+func decodeNegotiatedRates(s *jsonScanner) ([]NegotiatedRate, error) {
+	out := []NegotiatedRate{}
+	isArray, err := s.Array(func() error {
+		out = append(out, NegotiatedRate{})
+		nr := &out[len(out)-1]
+		return s.Object(func(key []byte) error {
+			var err error
+			switch string(key) {
+			case "provider_references":
+				refs := []int{}
+				var isArray bool
+				isArray, err = s.Array(func() error {
+					v, err := s.Int64()
+					refs = append(refs, int(v))
+					return err
+				})
+				if !isArray {
+					refs = nil
+				}
+				nr.ProviderReferences = refs
+			case "provider_groups":
+				nr.ProviderGroups, err = decodeProviderGroups(s)
+			case "negotiated_prices":
+				nr.NegotiatedPrices, err = decodeNegotiatedPrices(s)
+			default:
+				err = s.Skip()
+			}
+			return err
+		})
+	})
+	if !isArray {
+		return nil, err
+	}
+	return out, err
+}
+
+func decodeNegotiatedPrices(s *jsonScanner) ([]NegotiatedPrice, error) {
+	out := []NegotiatedPrice{}
+	isArray, err := s.Array(func() error {
+		out = append(out, NegotiatedPrice{})
+		p := &out[len(out)-1]
+		return s.Object(func(key []byte) error {
+			var err error
+			switch string(key) {
+			case "negotiated_type":
+				p.NegotiatedType, err = s.Interned()
+			case "negotiated_rate":
+				p.NegotiatedRate, err = s.Float64()
+			case "billing_class":
+				p.BillingClass, err = s.Interned()
+			case "setting":
+				p.Setting, err = s.Interned()
+			case "expiration_date":
+				p.ExpirationDate, err = s.Interned()
+			case "service_code":
+				p.ServiceCode, err = s.Strings()
+			case "billing_code_modifier":
+				p.BillingCodeModifier, err = s.Strings()
+			case "additional_information":
+				p.AdditionalInformation, err = s.String()
+			default:
+				err = s.Skip()
+			}
+			return err
+		})
+	})
+	if !isArray {
+		return nil, err
+	}
+	return out, err
+}
+
+func decodeContainedCodes(s *jsonScanner) ([]ContainedCode, error) {
+	out := []ContainedCode{}
+	isArray, err := s.Array(func() error {
+		out = append(out, ContainedCode{})
+		cc := &out[len(out)-1]
+		return s.Object(func(key []byte) error {
+			var err error
+			switch string(key) {
+			case "billing_code_type":
+				cc.BillingCodeType, err = s.Interned()
+			case "billing_code_type_version":
+				cc.BillingCodeTypeVersion, err = s.Interned()
+			case "billing_code":
+				cc.BillingCode, err = s.String()
+			case "description":
+				cc.Description, err = s.String()
+			default:
+				err = s.Skip()
+			}
+			return err
+		})
+	})
+	if !isArray {
+		return nil, err
+	}
+	return out, err
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// expirationLayouts are the expiration_date spellings accepted. The schema
+// requires YYYY-MM-DD, but US-style and timestamp forms appear in the wild.
+var expirationLayouts = []string{
+	"2006-01-02",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"01/02/2006",
+	"2006/01/02",
+}
+
+// noExpirationYear marks the "no expiration" sentinel (9999-12-31 and
+// friends); such dates never expire.
+const noExpirationYear = 9999
+
+// parseExpirationDate parses a negotiated price expiration_date. It
+// reports ok=false for empty or unparseable values and noExpiration for
+// the 9999 sentinel, in which case the date is zero.
+func parseExpirationDate(s string) (date time.Time, noExpiration, ok bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false, false
+	}
+	for _, layout := range expirationLayouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		if t.Year() >= noExpirationYear {
+			return time.Time{}, true, true
+		}
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), false, true
+	}
+	return time.Time{}, false, false
+}
+
+// parseAsOf parses an -as-of date (YYYY-MM-DD).
+func parseAsOf(s string) (time.Time, error) {
+	return time.Parse("2006-01-02", strings.TrimSpace(s))
+}
+
+// epochDays converts a date to the days since 1970-01-01 that DATE
+// columns hold.
+func epochDays(t time.Time) int32 {
+	return int32(t.Unix() / 86400)
+}
+
+// Expiration returns the price's expiration date, or false when it is
+// NULL (never expires or unparseable).
+func (r *RateRow) Expiration() (time.Time, bool) {
+	if r.ExpirationDate == nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(*r.ExpirationDate)*86400, 0).UTC(), true
+}
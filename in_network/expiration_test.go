@@ -0,0 +1,165 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseExpirationDate(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		noExp  bool
+		wantOK bool
+	}{
+		{"2022-01-01", "2022-01-01", false, true},
+		{" 2024-06-30 ", "2024-06-30", false, true},
+		{"06/30/2024", "2024-06-30", false, true},
+		{"2024/06/30", "2024-06-30", false, true},
+		{"2024-06-30T12:00:00Z", "2024-06-30", false, true},
+		{"9999-12-31", "", true, true},
+		{"12/31/9999", "", true, true},
+		{"", "", false, false},
+		{"N/A", "", false, false},
+		{"2024-13-01", "", false, false},
+	}
+	for _, tt := range tests {
+		date, noExp, ok := parseExpirationDate(tt.in)
+		if ok != tt.wantOK || noExp != tt.noExp {
+			t.Errorf("parseExpirationDate(%q) noExpiration=%v ok=%v, want %v %v", tt.in, noExp, ok, tt.noExp, tt.wantOK)
+			continue
+		}
+		if tt.want != "" && date.Format("2006-01-02") != tt.want {
+			t.Errorf("parseExpirationDate(%q) = %s, want %s", tt.in, date.Format("2006-01-02"), tt.want)
+		}
+	}
+}
+
+const expirationSample = `{
+  "reporting_entity_name": "Test", "reporting_entity_type": "health insurance issuer",
+  "last_updated_on": "2024-01-01", "version": "1.0.0",
+  "provider_references": [{"provider_group_id": 1, "provider_groups": [{"npi": [1234567890], "tin": {"type": "ein", "value": "11-1111111"}}]}],
+  "in_network": [
+    {"negotiation_arrangement": "ffs", "name": "Old", "billing_code_type": "CPT", "billing_code_type_version": "2024",
+     "billing_code": "99201", "description": "Expired",
+     "negotiated_rates": [{"provider_references": [1], "negotiated_prices": [
+       {"negotiated_type": "negotiated", "negotiated_rate": 10, "expiration_date": "2022-01-01", "billing_class": "professional"}]}]},
+    {"negotiation_arrangement": "ffs", "name": "Mixed", "billing_code_type": "CPT", "billing_code_type_version": "2024",
+     "billing_code": "99213", "description": "Mixed",
+     "negotiated_rates": [{"provider_references": [1], "negotiated_prices": [
+       {"negotiated_type": "negotiated", "negotiated_rate": 20, "expiration_date": "2023-06-30", "billing_class": "professional"},
+       {"negotiated_type": "negotiated", "negotiated_rate": 30, "expiration_date": "12/31/2025", "billing_class": "professional"},
+       {"negotiated_type": "negotiated", "negotiated_rate": 40, "expiration_date": "9999-12-31", "billing_class": "professional"},
+       {"negotiated_type": "negotiated", "negotiated_rate": 50, "expiration_date": "ongoing", "billing_class": "professional"},
+       {"negotiated_type": "negotiated", "negotiated_rate": 60, "expiration_date": "", "billing_class": "professional"},
+       {"negotiated_type": "negotiated", "negotiated_rate": 70, "billing_class": "professional"}]}]}
+  ]
+}`
+
+func convertExpirationSample(t *testing.T, asOf time.Time) ([]RateRow, *ConvertStats) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	ratesPath := filepath.Join(tmpDir, "rates.parquet")
+	rw, err := NewRateParquetWriter(ratesPath)
+	if err != nil {
+		t.Fatalf("rate writer: %v", err)
+	}
+	pw, err := NewProviderParquetWriter(filepath.Join(tmpDir, "providers.parquet"))
+	if err != nil {
+		rw.Close()
+		t.Fatalf("provider writer: %v", err)
+	}
+
+	converter := NewStreamConverter(strings.NewReader(expirationSample), false)
+	if !asOf.IsZero() {
+		converter.SetAsOf(asOf)
+	}
+	stats, err := converter.Convert(rw, pw)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	rw.Close()
+	pw.Close()
+	return readRateRows(t, ratesPath), stats
+}
+
+func TestExpirationColumns(t *testing.T) {
+	rates, stats := convertExpirationSample(t, time.Time{})
+	if len(rates) != 7 {
+		t.Fatalf("expected 7 rate rows, got %d", len(rates))
+	}
+	if stats.InvalidExpirationDates != 1 {
+		t.Errorf("invalid expiration dates = %d, want 1", stats.InvalidExpirationDates)
+	}
+
+	byRate := make(map[float64]RateRow)
+	for _, r := range rates {
+		byRate[r.NegotiatedRate] = r
+	}
+	r := byRate[30]
+	if exp, ok := r.Expiration(); !ok || exp.Format("2006-01-02") != "2025-12-31" {
+		t.Errorf("US-format expiration = %v, want 2025-12-31", exp)
+	}
+	if r := byRate[40]; r.ExpirationDate != nil || !r.NoExpiration {
+		t.Errorf("sentinel expiration = %v (no_expiration %v), want NULL and true", r.ExpirationDate, r.NoExpiration)
+	}
+	if r := byRate[50]; r.ExpirationDate != nil || r.ExpirationDateRaw == nil || *r.ExpirationDateRaw != "ongoing" {
+		t.Errorf("invalid expiration = %v raw %v, want NULL and \"ongoing\"", r.ExpirationDate, r.ExpirationDateRaw)
+	}
+	for _, rate := range []float64{60, 70} {
+		if r := byRate[rate]; r.ExpirationDate != nil || r.NoExpiration || r.ExpirationDateRaw != nil {
+			t.Errorf("missing expiration (rate %v) = %v raw %v, want NULL and no raw value", rate, r.ExpirationDate, r.ExpirationDateRaw)
+		}
+	}
+}
+
+// TestExpirationEpoch checks 1970-01-01, day 0, round-trips as a date
+// rather than NULL, in a column typed as a DATE.
+func TestExpirationEpoch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.parquet")
+	w, err := NewRateParquetWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch := int32(0)
+	for _, r := range []RateRow{{NegotiatedRate: 1, ExpirationDate: &epoch}, {NegotiatedRate: 2}} {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rates := readRateRows(t, path)
+	if exp, ok := rates[0].Expiration(); !ok || exp.Format("2006-01-02") != "1970-01-01" {
+		t.Errorf("epoch expiration = %v (%v), want 1970-01-01", exp, ok)
+	}
+	if _, ok := rates[1].Expiration(); ok || rates[1].ExpirationDate != nil {
+		t.Errorf("missing expiration = %v, want NULL", rates[1].ExpirationDate)
+	}
+	col, ok := rateSchema.Lookup("expiration_date")
+	if !ok || col.Node.Type().LogicalType() == nil || col.Node.Type().LogicalType().Date == nil {
+		t.Errorf("expiration_date column is not a DATE")
+	}
+}
+
+func TestAsOfDropsExpiredPrices(t *testing.T) {
+	rates, stats := convertExpirationSample(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	if stats.ExpiredPrices != 2 {
+		t.Errorf("expired prices = %d, want 2", stats.ExpiredPrices)
+	}
+	var got []float64
+	for _, r := range rates {
+		got = append(got, r.NegotiatedRate)
+	}
+	// 99201's only price expired; the sentinel, unparseable and missing
+	// dates stay.
+	if !slices.Equal(got, []float64{30, 40, 50, 60, 70}) {
+		t.Errorf("rates after -as-of = %v, want [30 40 50 60 70]", got)
+	}
+}
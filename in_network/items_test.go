@@ -0,0 +1,176 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func readParquetRows[T any](t *testing.T, path string) []T {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+
+	pf, err := parquet.OpenFile(f, stat.Size())
+	if err != nil {
+		t.Fatalf("open parquet: %v", err)
+	}
+
+	reader := parquet.NewGenericReader[T](pf, rowSchema[T]())
+	defer reader.Close()
+
+	rows := make([]T, reader.NumRows())
+	n, err := reader.Read(rows)
+	if err != nil && err != io.EOF {
+		t.Fatalf("read: %v", err)
+	}
+	return rows[:n]
+}
+
+type itemOutput struct {
+	rates []RateRow
+	items []ItemRow
+	codes []ContainedCodeRow
+	stats *ConvertStats
+}
+
+func convertWithItems(t *testing.T, name string, codesJSON bool, filter map[int64]bool) itemOutput {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join(examplesDir, name))
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	dir := t.TempDir()
+	paths := map[string]string{
+		"rates":     filepath.Join(dir, "rates.parquet"),
+		"providers": filepath.Join(dir, "providers.parquet"),
+		"items":     filepath.Join(dir, "items.parquet"),
+		"codes":     filepath.Join(dir, "codes.parquet"),
+	}
+	rw, err := NewRateParquetWriter(paths["rates"])
+	if err != nil {
+		t.Fatalf("rate writer: %v", err)
+	}
+	pw, err := NewProviderParquetWriter(paths["providers"])
+	if err != nil {
+		t.Fatalf("provider writer: %v", err)
+	}
+	iw, err := NewItemParquetWriter(paths["items"])
+	if err != nil {
+		t.Fatalf("item writer: %v", err)
+	}
+	cw, err := NewContainedCodeParquetWriter(paths["codes"])
+	if err != nil {
+		t.Fatalf("contained code writer: %v", err)
+	}
+
+	converter := NewStreamConverter(f, false)
+	converter.SetItemWriters(iw, cw)
+	converter.SetCodesJSON(codesJSON)
+	if filter != nil {
+		converter.SetNPIFilter(filter)
+	}
+	stats, err := converter.Convert(rw, pw)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	for _, w := range []interface{ Close() error }{rw, pw, iw, cw} {
+		if err := w.Close(); err != nil {
+			t.Fatalf("close: %v", err)
+		}
+	}
+
+	return itemOutput{
+		rates: readRateRows(t, paths["rates"]),
+		items: readParquetRows[ItemRow](t, paths["items"]),
+		codes: readParquetRows[ContainedCodeRow](t, paths["codes"]),
+		stats: stats,
+	}
+}
+
+func TestItemsBundle(t *testing.T) {
+	out := convertWithItems(t, "in-network-rates-bundle-single-plan-sample.json", true, nil)
+
+	if len(out.items) != 1 {
+		t.Fatalf("item rows = %d, want 1", len(out.items))
+	}
+	item := out.items[0]
+	if item.ItemID != 1 {
+		t.Errorf("item_id = %d, want 1", item.ItemID)
+	}
+	if item.NegotiationArrangement != "bundle" || item.BundledCodeCount != 2 || item.CoveredServiceCount != 0 {
+		t.Errorf("item = %+v", item)
+	}
+	for _, r := range out.rates {
+		if r.ItemID != item.ItemID {
+			t.Errorf("rate item_id = %d, want %d", r.ItemID, item.ItemID)
+		}
+	}
+
+	if len(out.codes) != 2 {
+		t.Fatalf("contained code rows = %d, want 2", len(out.codes))
+	}
+	for i, want := range []string{"27447", "27446"} {
+		c := out.codes[i]
+		if c.ItemID != item.ItemID || c.Relation != RelationBundled || c.BillingCode != want {
+			t.Errorf("code[%d] = %+v, want bundled %s", i, c, want)
+		}
+	}
+	if out.stats.ItemRows != 1 || out.stats.ContainedCodeRows != 2 {
+		t.Errorf("stats items=%d codes=%d", out.stats.ItemRows, out.stats.ContainedCodeRows)
+	}
+}
+
+func TestItemsCapitation(t *testing.T) {
+	out := convertWithItems(t, "in-network-rates-capitation-single-plan-sample.json", true, nil)
+
+	if len(out.items) != 1 || out.items[0].CoveredServiceCount != 2 {
+		t.Fatalf("items = %+v, want one item with 2 covered services", out.items)
+	}
+	if len(out.codes) != 2 {
+		t.Fatalf("contained code rows = %d, want 2", len(out.codes))
+	}
+	for _, c := range out.codes {
+		if c.Relation != RelationCovered {
+			t.Errorf("relation = %q, want %q", c.Relation, RelationCovered)
+		}
+	}
+}
+
+func TestItemsWithoutCodesJSON(t *testing.T) {
+	out := convertWithItems(t, "in-network-rates-bundle-single-plan-sample.json", false, nil)
+
+	for _, r := range out.rates {
+		if r.BundledCodesJSON != nil || r.CoveredServicesJSON != nil {
+			t.Errorf("expected JSON columns to be empty, got bundled=%v covered=%v",
+				r.BundledCodesJSON, r.CoveredServicesJSON)
+		}
+	}
+	if len(out.codes) != 2 {
+		t.Errorf("contained code rows = %d, want 2", len(out.codes))
+	}
+}
+
+func TestItemsDroppedByNPIFilter(t *testing.T) {
+	out := convertWithItems(t, "in-network-rates-bundle-single-plan-sample.json", true,
+		map[int64]bool{9999999999: true})
+
+	if len(out.rates) != 0 || len(out.items) != 0 || len(out.codes) != 0 {
+		t.Errorf("rates=%d items=%d codes=%d, want all 0",
+			len(out.rates), len(out.items), len(out.codes))
+	}
+}
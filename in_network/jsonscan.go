@@ -0,0 +1,555 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// jsonScanner is a streaming JSON reader for the converter's hot loops.
+// Hand-written decoders walk objects and arrays with it instead of going
+// through encoding/json's reflection. It accepts what encoding/json
+// accepts for the decoded types: object keys match case-insensitively,
+// null leaves a value unset (nil for slices), [] decodes to an empty
+// non-nil slice, and invalid UTF-8 in strings becomes U+FFFD.
+type jsonScanner struct {
+	r       io.Reader
+	buf     []byte // buf[pos:end] is unread input
+	pos     int
+	end     int
+	offset  int64 // input offset of buf[0]
+	err     error // sticky read error
+	scratch []byte
+	key     []byte // Object's copy of the current key
+	interns map[string]string
+}
+
+const (
+	jsonScanBufSize = 256 << 10
+	maxInterned     = 4096
+)
+
+func newJSONScanner(r io.Reader) *jsonScanner {
+	return &jsonScanner{
+		r:       r,
+		buf:     make([]byte, jsonScanBufSize),
+		interns: make(map[string]string),
+	}
+}
+
+// InputOffset returns the number of input bytes consumed.
+func (s *jsonScanner) InputOffset() int64 { return s.offset + int64(s.pos) }
+
+// fill reads more input, keeping buf[keep:end] (keep ≤ pos) at the start
+// of the buffer. It reports false at end of input or on a read error.
+func (s *jsonScanner) fill(keep int) bool {
+	if s.err != nil {
+		return false
+	}
+	if keep > 0 {
+		n := copy(s.buf, s.buf[keep:s.end])
+		s.offset += int64(keep)
+		s.pos -= keep
+		s.end = n
+	}
+	if s.end == len(s.buf) {
+		grown := make([]byte, 2*len(s.buf))
+		copy(grown, s.buf[:s.end])
+		s.buf = grown
+	}
+	for {
+		n, err := s.r.Read(s.buf[s.end:])
+		s.end += n
+		if err != nil {
+			s.err = err
+			return n > 0
+		}
+		if n > 0 {
+			return true
+		}
+	}
+}
+
+func (s *jsonScanner) readErr() error {
+	if s.err == nil || s.err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return s.err
+}
+
+func (s *jsonScanner) syntaxError(c byte, context string) error {
+	return fmt.Errorf("invalid character %q %s at offset %d", c, context, s.InputOffset())
+}
+
+// peek skips whitespace and returns the next byte without consuming it.
+func (s *jsonScanner) peek() (byte, error) {
+	for {
+		for s.pos < s.end {
+			switch c := s.buf[s.pos]; c {
+			case ' ', '\t', '\n', '\r':
+				s.pos++
+			default:
+				return c, nil
+			}
+		}
+		if !s.fill(s.pos) {
+			return 0, s.readErr()
+		}
+	}
+}
+
+// expect consumes the next non-space byte, which must be c.
+func (s *jsonScanner) expect(c byte, context string) error {
+	got, err := s.peek()
+	if err != nil {
+		return err
+	}
+	if got != c {
+		return s.syntaxError(got, context)
+	}
+	s.pos++
+	return nil
+}
+
+// literal consumes the literal lit (true, false or null).
+func (s *jsonScanner) literal(lit string) error {
+	for s.end-s.pos < len(lit) {
+		if !s.fill(s.pos) {
+			return s.readErr()
+		}
+	}
+	if string(s.buf[s.pos:s.pos+len(lit)]) != lit {
+		return s.syntaxError(s.buf[s.pos], "in literal")
+	}
+	s.pos += len(lit)
+	return nil
+}
+
+// null consumes a null literal if one is next.
+func (s *jsonScanner) null() (bool, error) {
+	c, err := s.peek()
+	if err != nil || c != 'n' {
+		return false, err
+	}
+	return true, s.literal("null")
+}
+
+// Object calls fn with each key of the object at the current position;
+// fn must consume the key's value. key is only valid until fn reads on.
+// A null object calls fn never.
+func (s *jsonScanner) Object(fn func(key []byte) error) error {
+	if null, err := s.null(); null || err != nil {
+		return err
+	}
+	if err := s.expect('{', "looking for beginning of object"); err != nil {
+		return err
+	}
+	c, err := s.peek()
+	if err != nil {
+		return err
+	}
+	if c == '}' {
+		s.pos++
+		return nil
+	}
+	for {
+		key, err := s.rawString()
+		if err != nil {
+			return err
+		}
+		// key may point into buf, which reading the ':' can refill.
+		key = append(s.key[:0], key...)
+		s.key = key
+		lowerASCII(key)
+		if err := s.expect(':', "after object key"); err != nil {
+			return err
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+		c, err := s.peek()
+		if err != nil {
+			return err
+		}
+		s.pos++
+		switch c {
+		case ',':
+		case '}':
+			return nil
+		default:
+			return s.syntaxError(c, "after object key:value pair")
+		}
+	}
+}
+
+// Array calls fn for each element of the array at the current position;
+// fn must consume the element. It reports whether the value was an array
+// rather than null.
+func (s *jsonScanner) Array(fn func() error) (bool, error) {
+	if null, err := s.null(); null || err != nil {
+		return false, err
+	}
+	if err := s.expect('[', "looking for beginning of array"); err != nil {
+		return false, err
+	}
+	c, err := s.peek()
+	if err != nil {
+		return true, err
+	}
+	if c == ']' {
+		s.pos++
+		return true, nil
+	}
+	for {
+		if err := fn(); err != nil {
+			return true, err
+		}
+		c, err := s.peek()
+		if err != nil {
+			return true, err
+		}
+		s.pos++
+		switch c {
+		case ',':
+		case ']':
+			return true, nil
+		default:
+			return true, s.syntaxError(c, "after array element")
+		}
+	}
+}
+
+// lowerASCII folds keys so they match lower-case field names the way
+// encoding/json's case-insensitive matching does.
+func lowerASCII(b []byte) {
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+}
+
+// rawString reads a string and returns its unescaped bytes, which are
+// only valid until the next read.
+func (s *jsonScanner) rawString() ([]byte, error) {
+	c, err := s.peek()
+	if err != nil {
+		return nil, err
+	}
+	if c != '"' {
+		return nil, s.syntaxError(c, "looking for beginning of string")
+	}
+	s.pos++
+
+	// Fast path: no escapes before the closing quote in the buffer.
+fast:
+	for i := s.pos; i < s.end; i++ {
+		switch c := s.buf[i]; {
+		case c == '"':
+			b := s.buf[s.pos:i]
+			s.pos = i + 1
+			return b, nil
+		case c == '\\' || c < 0x20:
+			break fast
+		}
+	}
+
+	out := s.scratch[:0]
+	for {
+		if s.pos >= s.end && !s.fill(s.pos) {
+			return nil, s.readErr()
+		}
+		c := s.buf[s.pos]
+		switch {
+		case c == '"':
+			s.pos++
+			s.scratch = out
+			return out, nil
+		case c < 0x20:
+			return nil, s.syntaxError(c, "in string literal")
+		case c != '\\':
+			out = append(out, c)
+			s.pos++
+			continue
+		}
+		// Escape sequence: make sure it is fully buffered (\uXXXX\uXXXX).
+		for s.end-s.pos < 12 && s.fill(s.pos) {
+		}
+		if s.end-s.pos < 2 {
+			return nil, s.readErr()
+		}
+		e := s.buf[s.pos+1]
+		s.pos += 2
+		switch e {
+		case '"', '\\', '/':
+			out = append(out, e)
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'u':
+			r, ok := s.hex4()
+			if !ok {
+				return nil, s.syntaxError('u', "in \\u hexadecimal character escape")
+			}
+			if utf16.IsSurrogate(r) {
+				r = s.lowSurrogate(r)
+			}
+			out = utf8.AppendRune(out, r)
+		default:
+			return nil, s.syntaxError(e, "in string escape code")
+		}
+	}
+}
+
+// lowSurrogate completes a UTF-16 surrogate pair from a following \uXXXX
+// escape. Like encoding/json, it returns U+FFFD and leaves the input
+// alone when there is no valid pair.
+func (s *jsonScanner) lowSurrogate(hi rune) rune {
+	if s.end-s.pos < 6 || s.buf[s.pos] != '\\' || s.buf[s.pos+1] != 'u' {
+		return utf8.RuneError
+	}
+	save := s.pos
+	s.pos += 2
+	if lo, ok := s.hex4(); ok {
+		if r := utf16.DecodeRune(hi, lo); r != utf8.RuneError {
+			return r
+		}
+	}
+	s.pos = save
+	return utf8.RuneError
+}
+
+func (s *jsonScanner) hex4() (rune, bool) {
+	if s.end-s.pos < 4 {
+		return 0, false
+	}
+	var r rune
+	for _, c := range s.buf[s.pos : s.pos+4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r*16 + rune(c)
+	}
+	s.pos += 4
+	return r, true
+}
+
+// validString converts b to a string, replacing each invalid UTF-8 byte
+// with U+FFFD as encoding/json does.
+func validString(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	out := make([]byte, 0, len(b)+8)
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size == 1 {
+			out = utf8.AppendRune(out, utf8.RuneError)
+		} else {
+			out = append(out, b[:size]...)
+		}
+		b = b[size:]
+	}
+	return string(out)
+}
+
+// String reads a string value; null reads as "".
+func (s *jsonScanner) String() (string, error) {
+	if null, err := s.null(); null || err != nil {
+		return "", err
+	}
+	b, err := s.rawString()
+	if err != nil {
+		return "", err
+	}
+	return validString(b), nil
+}
+
+// Interned reads a string value, sharing one copy per distinct value.
+// Use it for enum-like fields repeated on every price.
+func (s *jsonScanner) Interned() (string, error) {
+	if null, err := s.null(); null || err != nil {
+		return "", err
+	}
+	b, err := s.rawString()
+	if err != nil {
+		return "", err
+	}
+	if v, ok := s.interns[string(b)]; ok {
+		return v, nil
+	}
+	v := validString(b)
+	if len(s.interns) < maxInterned {
+		s.interns[v] = v
+	}
+	return v, nil
+}
+
+// Strings reads an array of strings, interning them.
+func (s *jsonScanner) Strings() ([]string, error) {
+	out := []string{}
+	isArray, err := s.Array(func() error {
+		v, err := s.Interned()
+		out = append(out, v)
+		return err
+	})
+	if !isArray {
+		return nil, err
+	}
+	return out, err
+}
+
+// number returns the bytes of the number at the current position.
+func (s *jsonScanner) number() ([]byte, error) {
+	if _, err := s.peek(); err != nil {
+		return nil, err
+	}
+	start := s.pos
+	for {
+		for s.pos < s.end {
+			switch c := s.buf[s.pos]; {
+			case '0' <= c && c <= '9', c == '-', c == '+', c == '.', c == 'e', c == 'E':
+				s.pos++
+				continue
+			}
+			return s.checkNumber(s.buf[start:s.pos])
+		}
+		n := s.pos - start
+		if !s.fill(start) {
+			if s.err == io.EOF && n > 0 {
+				return s.checkNumber(s.buf[s.pos-n : s.pos])
+			}
+			return nil, s.readErr()
+		}
+		start = s.pos - n
+	}
+}
+
+var errInvalidNumber = errors.New("invalid number")
+
+// checkNumber enforces the JSON number grammar, which is stricter than
+// strconv's (no leading '+', '.', or zeros).
+func (s *jsonScanner) checkNumber(b []byte) ([]byte, error) {
+	i := 0
+	if i < len(b) && b[i] == '-' {
+		i++
+	}
+	digits := func() int {
+		n := 0
+		for i < len(b) && '0' <= b[i] && b[i] <= '9' {
+			i++
+			n++
+		}
+		return n
+	}
+	switch {
+	case i < len(b) && b[i] == '0':
+		i++
+	case digits() == 0:
+		return nil, s.numberError(b)
+	}
+	if i < len(b) && b[i] == '.' {
+		i++
+		if digits() == 0 {
+			return nil, s.numberError(b)
+		}
+	}
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+		i++
+		if i < len(b) && (b[i] == '+' || b[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return nil, s.numberError(b)
+		}
+	}
+	if i != len(b) {
+		return nil, s.numberError(b)
+	}
+	return b, nil
+}
+
+func (s *jsonScanner) numberError(b []byte) error {
+	if len(b) == 0 {
+		return s.syntaxError(s.buf[s.pos], "looking for beginning of value")
+	}
+	return fmt.Errorf("%w %q at offset %d", errInvalidNumber, b, s.InputOffset())
+}
+
+// Float64 reads a number; null reads as 0.
+func (s *jsonScanner) Float64() (float64, error) {
+	if null, err := s.null(); null || err != nil {
+		return 0, err
+	}
+	b, err := s.number()
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseFloat(string(b), 64)
+	if err != nil {
+		return 0, fmt.Errorf("number %s at offset %d: %w", b, s.InputOffset(), err)
+	}
+	return v, nil
+}
+
+// Int64 reads an integer; null reads as 0. Like encoding/json, numbers
+// with a fraction or exponent are rejected.
+func (s *jsonScanner) Int64() (int64, error) {
+	if null, err := s.null(); null || err != nil {
+		return 0, err
+	}
+	b, err := s.number()
+	if err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode number %s at offset %d into an integer", b, s.InputOffset())
+	}
+	return v, nil
+}
+
+// Skip consumes one value of any type.
+func (s *jsonScanner) Skip() error {
+	c, err := s.peek()
+	if err != nil {
+		return err
+	}
+	switch {
+	case c == '"':
+		_, err := s.rawString()
+		return err
+	case c == '{':
+		return s.Object(func([]byte) error { return s.Skip() })
+	case c == '[':
+		_, err := s.Array(s.Skip)
+		return err
+	case c == 't':
+		return s.literal("true")
+	case c == 'f':
+		return s.literal("false")
+	case c == 'n':
+		return s.literal("null")
+	case c == '-' || ('0' <= c && c <= '9'):
+		_, err := s.number()
+		return err
+	}
+	return s.syntaxError(c, "looking for beginning of value")
+}
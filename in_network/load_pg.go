@@ -0,0 +1,460 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"in_network/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/parquet-go/parquet-go"
+)
+
+// defaultPgBatch is the number of rows buffered per COPY.
+const defaultPgBatch = 10_000
+
+// PgLoadStats summarizes one Parquet → PostgreSQL load.
+type PgLoadStats struct {
+	FileID         int32
+	Items          int64
+	Prices         int64
+	ProviderGroups int64
+	ProviderNPIs   int64
+}
+
+// loadParquetToPg bulk-loads a converted in-network file (the _rates and
+// _providers outputs under base) into PostgreSQL via COPY. The whole file
+// loads in one transaction, so a failed load leaves nothing behind.
+func loadParquetToPg(ctx context.Context, base, connStr string, batchSize int) (*PgLoadStats, error) {
+	if batchSize <= 0 {
+		batchSize = defaultPgBatch
+	}
+	ratesPath, providersPath, err := pgInputPaths(base)
+	if err != nil {
+		return nil, err
+	}
+	rateParts, err := parquetParts(ratesPath)
+	if err != nil {
+		return nil, err
+	}
+	providerParts, err := parquetParts(providersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := firstRateRow(rateParts)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := pgx.Connect(ctx, connStr)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	q := db.New(tx)
+
+	fileID, err := insertFileFromRow(ctx, q, first, ratesPath)
+	if err != nil {
+		return nil, err
+	}
+	stats := &PgLoadStats{FileID: fileID}
+
+	if err := loadProviders(ctx, q, fileID, providerParts, batchSize, stats); err != nil {
+		return nil, err
+	}
+	if err := loadRates(ctx, q, fileID, rateParts, batchSize, stats); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return stats, nil
+}
+
+// insertFileFromRow upserts the reporting entity and plan described by a
+// rate row and records the file.
+func insertFileFromRow(ctx context.Context, q *db.Queries, r *RateRow, source string) (int32, error) {
+	entityID, err := q.UpsertReportingEntity(ctx, db.UpsertReportingEntityParams{
+		Name:       sanitizeUTF8(r.ReportingEntityName),
+		EntityType: sanitizeUTF8(r.ReportingEntityType),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("upsert reporting entity: %w", err)
+	}
+
+	var planID pgtype.Int4
+	if r.PlanName != nil || r.PlanID != nil || r.IssuerName != nil || r.PlanSponsorName != nil {
+		id, err := q.UpsertPayerPlan(ctx, db.UpsertPayerPlanParams{
+			ReportingEntityID: entityID,
+			PlanName:          optString(r.PlanName),
+			IssuerName:        optString(r.IssuerName),
+			PlanSponsorName:   optString(r.PlanSponsorName),
+			PlanIDType:        optString(r.PlanIDType),
+			PlanID:            optString(r.PlanID),
+			PlanMarketType:    optString(r.PlanMarketType),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("upsert plan: %w", err)
+		}
+		planID = pgtype.Int4{Int32: id, Valid: true}
+	}
+
+	lastUpdated := parseDate(r.LastUpdatedOn)
+	if !lastUpdated.Valid {
+		lastUpdated = pgtype.Date{Time: time.Now(), Valid: true}
+	}
+
+	fileID, err := q.InsertInNetworkFile(ctx, db.InsertInNetworkFileParams{
+		ReportingEntityID: entityID,
+		PayerPlanID:       planID,
+		LastUpdatedOn:     lastUpdated,
+		Version:           sanitizeUTF8(r.Version),
+		Source:            source,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("insert file: %w", err)
+	}
+	return fileID, nil
+}
+
+// loadProviders copies provider groups and their NPI/TIN rows.
+func loadProviders(ctx context.Context, q *db.Queries, fileID int32, parts []string, batchSize int, stats *PgLoadStats) error {
+	seen := make(map[int32]bool)
+	var (
+		groups []db.InsertProviderGroupsParams
+		npis   []db.InsertProviderGroupNPIsParams
+	)
+	flush := func() error {
+		// Groups first: NPI rows reference them.
+		if len(groups) > 0 {
+			n, err := q.InsertProviderGroups(ctx, groups)
+			if err != nil {
+				return fmt.Errorf("copy provider_groups: %w", err)
+			}
+			stats.ProviderGroups += n
+			groups = groups[:0]
+		}
+		if len(npis) > 0 {
+			n, err := q.InsertProviderGroupNPIs(ctx, npis)
+			if err != nil {
+				return fmt.Errorf("copy provider_group_npis: %w", err)
+			}
+			stats.ProviderNPIs += n
+			npis = npis[:0]
+		}
+		return nil
+	}
+
+	err := readParquetParts(parts, func(rows []ProviderRow) error {
+		for i := range rows {
+			r := &rows[i]
+			if !seen[r.ProviderGroupID] {
+				seen[r.ProviderGroupID] = true
+				groups = append(groups, db.InsertProviderGroupsParams{
+					FileID:          fileID,
+					ProviderGroupID: r.ProviderGroupID,
+					NetworkNames:    r.NetworkNames,
+				})
+			}
+			npis = append(npis, db.InsertProviderGroupNPIsParams{
+				FileID:          fileID,
+				ProviderGroupID: r.ProviderGroupID,
+				Npi:             r.NPI,
+				TinType:         sanitizeUTF8(r.TINType),
+				TinValue:        sanitizeUTF8(r.TINValue),
+				BusinessName:    optToPgText(r.BusinessName),
+			})
+			if len(npis) >= batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// loadRates copies items (derived from the denormalized item columns on
+// rate rows) and negotiated prices.
+func loadRates(ctx context.Context, q *db.Queries, fileID int32, parts []string, batchSize int, stats *PgLoadStats) error {
+	seen := make(map[int64]bool)
+	var (
+		items  []db.InsertInNetworkItemsParams
+		prices []db.InsertNegotiatedPricesParams
+		last   = time.Now()
+	)
+	flush := func() error {
+		// Items first: prices reference them.
+		if len(items) > 0 {
+			n, err := q.InsertInNetworkItems(ctx, items)
+			if err != nil {
+				return fmt.Errorf("copy in_network_items: %w", err)
+			}
+			stats.Items += n
+			items = items[:0]
+		}
+		if len(prices) > 0 {
+			n, err := q.InsertNegotiatedPrices(ctx, prices)
+			if err != nil {
+				return fmt.Errorf("copy negotiated_prices: %w", err)
+			}
+			stats.Prices += n
+			prices = prices[:0]
+		}
+		if time.Since(last) >= 5*time.Second {
+			log.Printf("  progress: %d items, %d prices", stats.Items, stats.Prices)
+			last = time.Now()
+		}
+		return nil
+	}
+
+	err := readParquetParts(parts, func(rows []RateRow) error {
+		for i := range rows {
+			r := &rows[i]
+			if !seen[r.ItemID] {
+				seen[r.ItemID] = true
+				items = append(items, db.InsertInNetworkItemsParams{
+					FileID:                 fileID,
+					ItemID:                 r.ItemID,
+					NegotiationArrangement: r.NegotiationArrangement,
+					Name:                   sanitizeUTF8(r.Name),
+					BillingCodeType:        r.BillingCodeType,
+					BillingCodeTypeVersion: r.BillingCodeTypeVersion,
+					BillingCode:            r.BillingCode,
+					Description:            sanitizeUTF8(r.Description),
+					BundledCodes:           optJSON(r.BundledCodesJSON),
+					CoveredServices:        optJSON(r.CoveredServicesJSON),
+					SeverityOfIllness:      optToPgText(r.SeverityOfIllness),
+				})
+			}
+			prices = append(prices, db.InsertNegotiatedPricesParams{
+				FileID:                fileID,
+				ItemID:                r.ItemID,
+				NegotiatedType:        r.NegotiatedType,
+				NegotiatedRate:        floatToNumeric(r.NegotiatedRate),
+				ExpirationDate:        expirationToPgDate(r),
+				BillingClass:          r.BillingClass,
+				Setting:               strToPgText(r.Setting),
+				ServiceCode:           r.ServiceCode,
+				BillingCodeModifier:   r.BillingCodeModifier,
+				AdditionalInformation: optToPgText(r.AdditionalInformation),
+				ProviderGroupIds:      r.ProviderGroupIDs,
+			})
+			if len(prices) >= batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// pgInputPaths resolves the rates and providers outputs for base. base may
+// be the -out base path or the _rates file or directory itself; each output
+// may be a single Parquet file or a part directory.
+func pgInputPaths(base string) (rates, providers string, err error) {
+	base = strings.TrimSuffix(filepath.Clean(base), ".parquet")
+	base = strings.TrimSuffix(base, "_rates")
+
+	find := func(suffix string) (string, error) {
+		for _, p := range []string{base + suffix + ".parquet", base + suffix} {
+			if _, err := os.Stat(p); err == nil {
+				return p, nil
+			}
+		}
+		return "", fmt.Errorf("no %s output found for %s", suffix, base)
+	}
+	if rates, err = find("_rates"); err != nil {
+		return "", "", err
+	}
+	if providers, err = find("_providers"); err != nil {
+		return "", "", err
+	}
+	return rates, providers, nil
+}
+
+// parquetParts lists the Parquet files making up an output: the file itself,
+// or the parts of a directory (from its manifest when present).
+func parquetParts(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+
+	if data, err := os.ReadFile(filepath.Join(path, manifestName)); err == nil {
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", manifestName, err)
+		}
+		parts := make([]string, len(m.Parts))
+		for i, p := range m.Parts {
+			parts[i] = filepath.Join(path, filepath.FromSlash(p.Path))
+		}
+		return parts, nil
+	}
+
+	var parts []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(p, ".parquet") {
+			parts = append(parts, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(parts)
+	return parts, nil
+}
+
+// readParquetParts reads each part in order and calls fn with every batch.
+func readParquetParts[T any](parts []string, fn func([]T) error) error {
+	buf := make([]T, 8192)
+	for _, path := range parts {
+		if err := readParquetFile(path, buf, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readParquetFile[T any](path string, buf []T, fn func([]T) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open parquet: %w", err)
+	}
+	defer f.Close()
+
+	reader := parquet.NewGenericReader[T](f, rowSchema[T]())
+	defer reader.Close()
+
+	for {
+		// Zero the buffer so rows handed to fn never share slices with
+		// the next batch.
+		clear(buf)
+		n, readErr := reader.Read(buf)
+		if n > 0 {
+			if err := fn(buf[:n]); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("read %s: %w", path, readErr)
+		}
+	}
+}
+
+// firstRateRow returns the first rate row, which carries the file metadata.
+func firstRateRow(parts []string) (*RateRow, error) {
+	var first *RateRow
+	errStop := errors.New("stop")
+	for _, path := range parts {
+		err := readParquetFile(path, make([]RateRow, 1), func(rows []RateRow) error {
+			r := rows[0]
+			first = &r
+			return errStop
+		})
+		if err != nil && !errors.Is(err, errStop) {
+			return nil, err
+		}
+		if first != nil {
+			return first, nil
+		}
+	}
+	return nil, fmt.Errorf("no rate rows to load")
+}
+
+// sanitizeUTF8 replaces invalid UTF-8 bytes with spaces.
+func sanitizeUTF8(s string) string {
+	return strings.ToValidUTF8(s, " ")
+}
+
+func optString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return sanitizeUTF8(*s)
+}
+
+func optToPgText(s *string) pgtype.Text {
+	if s == nil || *s == "" {
+		return pgtype.Text{Valid: false}
+	}
+	return pgtype.Text{String: sanitizeUTF8(*s), Valid: true}
+}
+
+func strToPgText(s string) pgtype.Text {
+	return optToPgText(&s)
+}
+
+func optJSON(s *string) []byte {
+	if s == nil {
+		return nil
+	}
+	return []byte(*s)
+}
+
+func floatToNumeric(f float64) pgtype.Numeric {
+	var num pgtype.Numeric
+	num.Scan(strconv.FormatFloat(f, 'f', -1, 64))
+	return num
+}
+
+// expirationToPgDate maps a rate's expiration to a DATE: the 9999-12-31
+// sentinel becomes 'infinity' and unparseable values become NULL.
+func expirationToPgDate(r *RateRow) pgtype.Date {
+	if r.NoExpiration {
+		return pgtype.Date{InfinityModifier: pgtype.Infinity, Valid: true}
+	}
+	if t, ok := r.Expiration(); ok {
+		return pgtype.Date{Time: t, Valid: true}
+	}
+	return pgtype.Date{}
+}
+
+// parseDate parses a YYYY-MM-DD date, returning NULL if it is malformed.
+func parseDate(s string) pgtype.Date {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(s))
+	if err != nil {
+		return pgtype.Date{Valid: false}
+	}
+	return pgtype.Date{Time: t, Valid: true}
+}
@@ -1,36 +1,145 @@
 package main
 
 import (
-	"bufio"
-	"compress/gzip"
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 )
 
 func main() {
-	inputFile := flag.String("file", "", "Input in-network JSON file (required, supports .gz)")
-	outputBase := flag.String("out", "", "Output base path (default: derived from input filename)")
+	inputFile := flag.String("file", "", "Input in-network JSON file (required, supports .gz; - reads stdin)")
+	outputBase := flag.String("out", "", "Output base path (default: derived from input filename; - writes a tar of all outputs to stdout)")
 	npiFile := flag.String("npi", "", "NPI allowlist JSON file (optional, filters to matching providers)")
+	nppesFile := flag.String("nppes", "", "NPPES bulk CSV to build the NPI allowlist from (supports .gz)")
+	taxonomy := flag.String("taxonomy", "", "NPPES: taxonomy code prefixes, comma-separated (e.g. 207R,208D00000X)")
+	states := flag.String("state", "", "NPPES: practice location states, comma-separated")
+	zips := flag.String("zip", "", "NPPES: practice location ZIP prefixes, comma-separated")
+	orgNames := flag.String("org", "", "NPPES: organization name substrings, comma-separated")
+	tocFile := flag.String("toc", "", "mrfparser TOC extraction (JSON, Parquet or normalized Parquet) for the <base>_plans.parquet bridge")
+	asOf := flag.String("as-of", "", "Drop negotiated prices whose expiration_date is before this date (YYYY-MM-DD)")
+	sourceURL := flag.String("source-url", "", "URL the input was downloaded from; matches -toc entries exactly instead of by file name")
+	npiOut := flag.String("npi-out", "", "Save the NPPES-built allowlist to this JSON file (reusable with -npi)")
 	bufferSize := flag.Int("buffer", 64, "Read buffer size in MB")
 	verbose := flag.Bool("v", false, "Verbose output with progress updates")
+	maxRows := flag.Int64("max-rows", 0, "Start a new part file after N rows (writes part directories)")
+	maxMB := flag.Int64("max-mb", 0, "Start a new part file after roughly N MB (writes part directories)")
+	partitionBy := flag.String("partition", "", "Hive partition keys for rates: billing_code_type,billing_code_prefix")
+	prefixLen := flag.Int("prefix-len", 2, "Length of billing_code_prefix partition values")
+	groupMem := flag.Int("group-mem", defaultGroupIndexMem, "Distinct embedded provider groups kept in memory before the dedup index spills to disk")
+	tmpDir := flag.String("tmpdir", "", "Directory for temporary files (default: system temp dir)")
+	summary := flag.Bool("summary", false, "Also write <base>_summary.parquet with per-code rate distributions")
+	networks := flag.Bool("networks", false, "Also write <base>_networks.parquet with provider network membership (Parquet format only)")
+	format := flag.String("format", FormatParquet, "Format of the rates, providers, items and contained codes outputs: parquet, csv or csv.gz")
+	mergeSummaries := flag.String("merge-summaries", "", "Combine _summary.parquet files matching this glob into -out")
+	codesJSON := flag.Bool("codes-json", true, "Also store bundled_codes/covered_services as JSON columns on rate rows")
+	pgConn := flag.String("pg", "", "PostgreSQL connection string (Parquet → PG mode: -file is the output base)")
+	pgBatch := flag.Int("batch", defaultPgBatch, "Rows per COPY batch in PG mode")
+	validate := flag.Bool("validate", false, "Check the input against the bundled CMS schema and report violations instead of converting")
+	reportFile := flag.String("report", "", "Also write the -validate report as JSON to this file")
+	listFile := flag.String("list", "", "Batch mode: file listing one input path per line")
+	outDir := flag.String("out-dir", "", "Directory for outputs (default: next to each input)")
+	workers := flag.Int("workers", 4, "Batch mode: files converted concurrently")
+	runManifest := flag.String("run-manifest", "", "Batch mode: run manifest path (default: "+runManifestName+" in the output or input directory)")
+	hashInputs := flag.Bool("hash", false, "Batch mode: also compare SHA-256 of inputs when skipping converted files")
+	force := flag.Bool("force", false, "Batch mode: reconvert files the run manifest shows as converted")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, `in_network - Convert CMS in-network rate JSON files to Parquet
 
-Produces two Parquet files:
-  <base>_rates.parquet      One row per negotiated price (denormalized)
-  <base>_providers.parquet  One row per (provider_group_id, NPI)
+Produces these Parquet files:
+  <base>_rates.parquet            One row per negotiated price (denormalized)
+  <base>_providers.parquet        One row per (provider_group_id, NPI)
+  <base>_items.parquet            One row per in-network item
+  <base>_contained_codes.parquet  One row per bundled code or covered service
+  <base>_summary.parquet          One row per code/type/class/setting rate distribution (-summary)
+  <base>_networks.parquet         One row per (network_name, provider_group_id, NPI, TIN) (-networks)
 
-Users JOIN on provider_group_id to resolve provider details.
+Users JOIN on provider_group_id to resolve provider details, and on
+item_id to resolve an item's bundled codes or covered services.
+
+With -max-rows, -max-mb or -partition, each output becomes a directory of
+part files plus a _manifest.json listing parts and row counts:
+  <base>_rates/billing_code_type=CPT/billing_code_prefix=99/part-0001.parquet
+  <base>_providers/part-0001.parquet
+
+With -pg, an existing <base>_rates and <base>_providers output (files or
+part directories) is bulk-loaded into PostgreSQL via COPY instead.
+
+With -summary, a <base>_summary.parquet holds count, min, p10, median,
+p90 and max of negotiated_rate per billing code, negotiated_type,
+billing_class and setting. Each row carries a mergeable quantile sketch;
+-merge-summaries combines many files' summaries. The sketches are kept in
+memory for every distinct key until the input is read.
+
+With -networks, a <base>_networks.parquet flattens the network_name lists
+of provider_references, so NPIs and TINs can be looked up by network.
+group_rate_count and network_rate_count give the rate rows referencing the
+provider group and any group in the network. Embedded provider groups
+carry no network names and are not listed. Building it rereads the
+provider output after conversion.
+
+With -format csv or csv.gz, the rates, providers, items and contained
+codes outputs are written as CSV (<base>_rates.csv.gz etc.) with a header
+row of the Parquet column names. Fields are quoted as needed, NULL is an
+empty field, expiration_date is YYYY-MM-DD, and list columns
+(service_code, billing_code_modifier, provider_group_ids, network_names)
+join their elements with "|". The summary and plans outputs stay Parquet;
+the networks output and part files need Parquet.
+
+With -toc, a <base>_plans.parquet bridge lists every plan (HIOS ID or
+EIN) whose TOC reporting structure references the input, matched by file
+name or exactly by -source-url; a file name shared by several TOC URLs
+needs -source-url. Its in_network_file column is the output base name, so
+multi-plan rates can be joined to plan IDs.
+
+With -nppes, the NPI allowlist is built from a local NPPES bulk CSV by
+taxonomy code prefix, practice state, ZIP prefix and/or organization name
+(criteria are ANDed, comma-separated values ORed; deactivated NPIs are
+dropped). -npi-out saves it for reuse with -npi; without -file it only
+builds the allowlist.
+
+With a directory or glob as -file, or -list, many files are converted
+concurrently (-workers). A run manifest records each file's stats, outputs
+and errors; files whose size and mtime (and SHA-256 with -hash) match a
+previous successful run are skipped. A failing file does not stop the
+batch, but the exit status is 1.
+
+expiration_date is stored as a DATE; the 9999-12-31 "no expiration"
+sentinel sets no_expiration instead, and unparseable values are kept in
+expiration_date_raw. With -as-of, prices that expired before that date are
+dropped, as are items left without prices.
+
+With -file -, the input is read from stdin (gzip is detected from the
+stream) and the outputs are named stdin_* unless -out or -out-dir says
+otherwise. With -out -, the outputs (including part directories) are
+written to a temporary directory under -tmpdir and then streamed to
+stdout as an uncompressed tar archive; logs stay on stderr.
+
+With -validate, the input is streamed against the bundled CMS schema
+(schemas/in-network-rates.json) and violations are reported by JSON path
+and rule with counts; rates referencing undefined provider groups are
+flagged too. Nothing is written, and the exit status is 1 on violations.
 
 Usage:
   in_network -file <input.json> [-out <base>] [-v]
+  in_network -file <input.json> -partition billing_code_type,billing_code_prefix -max-rows 5000000
+  in_network -file <base> -pg <connstr>
+  in_network -file <input.json> -validate [-report report.json]
+  in_network -file <input.json> -as-of 2024-07-01
+  in_network -file <input.json> -summary -networks
+  in_network -file <input.json> -format csv.gz -summary
+  curl -s <url> | in_network -file - -out-dir <dir>
+  curl -s <url> | in_network -file - -out - | tar -x -C <dir>
+  in_network -file '<dir>/*.json.gz' -out-dir <dir> -workers 8
+  in_network -list files.txt -out-dir <dir>
+  in_network -file <input.json.gz> -toc ny_plans.parquet [-source-url <url>]
+  in_network -merge-summaries 'out/*_summary.parquet' -out combined_summary.parquet
+  in_network -nppes npidata.csv -taxonomy 207R -state NY -npi-out ny_im.json [-file <input.json>]
 
 Options:
 `)
@@ -39,100 +148,288 @@ Options:
 
 	flag.Parse()
 
-	if *inputFile == "" {
-		fmt.Fprintln(os.Stderr, "Error: -file is required")
+	if *mergeSummaries != "" {
+		paths, err := filepath.Glob(*mergeSummaries)
+		if err != nil || len(paths) == 0 {
+			log.Fatalf("No summary files match %s", *mergeSummaries)
+		}
+		if *outputBase == "" {
+			log.Fatalf("-merge-summaries requires -out <combined_summary.parquet>")
+		}
+		merged, err := mergeSummaryFiles(paths)
+		if err != nil {
+			log.Fatalf("Failed to merge summaries: %v", err)
+		}
+		n, err := merged.WriteParquet(*outputBase)
+		if err != nil {
+			log.Fatalf("Failed to write merged summary: %v", err)
+		}
+		log.Printf("Merged %d summary files into %s (%d groups)", len(paths), *outputBase, n)
+		return
+	}
+
+	var nppesFilter map[int64]bool
+	if *nppesFile != "" {
+		if *npiFile != "" {
+			log.Fatalf("-npi and -nppes are mutually exclusive")
+		}
+		crit := NPPESCriteria{
+			Taxonomies:  splitList(*taxonomy),
+			States:      splitList(*states),
+			ZIPPrefixes: splitList(*zips),
+			OrgNames:    splitList(*orgNames),
+		}
+		reader, closeNPPES, err := openInput(*nppesFile, *bufferSize*1024*1024)
+		if err != nil {
+			log.Fatalf("Failed to open NPPES file: %v", err)
+		}
+		providers, err := BuildNPIFilterFromNPPES(reader, crit)
+		closeNPPES()
+		if err != nil {
+			log.Fatalf("Failed to build NPI filter: %v", err)
+		}
+		log.Printf("NPI filter: %d NPIs selected from %s", len(providers), *nppesFile)
+		if len(providers) == 0 {
+			log.Printf("Warning: no NPPES providers matched; output will have no rates")
+		}
+		if *npiOut != "" {
+			if err := SaveNPIFilter(*npiOut, providers); err != nil {
+				log.Fatalf("Failed to save NPI filter: %v", err)
+			}
+			log.Printf("NPI filter saved to %s", *npiOut)
+		}
+		nppesFilter = NPIFilterSet(providers)
+		if *inputFile == "" && *listFile == "" {
+			return // build-only
+		}
+	}
+
+	if *inputFile == "" && *listFile == "" {
+		fmt.Fprintln(os.Stderr, "Error: -file or -list is required")
 		flag.Usage()
 		os.Exit(1)
 	}
 
-	// Determine output base path
-	base := *outputBase
-	if base == "" {
-		base = *inputFile
-		for _, ext := range []string{".gz", ".json"} {
-			base = strings.TrimSuffix(base, ext)
+	if *pgConn != "" {
+		start := time.Now()
+		stats, err := loadParquetToPg(context.Background(), *inputFile, *pgConn, *pgBatch)
+		if err != nil {
+			log.Fatalf("PG load error: %v", err)
 		}
+		log.Printf("Loaded file %d in %s: %d items, %d prices, %d provider groups, %d provider NPIs",
+			stats.FileID, time.Since(start).Round(time.Millisecond),
+			stats.Items, stats.Prices, stats.ProviderGroups, stats.ProviderNPIs)
+		return
 	}
-	ratesPath := base + "_rates.parquet"
-	providersPath := base + "_providers.parquet"
 
-	startTime := time.Now()
-	log.Printf("Input:  %s", *inputFile)
-	log.Printf("Output: %s, %s", filepath.Base(ratesPath), filepath.Base(providersPath))
+	partOpts := PartitionOptions{
+		MaxRows:     *maxRows,
+		MaxBytes:    *maxMB * 1024 * 1024,
+		PartitionBy: ParsePartitionKeys(*partitionBy),
+		PrefixLen:   *prefixLen,
+	}
+	if err := partOpts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts := ConvertOptions{
+		BufferSize:    *bufferSize * 1024 * 1024,
+		Verbose:       *verbose,
+		Partition:     partOpts,
+		GroupIndexMem: *groupMem,
+		TmpDir:        *tmpDir,
+		CodesJSON:     *codesJSON,
+		Summary:       *summary,
+		Networks:      *networks,
+		Format:        *format,
+	}
+	if err := opts.validateFormat(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *asOf != "" {
+		t, err := parseAsOf(*asOf)
+		if err != nil {
+			log.Fatalf("Invalid -as-of date %q (want YYYY-MM-DD): %v", *asOf, err)
+		}
+		opts.AsOf = t
+	}
 
-	// Open input file
-	file, err := os.Open(*inputFile)
-	if err != nil {
-		log.Fatalf("Failed to open input: %v", err)
+	// Load NPI filter if specified
+	if *npiFile != "" {
+		filter, err := LoadNPIFilter(*npiFile)
+		if err != nil {
+			log.Fatalf("Failed to load NPI filter: %v", err)
+		}
+		opts.NPIFilter = filter
+		log.Printf("NPI filter: %d NPIs loaded from %s", len(filter), *npiFile)
+	} else if nppesFilter != nil {
+		opts.NPIFilter = nppesFilter
 	}
-	defer file.Close()
 
-	fileInfo, _ := file.Stat()
-	log.Printf("File size: %.2f MB", float64(fileInfo.Size())/(1024*1024))
+	if *tocFile != "" {
+		toc, err := LoadTOCIndex(*tocFile)
+		if err != nil {
+			log.Fatalf("Failed to load TOC extraction: %v", err)
+		}
+		opts.TOC = toc
+		opts.SourceURL = *sourceURL
+		log.Printf("TOC: %d plan/URL pairs over %d in-network URLs from %s", toc.Plans(), toc.URLs(), *tocFile)
+	}
 
-	// Set up buffered reader with optional gzip
-	var reader io.Reader
-	bufSize := *bufferSize * 1024 * 1024
-	br := bufio.NewReaderSize(file, bufSize)
+	if *listFile != "" || isBatchInput(*inputFile) {
+		if *validate || *outputBase != "" || *sourceURL != "" {
+			log.Fatalf("-validate, -out and -source-url take a single -file; use -out-dir in batch mode")
+		}
+		inputs, dir, err := resolveBatchInputs(*inputFile, *listFile)
+		if err != nil {
+			log.Fatalf("Batch input error: %v", err)
+		}
+		if len(inputs) == 0 {
+			log.Fatalf("No input files found")
+		}
+		manifest := *runManifest
+		if manifest == "" {
+			if *outDir != "" {
+				dir = *outDir
+			}
+			manifest = filepath.Join(dir, runManifestName)
+		}
+		log.Printf("Batch: %d input files, %d workers, run manifest %s", len(inputs), *workers, manifest)
 
-	if strings.HasSuffix(strings.ToLower(*inputFile), ".gz") {
-		gz, err := gzip.NewReader(br)
+		start := time.Now()
+		m, err := runBatch(inputs, BatchOptions{
+			Convert:  opts,
+			OutDir:   *outDir,
+			Workers:  *workers,
+			Hash:     *hashInputs,
+			Force:    *force,
+			Manifest: manifest,
+		})
 		if err != nil {
-			log.Fatalf("Failed to create gzip reader: %v", err)
+			log.Fatalf("Batch error: %v", err)
 		}
-		defer gz.Close()
-		reader = gz
-		log.Printf("Detected gzipped input")
-	} else {
-		reader = br
+		log.Printf("Batch done in %v: %d converted, %d skipped, %d failed",
+			time.Since(start).Round(time.Millisecond), m.Converted, m.Skipped, m.Failed)
+		if m.Failed > 0 {
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Create Parquet writers
-	rateWriter, err := NewRateParquetWriter(ratesPath)
-	if err != nil {
-		log.Fatalf("Failed to create rate writer: %v", err)
+	if *inputFile == stdioPath && opts.TOC != nil && *sourceURL == "" {
+		log.Fatalf("-toc with -file - needs -source-url to match the input")
 	}
 
-	providerWriter, err := NewProviderParquetWriter(providersPath)
-	if err != nil {
-		rateWriter.Close()
-		log.Fatalf("Failed to create provider writer: %v", err)
+	// Determine output base path
+	base := *outputBase
+	if base == "" {
+		base = batchOutputBase(*inputFile, *outDir)
+		if *outDir != "" {
+			if err := os.MkdirAll(*outDir, 0755); err != nil {
+				log.Fatalf("Failed to create output directory: %v", err)
+			}
+		}
+	}
+	toStdout := base == stdioPath
+	if toStdout {
+		base = filepath.Base(defaultOutputBase(*inputFile))
 	}
+	paths := outputPaths(base, opts)
 
-	// Load NPI filter if specified
-	converter := NewStreamConverter(reader, *verbose)
-	if *npiFile != "" {
-		filter, err := LoadNPIFilter(*npiFile)
+	startTime := time.Now()
+	log.Printf("Input:  %s", *inputFile)
+	if *inputFile == stdioPath {
+		log.Printf("Reading stdin")
+	} else if fileInfo, err := os.Stat(*inputFile); err == nil {
+		log.Printf("File size: %.2f MB", float64(fileInfo.Size())/(1024*1024))
+	}
+
+	if *validate {
+		reader, closeInput, err := openInput(*inputFile, opts.BufferSize)
 		if err != nil {
-			rateWriter.Close()
-			providerWriter.Close()
-			log.Fatalf("Failed to load NPI filter: %v", err)
+			log.Fatalf("Failed to open input: %v", err)
 		}
-		converter.SetNPIFilter(filter)
-		log.Printf("NPI filter: %d NPIs loaded from %s", len(filter), *npiFile)
+		defer closeInput()
+
+		v, err := NewValidator(reader)
+		if err != nil {
+			log.Fatalf("Validator error: %v", err)
+		}
+		report, err := v.Validate()
+		if err != nil {
+			log.Fatalf("Validation error: %v", err)
+		}
+		report.WriteText(os.Stdout)
+		if *reportFile != "" {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				log.Fatalf("Failed to encode report: %v", err)
+			}
+			if err := os.WriteFile(*reportFile, data, 0o644); err != nil {
+				log.Fatalf("Failed to write report: %v", err)
+			}
+		}
+		log.Printf("Validated in %s", time.Since(startTime).Round(time.Millisecond))
+		if !report.Valid() {
+			os.Exit(1)
+		}
+		return
 	}
 
-	// Convert
-	stats, err := converter.Convert(rateWriter, providerWriter)
-	if err != nil {
-		rateWriter.Close()
-		providerWriter.Close()
-		log.Fatalf("Convert error: %v", err)
+	log.Printf("Output: %s, %s", filepath.Base(paths.Rates), filepath.Base(paths.Providers))
+	if toStdout {
+		log.Printf("Streaming outputs to stdout as a tar archive")
 	}
 
-	// Close writers
-	if err := rateWriter.Close(); err != nil {
-		providerWriter.Close()
-		log.Fatalf("Failed to close rate writer: %v", err)
+	// Convert
+	var res *ConvertResult
+	var err error
+	if toStdout {
+		res, err = convertToTar(os.Stdout, *inputFile, base, opts)
+	} else {
+		res, err = convertFile(*inputFile, base, opts)
 	}
-	if err := providerWriter.Close(); err != nil {
-		log.Fatalf("Failed to close provider writer: %v", err)
+	if err != nil {
+		log.Fatalf("Convert error: %v", err)
 	}
+	stats := res.Stats
 
 	elapsed := time.Since(startTime)
 	log.Printf("Done in %v", elapsed.Round(time.Millisecond))
 	log.Printf("  %d in-network items → %d rate rows (%s)",
-		stats.InNetworkItems, stats.RateRows, filepath.Base(ratesPath))
+		stats.InNetworkItems, stats.RateRows, filepath.Base(paths.Rates))
 	log.Printf("  %d provider rows (%s)",
-		stats.ProviderRows, filepath.Base(providersPath))
+		stats.ProviderRows, filepath.Base(paths.Providers))
+	log.Printf("  %d item rows (%s), %d contained code rows (%s)",
+		stats.ItemRows, filepath.Base(paths.Items), stats.ContainedCodeRows, filepath.Base(paths.ContainedCodes))
+	if stats.UniqueProviderGroups > 0 {
+		log.Printf("  %d embedded provider groups, %d duplicates removed",
+			stats.UniqueProviderGroups, stats.DuplicateProviderGroups)
+		if res.GroupIndexSpilled {
+			log.Printf("  provider group index spilled to disk (over %d groups)", *groupMem)
+		}
+	}
+	if !opts.AsOf.IsZero() {
+		log.Printf("  %d prices expired before %s dropped", stats.ExpiredPrices, opts.AsOf.Format("2006-01-02"))
+	}
+	if stats.InvalidExpirationDates > 0 {
+		log.Printf("  warning: %d unparseable expiration dates kept in expiration_date_raw", stats.InvalidExpirationDates)
+	}
+	if opts.Summary {
+		log.Printf("  %d summary rows (%s)", stats.SummaryRows, filepath.Base(paths.Summary))
+	}
+	if opts.Networks {
+		log.Printf("  %d network rows (%s)", stats.NetworkRows, filepath.Base(paths.Networks))
+	}
+	if opts.TOC != nil {
+		log.Printf("  %d plan rows (%s)", stats.PlanRows, filepath.Base(paths.Plans))
+		if stats.PlanRows == 0 {
+			log.Printf("  warning: no TOC plan references %s", *inputFile)
+		}
+	}
+	if partOpts.Enabled() {
+		log.Printf("  %d rate parts, %d provider parts (see %s)",
+			res.RateParts, res.ProviderParts, manifestName)
+	}
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+)
+
+// NetworkIndex records the networks of each provider_references group and
+// counts the rate rows referencing each set of groups while converting.
+// Only provider_references carry network_name, so rates using embedded
+// provider groups are not counted. Counts are resolved when the output is
+// written, so provider_references may come before or after in_network.
+type NetworkIndex struct {
+	groupNetworks map[int32][]string
+	refRates      map[string]int64 // encoded provider_group_ids → rate rows
+}
+
+// NewNetworkIndex creates an empty index.
+func NewNetworkIndex() *NetworkIndex {
+	return &NetworkIndex{
+		groupNetworks: make(map[int32][]string),
+		refRates:      make(map[string]int64),
+	}
+}
+
+// AddGroup records the networks of a provider_references group.
+func (x *NetworkIndex) AddGroup(id int32, networks []string) {
+	if len(networks) > 0 {
+		x.groupNetworks[id] = networks
+	}
+}
+
+// AddRates counts n rate rows referencing the provider groups ids.
+func (x *NetworkIndex) AddRates(ids []int32, n int64) {
+	if len(ids) == 0 || n == 0 {
+		return
+	}
+	key := make([]byte, 4*len(ids))
+	for i, id := range ids {
+		binary.LittleEndian.PutUint32(key[4*i:], uint32(id))
+	}
+	x.refRates[string(key)] += n
+}
+
+// rateCounts resolves the rate rows referencing each group and each
+// network. A rate referencing several groups in one network counts once
+// for that network.
+func (x *NetworkIndex) rateCounts() (groups map[int32]int64, networks map[string]int64) {
+	groups = make(map[int32]int64)
+	networks = make(map[string]int64)
+	var seen []string
+	for key, n := range x.refRates {
+		seen = seen[:0]
+		for i := 0; i < len(key); i += 4 {
+			id := int32(binary.LittleEndian.Uint32([]byte(key[i : i+4])))
+			groups[id] += n
+			for _, name := range x.groupNetworks[id] {
+				if !slices.Contains(seen, name) {
+					seen = append(seen, name)
+					networks[name] += n
+				}
+			}
+		}
+	}
+	return groups, networks
+}
+
+// WriteParquet writes one network row per (network_name, provider row)
+// for the provider rows in providerParts, with the resolved rate counts.
+func (x *NetworkIndex) WriteParquet(path string, providerParts []string) (int64, error) {
+	groupRates, networkRates := x.rateCounts()
+	w, err := NewNetworkParquetWriter(path)
+	if err != nil {
+		return 0, fmt.Errorf("create network writer: %w", err)
+	}
+	var n int64
+	var seen []string
+	err = readParquetParts(providerParts, func(rows []ProviderRow) error {
+		for _, p := range rows {
+			seen = seen[:0]
+			for _, name := range p.NetworkNames {
+				if slices.Contains(seen, name) {
+					continue
+				}
+				seen = append(seen, name)
+				row := NetworkRow{
+					NetworkName:      name,
+					ProviderGroupID:  p.ProviderGroupID,
+					NPI:              p.NPI,
+					TINType:          p.TINType,
+					TINValue:         p.TINValue,
+					GroupRateCount:   groupRates[p.ProviderGroupID],
+					NetworkRateCount: networkRates[name],
+				}
+				if err := w.Write(row); err != nil {
+					return fmt.Errorf("write network row: %w", err)
+				}
+				n++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		w.Close()
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, fmt.Errorf("close network writer: %w", err)
+	}
+	return n, nil
+}
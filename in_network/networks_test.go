@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// in_network comes first to check counts do not depend on field order.
+const networksInput = `{
+  "reporting_entity_name": "Test", "reporting_entity_type": "health insurance issuer",
+  "last_updated_on": "2024-01-01", "version": "1.3.1",
+  "in_network": [
+    {"negotiation_arrangement": "ffs", "name": "Visit", "billing_code_type": "CPT",
+     "billing_code_type_version": "2024", "billing_code": "99213", "description": "Visit",
+     "negotiated_rates": [{"provider_references": [1, 2], "negotiated_prices": [
+       {"negotiated_type": "negotiated", "negotiated_rate": 100, "expiration_date": "9999-12-31", "billing_class": "professional"},
+       {"negotiated_type": "negotiated", "negotiated_rate": 90, "expiration_date": "9999-12-31", "billing_class": "institutional"}]}]},
+    {"negotiation_arrangement": "ffs", "name": "Visit", "billing_code_type": "CPT",
+     "billing_code_type_version": "2024", "billing_code": "99214", "description": "Visit",
+     "negotiated_rates": [
+       {"provider_references": [2], "negotiated_prices": [
+         {"negotiated_type": "negotiated", "negotiated_rate": 150, "expiration_date": "9999-12-31", "billing_class": "professional"}]},
+       {"provider_groups": [{"npi": [1999999999], "tin": {"type": "ein", "value": "99-0000000"}}], "negotiated_prices": [
+         {"negotiated_type": "negotiated", "negotiated_rate": 140, "expiration_date": "9999-12-31", "billing_class": "professional"}]}]}
+  ],
+  "provider_references": [
+    {"provider_group_id": 1, "network_name": ["Gold", "Silver", "Gold"],
+     "provider_groups": [{"npi": [1111111111, 1222222222], "tin": {"type": "ein", "value": "11-1111111"}}]},
+    {"provider_group_id": 2, "network_name": ["Gold"],
+     "provider_groups": [{"npi": [], "tin": {"type": "ein", "value": "22-2222222"}}]},
+    {"provider_group_id": 3,
+     "provider_groups": [{"npi": [1333333333], "tin": {"type": "ein", "value": "33-3333333"}}]}
+  ]
+}`
+
+func TestNetworksOutput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.json")
+	if err := os.WriteFile(input, []byte(networksInput), 0644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := convertFile(input, filepath.Join(dir, "out"), ConvertOptions{BufferSize: 1 << 16, Networks: true})
+	if err != nil {
+		t.Fatalf("convertFile: %v", err)
+	}
+	rows, err := parquet.ReadFile[NetworkRow](res.Outputs.Networks)
+	if err != nil {
+		t.Fatalf("read networks: %v", err)
+	}
+	if int64(len(rows)) != res.Stats.NetworkRows {
+		t.Errorf("NetworkRows = %d, read %d", res.Stats.NetworkRows, len(rows))
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].NetworkName != rows[j].NetworkName {
+			return rows[i].NetworkName < rows[j].NetworkName
+		}
+		return rows[i].NPI < rows[j].NPI
+	})
+
+	// Group 1 is listed under Gold once despite the repeated name; group 2
+	// has no NPIs, so its TIN row has NPI 0; group 3 has no network. The
+	// first item's two prices reference both Gold groups but count once
+	// for Gold; the embedded group's price is not counted.
+	want := []NetworkRow{
+		{NetworkName: "Gold", ProviderGroupID: 2, NPI: 0, TINType: "ein", TINValue: "22-2222222", GroupRateCount: 3, NetworkRateCount: 3},
+		{NetworkName: "Gold", ProviderGroupID: 1, NPI: 1111111111, TINType: "ein", TINValue: "11-1111111", GroupRateCount: 2, NetworkRateCount: 3},
+		{NetworkName: "Gold", ProviderGroupID: 1, NPI: 1222222222, TINType: "ein", TINValue: "11-1111111", GroupRateCount: 2, NetworkRateCount: 3},
+		{NetworkName: "Silver", ProviderGroupID: 1, NPI: 1111111111, TINType: "ein", TINValue: "11-1111111", GroupRateCount: 2, NetworkRateCount: 2},
+		{NetworkName: "Silver", ProviderGroupID: 1, NPI: 1222222222, TINType: "ein", TINValue: "11-1111111", GroupRateCount: 2, NetworkRateCount: 2},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
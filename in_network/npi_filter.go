@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 )
 
 type npiEntry struct {
-	NPI string `json:"npi"`
+	NPI  string `json:"npi"`
+	Name string `json:"name,omitempty"`
 }
 
 // LoadNPIFilter reads a JSON array of objects with "npi" string fields
@@ -34,3 +36,22 @@ func LoadNPIFilter(path string) (map[int64]bool, error) {
 	}
 	return filter, nil
 }
+
+// SaveNPIFilter writes providers in the format LoadNPIFilter reads, sorted
+// by NPI, so a generated allowlist can be reused with -npi.
+func SaveNPIFilter(path string, providers []NPPESProvider) error {
+	entries := make([]npiEntry, len(providers))
+	for i, p := range providers {
+		entries[i] = npiEntry{NPI: strconv.FormatInt(p.NPI, 10), Name: p.Name}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].NPI < entries[j].NPI })
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode NPI file: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write NPI file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// NPPES bulk file (npidata_pfile_*.csv) column names.
+const (
+	nppesColNPI           = "NPI"
+	nppesColEntityType    = "Entity Type Code"
+	nppesColOrgName       = "Provider Organization Name (Legal Business Name)"
+	nppesColOtherOrgName  = "Provider Other Organization Name"
+	nppesColLastName      = "Provider Last Name (Legal Name)"
+	nppesColFirstName     = "Provider First Name"
+	nppesColState         = "Provider Business Practice Location Address State Name"
+	nppesColPostalCode    = "Provider Business Practice Location Address Postal Code"
+	nppesColDeactivated   = "NPI Deactivation Date"
+	nppesColReactivated   = "NPI Reactivation Date"
+	nppesColTaxonomy      = "Healthcare Provider Taxonomy Code_"
+	nppesTaxonomySlots    = 15
+	nppesEntityIndividual = "1"
+)
+
+// NPPESCriteria selects providers from an NPPES extract. Every non-empty
+// criterion must match; within one criterion any value may match.
+type NPPESCriteria struct {
+	Taxonomies  []string // taxonomy code prefixes, matched against all of a provider's codes
+	States      []string // practice location state, e.g. "NY"
+	ZIPPrefixes []string // practice location postal code prefixes
+	OrgNames    []string // case-insensitive substrings of the organization name
+}
+
+// Empty reports whether no criterion is set.
+func (c NPPESCriteria) Empty() bool {
+	return len(c.Taxonomies) == 0 && len(c.States) == 0 && len(c.ZIPPrefixes) == 0 && len(c.OrgNames) == 0
+}
+
+// NPPESProvider is a provider selected from an NPPES extract.
+type NPPESProvider struct {
+	NPI  int64
+	Name string
+}
+
+// nppesColumns holds header indices; -1 marks an absent optional column.
+type nppesColumns struct {
+	npi, entityType, orgName, otherOrgName, lastName, firstName int
+	state, postalCode, deactivated, reactivated                 int
+	taxonomies                                                  []int
+}
+
+func findNPPESColumns(header []string) (*nppesColumns, error) {
+	idx := make(map[string]int, len(header))
+	for i, h := range header {
+		// The bulk file's first header may carry a UTF-8 BOM.
+		idx[strings.TrimPrefix(strings.TrimSpace(h), "\ufeff")] = i
+	}
+	col := func(name string) int {
+		if i, ok := idx[name]; ok {
+			return i
+		}
+		return -1
+	}
+	c := &nppesColumns{
+		npi:          col(nppesColNPI),
+		entityType:   col(nppesColEntityType),
+		orgName:      col(nppesColOrgName),
+		otherOrgName: col(nppesColOtherOrgName),
+		lastName:     col(nppesColLastName),
+		firstName:    col(nppesColFirstName),
+		state:        col(nppesColState),
+		postalCode:   col(nppesColPostalCode),
+		deactivated:  col(nppesColDeactivated),
+		reactivated:  col(nppesColReactivated),
+	}
+	for n := 1; n <= nppesTaxonomySlots; n++ {
+		if i := col(nppesColTaxonomy + strconv.Itoa(n)); i >= 0 {
+			c.taxonomies = append(c.taxonomies, i)
+		}
+	}
+	if c.npi < 0 {
+		return nil, fmt.Errorf("NPPES header has no %q column", nppesColNPI)
+	}
+	return c, nil
+}
+
+// requireColumns checks that the columns the criteria filter on exist.
+func (c *nppesColumns) requireColumns(crit NPPESCriteria) error {
+	switch {
+	case len(crit.Taxonomies) > 0 && len(c.taxonomies) == 0:
+		return fmt.Errorf("NPPES header has no %q columns", nppesColTaxonomy+"N")
+	case len(crit.States) > 0 && c.state < 0:
+		return fmt.Errorf("NPPES header has no %q column", nppesColState)
+	case len(crit.ZIPPrefixes) > 0 && c.postalCode < 0:
+		return fmt.Errorf("NPPES header has no %q column", nppesColPostalCode)
+	case len(crit.OrgNames) > 0 && c.orgName < 0:
+		return fmt.Errorf("NPPES header has no %q column", nppesColOrgName)
+	}
+	return nil
+}
+
+func csvField(rec []string, i int) string {
+	if i < 0 || i >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[i])
+}
+
+// BuildNPIFilterFromNPPES streams an NPPES bulk CSV and returns the active
+// providers matching crit. Deactivated NPIs without a later reactivation
+// are skipped.
+func BuildNPIFilterFromNPPES(r io.Reader, crit NPPESCriteria) ([]NPPESProvider, error) {
+	if crit.Empty() {
+		return nil, errors.New("no NPPES criteria given (taxonomy, state, ZIP prefix or organization name)")
+	}
+
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read NPPES header: %w", err)
+	}
+	cols, err := findNPPESColumns(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := cols.requireColumns(crit); err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]bool, len(crit.States))
+	for _, s := range crit.States {
+		states[strings.ToUpper(strings.TrimSpace(s))] = true
+	}
+	orgNames := make([]string, len(crit.OrgNames))
+	for i, s := range crit.OrgNames {
+		orgNames[i] = strings.ToLower(strings.TrimSpace(s))
+	}
+
+	var providers []NPPESProvider
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read NPPES line %d: %w", line, err)
+		}
+
+		if csvField(rec, cols.deactivated) != "" && csvField(rec, cols.reactivated) == "" {
+			continue
+		}
+		if len(states) > 0 && !states[strings.ToUpper(csvField(rec, cols.state))] {
+			continue
+		}
+		if len(crit.ZIPPrefixes) > 0 && !hasAnyPrefix(csvField(rec, cols.postalCode), crit.ZIPPrefixes) {
+			continue
+		}
+		if len(crit.Taxonomies) > 0 {
+			matched := false
+			for _, i := range cols.taxonomies {
+				if code := csvField(rec, i); code != "" && hasAnyPrefix(code, crit.Taxonomies) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		if len(orgNames) > 0 {
+			names := strings.ToLower(csvField(rec, cols.orgName) + "\x00" + csvField(rec, cols.otherOrgName))
+			matched := false
+			for _, s := range orgNames {
+				if s != "" && strings.Contains(names, s) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+
+		npi, err := strconv.ParseInt(csvField(rec, cols.npi), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("NPPES line %d: invalid NPI %q: %w", line, csvField(rec, cols.npi), err)
+		}
+		providers = append(providers, NPPESProvider{NPI: npi, Name: nppesName(rec, cols)})
+	}
+	return providers, nil
+}
+
+// nppesName returns the organization name, or "First Last" for individuals.
+func nppesName(rec []string, cols *nppesColumns) string {
+	if csvField(rec, cols.entityType) == nppesEntityIndividual {
+		return strings.TrimSpace(csvField(rec, cols.firstName) + " " + csvField(rec, cols.lastName))
+	}
+	return csvField(rec, cols.orgName)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, strings.TrimSpace(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NPIFilterSet converts selected providers to the set SetNPIFilter takes.
+func NPIFilterSet(providers []NPPESProvider) map[int64]bool {
+	filter := make(map[int64]bool, len(providers))
+	for _, p := range providers {
+		filter[p.NPI] = true
+	}
+	return filter
+}
+
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const nppesFixture = "testdata/nppes_sample.csv"
+
+func buildFromFixture(t *testing.T, crit NPPESCriteria) []int64 {
+	t.Helper()
+	f, err := os.Open(nppesFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	providers, err := BuildNPIFilterFromNPPES(f, crit)
+	if err != nil {
+		t.Fatalf("BuildNPIFilterFromNPPES: %v", err)
+	}
+	npis := make([]int64, len(providers))
+	for i, p := range providers {
+		npis[i] = p.NPI
+	}
+	sort.Slice(npis, func(i, j int) bool { return npis[i] < npis[j] })
+	return npis
+}
+
+func TestBuildNPIFilterFromNPPES(t *testing.T) {
+	tests := []struct {
+		name string
+		crit NPPESCriteria
+		want []int64
+	}{
+		// 4444444444 is deactivated; 5555555555 was reactivated.
+		{"taxonomy prefix", NPPESCriteria{Taxonomies: []string{"207R"}}, []int64{1111111111, 3333333333, 5555555555}},
+		{"exact taxonomy any slot", NPPESCriteria{Taxonomies: []string{"207RC0000X"}}, []int64{3333333333}},
+		{"state", NPPESCriteria{States: []string{"ny"}}, []int64{1111111111, 2222222222, 5555555555}},
+		{"zip prefix", NPPESCriteria{ZIPPrefixes: []string{"100", "070"}}, []int64{1111111111, 2222222222, 6666666666}},
+		{"org name matches other name", NPPESCriteria{OrgNames: []string{"midland"}}, []int64{2222222222, 6666666666}},
+		{"criteria are ANDed", NPPESCriteria{Taxonomies: []string{"207R"}, States: []string{"NY", "NJ"}}, []int64{1111111111, 5555555555}},
+		{"no match", NPPESCriteria{States: []string{"TX"}}, []int64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildFromFixture(t, tt.crit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildNPIFilterFromNPPESErrors(t *testing.T) {
+	if _, err := BuildNPIFilterFromNPPES(strings.NewReader("NPI\n1\n"), NPPESCriteria{}); err == nil {
+		t.Error("expected error for empty criteria")
+	}
+	if _, err := BuildNPIFilterFromNPPES(strings.NewReader("Name\nx\n"), NPPESCriteria{States: []string{"NY"}}); err == nil {
+		t.Error("expected error for missing NPI column")
+	}
+	_, err := BuildNPIFilterFromNPPES(strings.NewReader("NPI\n1\n"), NPPESCriteria{States: []string{"NY"}})
+	if err == nil || !strings.Contains(err.Error(), "State Name") {
+		t.Errorf("expected missing state column error, got %v", err)
+	}
+}
+
+func TestNPPESFilterRoundTrip(t *testing.T) {
+	f, err := os.Open(nppesFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	providers, err := BuildNPIFilterFromNPPES(f, NPPESCriteria{States: []string{"NY"}})
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[int64]string{}
+	for _, p := range providers {
+		names[p.NPI] = p.Name
+	}
+	if names[1111111111] != "JANE SMITH" || names[2222222222] != "MIDLAND MEDICAL GROUP" {
+		t.Errorf("names = %v", names)
+	}
+
+	// The saved allowlist loads back through -npi.
+	path := filepath.Join(t.TempDir(), "npis.json")
+	if err := SaveNPIFilter(path, providers); err != nil {
+		t.Fatalf("SaveNPIFilter: %v", err)
+	}
+	filter, err := LoadNPIFilter(path)
+	if err != nil {
+		t.Fatalf("LoadNPIFilter: %v", err)
+	}
+	if len(filter) != 3 || !filter[5555555555] || filter[4444444444] {
+		t.Errorf("loaded filter = %v", filter)
+	}
+
+	// And drives SetNPIFilter: only the two NY NPIs in the sample remain.
+	_, providerRows := convertTestFileWithNPIFilter(t,
+		"in-network-rates-fee-for-service-single-plan-sample.json", NPIFilterSet(providers))
+	seen := map[int64]bool{}
+	for _, p := range providerRows {
+		seen[p.NPI] = true
+	}
+	if len(seen) != 3 || !seen[1111111111] || !seen[2222222222] || !seen[5555555555] {
+		t.Errorf("provider NPIs = %v", seen)
+	}
+}
@@ -1,100 +1,217 @@
 package main
 
-import (
-	"fmt"
-	"os"
-
-	"github.com/parquet-go/parquet-go"
-)
-
 const flushInterval = 100_000
 
-// RateParquetWriter writes rate rows to a Parquet file.
+// RateParquetWriter writes rate rows to a Parquet file, or to a directory
+// of size-capped, optionally Hive-partitioned part files.
 type RateParquetWriter struct {
-	file   *os.File
-	writer *parquet.GenericWriter[RateRow]
-	count  int
+	w *rollingWriter[RateRow]
 }
 
 // NewRateParquetWriter creates a new Parquet writer for rate rows.
 func NewRateParquetWriter(path string) (*RateParquetWriter, error) {
-	file, err := os.Create(path)
+	w, err := newSingleFileWriter[RateRow]("rate", path)
 	if err != nil {
-		return nil, fmt.Errorf("create rate parquet: %w", err)
+		return nil, err
 	}
-	writer := parquet.NewGenericWriter[RateRow](file,
-		parquet.Compression(&parquet.Snappy),
-	)
-	return &RateParquetWriter{file: file, writer: writer}, nil
+	return &RateParquetWriter{w: w}, nil
 }
 
-// Write writes a single rate row.
-func (w *RateParquetWriter) Write(row RateRow) error {
-	if _, err := w.writer.Write([]RateRow{row}); err != nil {
-		return fmt.Errorf("write rate row: %w", err)
-	}
-	w.count++
-	if w.count%flushInterval == 0 {
-		if err := w.writer.Flush(); err != nil {
-			return fmt.Errorf("flush rates: %w", err)
-		}
+// NewPartitionedRateWriter creates a rate writer that writes part files
+// under dir according to opts, plus a _manifest.json listing every part.
+func NewPartitionedRateWriter(dir string, opts PartitionOptions) (*RateParquetWriter, error) {
+	w, err := newPartitionedWriter[RateRow]("rate", dir, opts, opts.ratePartition)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &RateParquetWriter{w: w}, nil
 }
 
+// Write writes a single rate row.
+func (w *RateParquetWriter) Write(row RateRow) error { return w.w.Write(row) }
+
 // Close flushes and closes the writer.
-func (w *RateParquetWriter) Close() error {
-	if err := w.writer.Close(); err != nil {
-		w.file.Close()
-		return fmt.Errorf("close rate writer: %w", err)
-	}
-	return w.file.Close()
-}
+func (w *RateParquetWriter) Close() error { return w.w.Close() }
 
 // Count returns the number of rows written.
-func (w *RateParquetWriter) Count() int { return w.count }
+func (w *RateParquetWriter) Count() int { return w.w.count }
+
+// Manifest returns the part files written so far (directory mode only).
+func (w *RateParquetWriter) Manifest() Manifest { return w.w.Manifest() }
 
-// ProviderParquetWriter writes provider rows to a Parquet file.
+// ProviderParquetWriter writes provider rows to a Parquet file, or to a
+// directory of size-capped part files.
 type ProviderParquetWriter struct {
-	file   *os.File
-	writer *parquet.GenericWriter[ProviderRow]
-	count  int
+	w *rollingWriter[ProviderRow]
 }
 
 // NewProviderParquetWriter creates a new Parquet writer for provider rows.
 func NewProviderParquetWriter(path string) (*ProviderParquetWriter, error) {
-	file, err := os.Create(path)
+	w, err := newSingleFileWriter[ProviderRow]("provider", path)
 	if err != nil {
-		return nil, fmt.Errorf("create provider parquet: %w", err)
+		return nil, err
 	}
-	writer := parquet.NewGenericWriter[ProviderRow](file,
-		parquet.Compression(&parquet.Snappy),
-	)
-	return &ProviderParquetWriter{file: file, writer: writer}, nil
+	return &ProviderParquetWriter{w: w}, nil
+}
+
+// NewPartitionedProviderWriter creates a provider writer that rolls over to
+// a new part file under dir when opts.MaxRows or opts.MaxBytes is reached.
+// Partition keys do not apply to provider rows and are ignored.
+func NewPartitionedProviderWriter(dir string, opts PartitionOptions) (*ProviderParquetWriter, error) {
+	opts.PartitionBy = nil
+	w, err := newPartitionedWriter[ProviderRow]("provider", dir, opts, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ProviderParquetWriter{w: w}, nil
 }
 
 // Write writes a single provider row.
-func (w *ProviderParquetWriter) Write(row ProviderRow) error {
-	if _, err := w.writer.Write([]ProviderRow{row}); err != nil {
-		return fmt.Errorf("write provider row: %w", err)
+func (w *ProviderParquetWriter) Write(row ProviderRow) error { return w.w.Write(row) }
+
+// Close flushes and closes the writer.
+func (w *ProviderParquetWriter) Close() error { return w.w.Close() }
+
+// Count returns the number of rows written.
+func (w *ProviderParquetWriter) Count() int { return w.w.count }
+
+// Manifest returns the part files written so far (directory mode only).
+func (w *ProviderParquetWriter) Manifest() Manifest { return w.w.Manifest() }
+
+// ItemParquetWriter writes in-network item rows to a Parquet file.
+type ItemParquetWriter struct {
+	w *rollingWriter[ItemRow]
+}
+
+// NewItemParquetWriter creates a new Parquet writer for item rows.
+func NewItemParquetWriter(path string) (*ItemParquetWriter, error) {
+	w, err := newSingleFileWriter[ItemRow]("item", path)
+	if err != nil {
+		return nil, err
 	}
-	w.count++
-	if w.count%flushInterval == 0 {
-		if err := w.writer.Flush(); err != nil {
-			return fmt.Errorf("flush providers: %w", err)
-		}
+	return &ItemParquetWriter{w: w}, nil
+}
+
+// NewPartitionedItemWriter creates an item writer that rolls over to a new
+// part file under dir when opts.MaxRows or opts.MaxBytes is reached.
+func NewPartitionedItemWriter(dir string, opts PartitionOptions) (*ItemParquetWriter, error) {
+	opts.PartitionBy = nil
+	w, err := newPartitionedWriter[ItemRow]("item", dir, opts, nil)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &ItemParquetWriter{w: w}, nil
 }
 
+// Write writes a single item row.
+func (w *ItemParquetWriter) Write(row ItemRow) error { return w.w.Write(row) }
+
 // Close flushes and closes the writer.
-func (w *ProviderParquetWriter) Close() error {
-	if err := w.writer.Close(); err != nil {
-		w.file.Close()
-		return fmt.Errorf("close provider writer: %w", err)
+func (w *ItemParquetWriter) Close() error { return w.w.Close() }
+
+// Count returns the number of rows written.
+func (w *ItemParquetWriter) Count() int { return w.w.count }
+
+// ContainedCodeParquetWriter writes bundled/covered code rows to a Parquet file.
+type ContainedCodeParquetWriter struct {
+	w *rollingWriter[ContainedCodeRow]
+}
+
+// NewContainedCodeParquetWriter creates a new Parquet writer for contained code rows.
+func NewContainedCodeParquetWriter(path string) (*ContainedCodeParquetWriter, error) {
+	w, err := newSingleFileWriter[ContainedCodeRow]("contained code", path)
+	if err != nil {
+		return nil, err
+	}
+	return &ContainedCodeParquetWriter{w: w}, nil
+}
+
+// NewPartitionedContainedCodeWriter creates a contained code writer that
+// rolls over to a new part file under dir when a cap in opts is reached.
+func NewPartitionedContainedCodeWriter(dir string, opts PartitionOptions) (*ContainedCodeParquetWriter, error) {
+	opts.PartitionBy = nil
+	w, err := newPartitionedWriter[ContainedCodeRow]("contained code", dir, opts, nil)
+	if err != nil {
+		return nil, err
 	}
-	return w.file.Close()
+	return &ContainedCodeParquetWriter{w: w}, nil
 }
 
+// Write writes a single contained code row.
+func (w *ContainedCodeParquetWriter) Write(row ContainedCodeRow) error { return w.w.Write(row) }
+
+// Close flushes and closes the writer.
+func (w *ContainedCodeParquetWriter) Close() error { return w.w.Close() }
+
+// Count returns the number of rows written.
+func (w *ContainedCodeParquetWriter) Count() int { return w.w.count }
+
+// PlanParquetWriter writes plan bridge rows to a Parquet file.
+type PlanParquetWriter struct {
+	w *rollingWriter[PlanRow]
+}
+
+// NewPlanParquetWriter creates a new Parquet writer for plan rows. The
+// bridge is small, so it is always a single file.
+func NewPlanParquetWriter(path string) (*PlanParquetWriter, error) {
+	w, err := newSingleFileWriter[PlanRow]("plan", path)
+	if err != nil {
+		return nil, err
+	}
+	return &PlanParquetWriter{w: w}, nil
+}
+
+// Write writes a single plan row.
+func (w *PlanParquetWriter) Write(row PlanRow) error { return w.w.Write(row) }
+
+// Close flushes and closes the writer.
+func (w *PlanParquetWriter) Close() error { return w.w.Close() }
+
+// Count returns the number of rows written.
+func (w *PlanParquetWriter) Count() int { return w.w.count }
+
+// NetworkParquetWriter writes network membership rows to a Parquet file.
+type NetworkParquetWriter struct {
+	w *rollingWriter[NetworkRow]
+}
+
+// NewNetworkParquetWriter creates a new Parquet writer for network rows.
+func NewNetworkParquetWriter(path string) (*NetworkParquetWriter, error) {
+	w, err := newSingleFileWriter[NetworkRow]("network", path)
+	if err != nil {
+		return nil, err
+	}
+	return &NetworkParquetWriter{w: w}, nil
+}
+
+// Write writes a single network row.
+func (w *NetworkParquetWriter) Write(row NetworkRow) error { return w.w.Write(row) }
+
+// Close flushes and closes the writer.
+func (w *NetworkParquetWriter) Close() error { return w.w.Close() }
+
+// Count returns the number of rows written.
+func (w *NetworkParquetWriter) Count() int { return w.w.count }
+
+// SummaryParquetWriter writes rate summary rows to a Parquet file.
+type SummaryParquetWriter struct {
+	w *rollingWriter[SummaryRow]
+}
+
+// NewSummaryParquetWriter creates a new Parquet writer for summary rows.
+func NewSummaryParquetWriter(path string) (*SummaryParquetWriter, error) {
+	w, err := newSingleFileWriter[SummaryRow]("summary", path)
+	if err != nil {
+		return nil, err
+	}
+	return &SummaryParquetWriter{w: w}, nil
+}
+
+// Write writes a single summary row.
+func (w *SummaryParquetWriter) Write(row SummaryRow) error { return w.w.Write(row) }
+
+// Close flushes and closes the writer.
+func (w *SummaryParquetWriter) Close() error { return w.w.Close() }
+
 // Count returns the number of rows written.
-func (w *ProviderParquetWriter) Count() int { return w.count }
+func (w *SummaryParquetWriter) Count() int { return w.w.count }
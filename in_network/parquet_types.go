@@ -1,7 +1,20 @@
 package main
 
+import (
+	"slices"
+
+	"github.com/parquet-go/parquet-go"
+)
+
 // RateRow is the Parquet schema for denormalized negotiated rates.
 // One row per negotiated price, with all parent metadata denormalized.
+// ExpirationDate is a DATE held as days since 1970-01-01 (see
+// RateRow.Expiration and rateSchema); it is nil when the price never
+// expires (NoExpiration, the 9999-12-31 sentinel), when the source value
+// is missing or empty, or when it could not be parsed (kept in
+// ExpirationDateRaw). RateUnit says whether NegotiatedRate is dollars,
+// dollars per day or a percentage of billed charges; BillingCodeModifier
+// qualifies BillingCode, which for a bundle is the bundle code.
 type RateRow struct {
 	ReportingEntityName    string   `parquet:"reporting_entity_name"`
 	ReportingEntityType    string   `parquet:"reporting_entity_type"`
@@ -13,17 +26,22 @@ type RateRow struct {
 	PlanMarketType         *string  `parquet:"plan_market_type,optional"`
 	LastUpdatedOn          string   `parquet:"last_updated_on"`
 	Version                string   `parquet:"version"`
+	ItemID                 int64    `parquet:"item_id"`
 	NegotiationArrangement string   `parquet:"negotiation_arrangement"`
 	Name                   string   `parquet:"name"`
 	BillingCodeType        string   `parquet:"billing_code_type"`
 	BillingCodeTypeVersion string   `parquet:"billing_code_type_version"`
+	SeverityOfIllness      *string  `parquet:"severity_of_illness,optional"`
 	BillingCode            string   `parquet:"billing_code"`
 	Description            string   `parquet:"description"`
 	NegotiatedRate         float64  `parquet:"negotiated_rate"`
 	NegotiatedType         string   `parquet:"negotiated_type"`
+	RateUnit               string   `parquet:"rate_unit"`
 	BillingClass           string   `parquet:"billing_class"`
 	Setting                string   `parquet:"setting"`
-	ExpirationDate         string   `parquet:"expiration_date"`
+	ExpirationDate         *int32   `parquet:"expiration_date,optional"`
+	NoExpiration           bool     `parquet:"no_expiration"`
+	ExpirationDateRaw      *string  `parquet:"expiration_date_raw,optional"`
 	ServiceCode            []string `parquet:"service_code,list,optional"`
 	BillingCodeModifier    []string `parquet:"billing_code_modifier,list,optional"`
 	AdditionalInformation  *string  `parquet:"additional_information,optional"`
@@ -32,6 +50,36 @@ type RateRow struct {
 	CoveredServicesJSON    *string  `parquet:"covered_services_json,optional"`
 }
 
+// rateSchema is the schema rate rows are written and read with: RateRow's,
+// with expiration_date annotated as a DATE. parquet-go only takes the date tag
+// on a plain int32 (or on time.Time, which it writes wrongly), and a plain
+// int32 could not tell 1970-01-01 from NULL.
+var rateSchema = func() *parquet.Schema {
+	s := parquet.SchemaOf(RateRow{})
+	return parquet.NewSchema(s.Name(), dateColumn{Node: s, name: "expiration_date"})
+}()
+
+// dateColumn is a group node whose int32 field called name is typed as a
+// DATE.
+type dateColumn struct {
+	parquet.Node
+	name string
+}
+
+func (n dateColumn) Fields() []parquet.Field {
+	fields := slices.Clone(n.Node.Fields())
+	for i, f := range fields {
+		if f.Name() == n.name {
+			fields[i] = dateField{f}
+		}
+	}
+	return fields
+}
+
+type dateField struct{ parquet.Field }
+
+func (dateField) Type() parquet.Type { return parquet.Date().Type() }
+
 // ProviderRow is the Parquet schema for provider reference data.
 // One row per (provider_group_id, NPI) combination.
 type ProviderRow struct {
@@ -42,3 +90,84 @@ type ProviderRow struct {
 	BusinessName    *string  `parquet:"business_name,optional"`
 	NetworkNames    []string `parquet:"network_names,list,optional"`
 }
+
+// ItemRow is the Parquet schema for in-network items.
+// One row per in_network entry; rate rows reference it by item_id.
+type ItemRow struct {
+	ItemID                 int64   `parquet:"item_id"`
+	NegotiationArrangement string  `parquet:"negotiation_arrangement"`
+	Name                   string  `parquet:"name"`
+	BillingCodeType        string  `parquet:"billing_code_type"`
+	BillingCodeTypeVersion string  `parquet:"billing_code_type_version"`
+	SeverityOfIllness      *string `parquet:"severity_of_illness,optional"`
+	BillingCode            string  `parquet:"billing_code"`
+	Description            string  `parquet:"description"`
+	BundledCodeCount       int32   `parquet:"bundled_code_count"`
+	CoveredServiceCount    int32   `parquet:"covered_service_count"`
+}
+
+// Contained code relations in ContainedCodeRow.Relation.
+const (
+	RelationBundled = "bundled"
+	RelationCovered = "covered"
+)
+
+// ContainedCodeRow is the Parquet schema for bundled_codes and
+// covered_services. One row per contained code, keyed by item_id.
+type ContainedCodeRow struct {
+	ItemID                 int64  `parquet:"item_id"`
+	Relation               string `parquet:"relation"` // bundled | covered
+	BillingCodeType        string `parquet:"billing_code_type"`
+	BillingCodeTypeVersion string `parquet:"billing_code_type_version"`
+	BillingCode            string `parquet:"billing_code"`
+	Description            string `parquet:"description"`
+}
+
+// PlanRow is the Parquet schema for the plan bridge built from a TOC
+// extraction (mrfparser output). One row per plan whose reporting
+// structure lists the converted file. InNetworkFile is the output base
+// name, so <in_network_file>_rates.parquet holds that file's rates.
+type PlanRow struct {
+	InNetworkFile  string `parquet:"in_network_file"`
+	SourceURL      string `parquet:"source_url"`
+	PlanName       string `parquet:"plan_name"`
+	IssuerName     string `parquet:"issuer_name"`
+	PlanIDType     string `parquet:"plan_id_type"`
+	PlanID         string `parquet:"plan_id"`
+	PlanMarketType string `parquet:"plan_market_type"`
+	Description    string `parquet:"description"`
+}
+
+// NetworkRow is the Parquet schema for provider network membership. One
+// row per (network_name, provider_group_id, NPI, TIN) from provider rows
+// whose provider_references entry names networks. GroupRateCount counts
+// rate rows referencing the provider group; NetworkRateCount counts rate
+// rows referencing any group in the network.
+type NetworkRow struct {
+	NetworkName      string `parquet:"network_name"`
+	ProviderGroupID  int32  `parquet:"provider_group_id"`
+	NPI              int64  `parquet:"npi"`
+	TINType          string `parquet:"tin_type"`
+	TINValue         string `parquet:"tin_value"`
+	GroupRateCount   int64  `parquet:"group_rate_count"`
+	NetworkRateCount int64  `parquet:"network_rate_count"`
+}
+
+// SummaryRow is the Parquet schema for per-code rate distributions. One
+// row per (billing_code_type, billing_code, negotiated_type, billing_class,
+// setting). Quantiles come from Sketch, a mergeable DDSketch with 1%
+// relative accuracy; min, max and count are exact.
+type SummaryRow struct {
+	BillingCodeType string  `parquet:"billing_code_type"`
+	BillingCode     string  `parquet:"billing_code"`
+	NegotiatedType  string  `parquet:"negotiated_type"`
+	BillingClass    string  `parquet:"billing_class"`
+	Setting         string  `parquet:"setting"`
+	Count           int64   `parquet:"count"`
+	Min             float64 `parquet:"min"`
+	P10             float64 `parquet:"p10"`
+	Median          float64 `parquet:"median"`
+	P90             float64 `parquet:"p90"`
+	Max             float64 `parquet:"max"`
+	Sketch          []byte  `parquet:"sketch"`
+}
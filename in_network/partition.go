@@ -0,0 +1,400 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Partition keys accepted by PartitionOptions.PartitionBy.
+const (
+	PartitionBillingCodeType   = "billing_code_type"
+	PartitionBillingCodePrefix = "billing_code_prefix"
+)
+
+// hiveDefaultPartition is the Hive convention for an empty partition value.
+const hiveDefaultPartition = "__HIVE_DEFAULT_PARTITION__"
+
+// manifestName is the manifest file written at the root of a part directory.
+const manifestName = "_manifest.json"
+
+// defaultMaxOpenParts bounds the number of part files held open at once
+// when writing many Hive partitions.
+const defaultMaxOpenParts = 64
+
+// PartitionOptions controls size-capped and Hive-partitioned output.
+// The zero value writes everything to a single file.
+type PartitionOptions struct {
+	MaxRows      int64    // start a new part after this many rows (0 = unlimited)
+	MaxBytes     int64    // start a new part after roughly this many bytes (0 = unlimited)
+	PartitionBy  []string // Hive partition keys, in directory order
+	PrefixLen    int      // billing_code_prefix length (default 2)
+	MaxOpenParts int      // open part files before the least recently used is closed (default 64)
+}
+
+// Enabled reports whether any rollover or partitioning is requested.
+func (o PartitionOptions) Enabled() bool {
+	return o.MaxRows > 0 || o.MaxBytes > 0 || len(o.PartitionBy) > 0
+}
+
+// Validate checks the partition keys and limits.
+func (o PartitionOptions) Validate() error {
+	if o.MaxRows < 0 || o.MaxBytes < 0 {
+		return fmt.Errorf("part limits must not be negative")
+	}
+	seen := make(map[string]bool)
+	for _, k := range o.PartitionBy {
+		switch k {
+		case PartitionBillingCodeType, PartitionBillingCodePrefix:
+		default:
+			return fmt.Errorf("unknown partition key %q (want %s or %s)",
+				k, PartitionBillingCodeType, PartitionBillingCodePrefix)
+		}
+		if seen[k] {
+			return fmt.Errorf("duplicate partition key %q", k)
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+// ParsePartitionKeys splits a comma-separated -partition flag value.
+func ParsePartitionKeys(s string) []string {
+	return splitList(s)
+}
+
+// ratePartition returns the Hive partition path for a rate row,
+// e.g. "billing_code_type=CPT/billing_code_prefix=99".
+func (o PartitionOptions) ratePartition(r *RateRow) string {
+	if len(o.PartitionBy) == 0 {
+		return ""
+	}
+	prefixLen := o.PrefixLen
+	if prefixLen <= 0 {
+		prefixLen = 2
+	}
+	segs := make([]string, 0, len(o.PartitionBy))
+	for _, k := range o.PartitionBy {
+		var v string
+		switch k {
+		case PartitionBillingCodeType:
+			v = r.BillingCodeType
+		case PartitionBillingCodePrefix:
+			v = r.BillingCode
+			if len(v) > prefixLen {
+				v = v[:prefixLen]
+			}
+		}
+		segs = append(segs, k+"="+escapePartitionValue(v))
+	}
+	return strings.Join(segs, "/")
+}
+
+// escapePartitionValue percent-encodes characters that are unsafe in a
+// Hive partition directory name.
+func escapePartitionValue(v string) string {
+	if v == "" {
+		return hiveDefaultPartition
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// Manifest lists the part files written to a part directory.
+type Manifest struct {
+	CreatedAt string         `json:"created_at"`
+	TotalRows int64          `json:"total_rows"`
+	Parts     []ManifestPart `json:"parts"`
+}
+
+// ManifestPart describes one Parquet part file.
+type ManifestPart struct {
+	Path      string            `json:"path"` // relative to the manifest
+	Partition map[string]string `json:"partition,omitempty"`
+	Rows      int64             `json:"rows"`
+	Bytes     int64             `json:"bytes"`
+}
+
+// countingWriter counts bytes written to the underlying file.
+type countingWriter struct {
+	f *os.File
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.f.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// partFile is one open Parquet part.
+type partFile[T any] struct {
+	partition string
+	path      string
+	out       *countingWriter
+	writer    *parquet.GenericWriter[T]
+	rows      int64
+	lastUsed  int64
+}
+
+// rollingWriter writes rows to one or more Parquet files. In single-file
+// mode it behaves like a plain GenericWriter. In directory mode it keeps
+// one open part per partition and starts a new part when a row or byte cap
+// is reached.
+//
+// Byte caps are checked against row groups already flushed to disk, so a
+// part may exceed MaxBytes by up to one row group.
+type rollingWriter[T any] struct {
+	label       string // "rate" or "provider", for error messages
+	root        string // directory root (directory mode) or output file (single mode)
+	dirMode     bool
+	opts        PartitionOptions
+	partitionOf func(*T) string
+
+	open     map[string]*partFile[T]
+	seq      map[string]int
+	finished []ManifestPart
+	count    int
+	tick     int64
+}
+
+func newSingleFileWriter[T any](label, path string) (*rollingWriter[T], error) {
+	w := &rollingWriter[T]{
+		label: label,
+		root:  path,
+		open:  make(map[string]*partFile[T]),
+		seq:   make(map[string]int),
+	}
+	// Create eagerly so open errors surface at construction, as before.
+	if _, err := w.part(""); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func newPartitionedWriter[T any](label, dir string, opts PartitionOptions, partitionOf func(*T) string) (*rollingWriter[T], error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.MaxOpenParts <= 0 {
+		opts.MaxOpenParts = defaultMaxOpenParts
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create %s directory: %w", label, err)
+	}
+	return &rollingWriter[T]{
+		label:       label,
+		root:        dir,
+		dirMode:     true,
+		opts:        opts,
+		partitionOf: partitionOf,
+		open:        make(map[string]*partFile[T]),
+		seq:         make(map[string]int),
+	}, nil
+}
+
+// rowSchema returns the schema rows of type T are written and read with.
+func rowSchema[T any]() *parquet.Schema {
+	if _, ok := any(new(T)).(*RateRow); ok {
+		return rateSchema
+	}
+	return parquet.SchemaOf(new(T))
+}
+
+// part returns the open part for a partition, creating it if needed.
+func (w *rollingWriter[T]) part(partition string) (*partFile[T], error) {
+	if p, ok := w.open[partition]; ok {
+		return p, nil
+	}
+	if w.dirMode && len(w.open) >= w.opts.MaxOpenParts {
+		if err := w.closeLRU(); err != nil {
+			return nil, err
+		}
+	}
+
+	path := w.root
+	if w.dirMode {
+		w.seq[partition]++
+		name := fmt.Sprintf("part-%04d.parquet", w.seq[partition])
+		path = filepath.Join(w.root, filepath.FromSlash(partition), name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("create %s partition: %w", w.label, err)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create %s parquet: %w", w.label, err)
+	}
+	out := &countingWriter{f: file}
+	p := &partFile[T]{
+		partition: partition,
+		path:      path,
+		out:       out,
+		writer: parquet.NewGenericWriter[T](out,
+			parquet.Compression(&parquet.Snappy),
+			rowSchema[T](),
+		),
+	}
+	w.open[partition] = p
+	return p, nil
+}
+
+// Write writes a single row, rolling over to a new part when needed.
+func (w *rollingWriter[T]) Write(row T) error {
+	partition := ""
+	if w.partitionOf != nil {
+		partition = w.partitionOf(&row)
+	}
+	p, err := w.part(partition)
+	if err != nil {
+		return err
+	}
+	if _, err := p.writer.Write([]T{row}); err != nil {
+		return fmt.Errorf("write %s row: %w", w.label, err)
+	}
+	p.rows++
+	w.count++
+	w.tick++
+	p.lastUsed = w.tick
+
+	if p.rows%w.flushEvery() == 0 {
+		if err := p.writer.Flush(); err != nil {
+			return fmt.Errorf("flush %ss: %w", w.label, err)
+		}
+	}
+	if (w.opts.MaxRows > 0 && p.rows >= w.opts.MaxRows) ||
+		(w.opts.MaxBytes > 0 && p.out.n >= w.opts.MaxBytes) {
+		return w.closePart(p)
+	}
+	return nil
+}
+
+// flushEvery returns the row-group size. Byte caps flush more often so
+// the on-disk size tracks the cap more closely.
+func (w *rollingWriter[T]) flushEvery() int64 {
+	if w.opts.MaxBytes > 0 {
+		return flushInterval / 10
+	}
+	return flushInterval
+}
+
+func (w *rollingWriter[T]) closeLRU() error {
+	var oldest *partFile[T]
+	for _, p := range w.open {
+		if oldest == nil || p.lastUsed < oldest.lastUsed {
+			oldest = p
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+	return w.closePart(oldest)
+}
+
+func (w *rollingWriter[T]) closePart(p *partFile[T]) error {
+	delete(w.open, p.partition)
+	if err := p.writer.Close(); err != nil {
+		p.out.f.Close()
+		return fmt.Errorf("close %s writer: %w", w.label, err)
+	}
+	if err := p.out.f.Close(); err != nil {
+		return fmt.Errorf("close %s file: %w", w.label, err)
+	}
+	if w.dirMode {
+		rel, err := filepath.Rel(w.root, p.path)
+		if err != nil {
+			rel = p.path
+		}
+		w.finished = append(w.finished, ManifestPart{
+			Path:      filepath.ToSlash(rel),
+			Partition: parsePartition(p.partition),
+			Rows:      p.rows,
+			Bytes:     p.out.n,
+		})
+	}
+	return nil
+}
+
+// Close closes all open parts and, in directory mode, writes the manifest.
+func (w *rollingWriter[T]) Close() error {
+	// Close in a stable order so part listings are deterministic.
+	keys := make([]string, 0, len(w.open))
+	for k := range w.open {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var firstErr error
+	for _, k := range keys {
+		if err := w.closePart(w.open[k]); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil || !w.dirMode {
+		return firstErr
+	}
+	return w.writeManifest()
+}
+
+func (w *rollingWriter[T]) writeManifest() error {
+	m := w.Manifest()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal %s manifest: %w", w.label, err)
+	}
+	if err := os.WriteFile(filepath.Join(w.root, manifestName), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write %s manifest: %w", w.label, err)
+	}
+	return nil
+}
+
+// Manifest returns the parts finished so far, ordered by path.
+func (w *rollingWriter[T]) Manifest() Manifest {
+	parts := append([]ManifestPart(nil), w.finished...)
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Path < parts[j].Path })
+	var total int64
+	for _, p := range parts {
+		total += p.Rows
+	}
+	return Manifest{
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		TotalRows: total,
+		Parts:     parts,
+	}
+}
+
+// parsePartition turns "k1=v1/k2=v2" into a map of unescaped values.
+func parsePartition(partition string) map[string]string {
+	if partition == "" {
+		return nil
+	}
+	m := make(map[string]string)
+	for _, seg := range strings.Split(partition, "/") {
+		k, v, _ := strings.Cut(seg, "=")
+		if v == hiveDefaultPartition {
+			v = ""
+		} else if u, err := url.PathUnescape(v); err == nil {
+			v = u
+		}
+		m[k] = v
+	}
+	return m
+}
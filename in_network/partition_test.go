@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func convertPartitioned(t *testing.T, name string, opts PartitionOptions) (string, *ConvertStats) {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join(examplesDir, name))
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+
+	dir := t.TempDir()
+	rw, err := NewPartitionedRateWriter(filepath.Join(dir, "rates"), opts)
+	if err != nil {
+		t.Fatalf("rate writer: %v", err)
+	}
+	pw, err := NewProviderParquetWriter(filepath.Join(dir, "providers.parquet"))
+	if err != nil {
+		t.Fatalf("provider writer: %v", err)
+	}
+
+	stats, err := NewStreamConverter(f, false).Convert(rw, pw)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if err := rw.Close(); err != nil {
+		t.Fatalf("close rates: %v", err)
+	}
+	if err := pw.Close(); err != nil {
+		t.Fatalf("close providers: %v", err)
+	}
+	return filepath.Join(dir, "rates"), stats
+}
+
+func readManifest(t *testing.T, dir string) Manifest {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("parse manifest: %v", err)
+	}
+	return m
+}
+
+func TestPartitionedRatesMaxRows(t *testing.T) {
+	// 8 rate rows, 3 per part → 3 parts (3, 3, 2)
+	dir, stats := convertPartitioned(t, "in-network-rates-all-negotiated-types-sample.json",
+		PartitionOptions{MaxRows: 3})
+
+	m := readManifest(t, dir)
+	if len(m.Parts) != 3 {
+		t.Fatalf("parts = %d, want 3", len(m.Parts))
+	}
+	if m.TotalRows != stats.RateRows {
+		t.Errorf("manifest total_rows = %d, want %d", m.TotalRows, stats.RateRows)
+	}
+
+	wantRows := []int64{3, 3, 2}
+	var total int
+	for i, p := range m.Parts {
+		if p.Rows != wantRows[i] {
+			t.Errorf("part[%d] rows = %d, want %d", i, p.Rows, wantRows[i])
+		}
+		if p.Partition != nil {
+			t.Errorf("part[%d] partition = %v, want none", i, p.Partition)
+		}
+		rows := readRateRows(t, filepath.Join(dir, filepath.FromSlash(p.Path)))
+		if int64(len(rows)) != p.Rows {
+			t.Errorf("part[%d] file rows = %d, manifest says %d", i, len(rows), p.Rows)
+		}
+		total += len(rows)
+	}
+	if total != 8 {
+		t.Errorf("total rows = %d, want 8", total)
+	}
+	if m.Parts[0].Path != "part-0001.parquet" {
+		t.Errorf("part[0] path = %q, want part-0001.parquet", m.Parts[0].Path)
+	}
+}
+
+func TestPartitionedRatesHive(t *testing.T) {
+	dir, _ := convertPartitioned(t, "in-network-rates-all-negotiated-types-sample.json",
+		PartitionOptions{PartitionBy: []string{PartitionBillingCodeType, PartitionBillingCodePrefix}})
+
+	m := readManifest(t, dir)
+	if len(m.Parts) == 0 {
+		t.Fatal("expected partitioned parts")
+	}
+
+	var total int64
+	for _, p := range m.Parts {
+		typ := p.Partition[PartitionBillingCodeType]
+		prefix := p.Partition[PartitionBillingCodePrefix]
+		want := "billing_code_type=" + escapePartitionValue(typ) +
+			"/billing_code_prefix=" + escapePartitionValue(prefix) + "/part-0001.parquet"
+		if p.Path != want {
+			t.Errorf("path = %q, want %q", p.Path, want)
+		}
+
+		rows := readRateRows(t, filepath.Join(dir, filepath.FromSlash(p.Path)))
+		for _, r := range rows {
+			if r.BillingCodeType != typ {
+				t.Errorf("%s: billing_code_type = %q", p.Path, r.BillingCodeType)
+			}
+			if len(r.BillingCode) < 2 || r.BillingCode[:2] != prefix {
+				t.Errorf("%s: billing_code = %q, want prefix %q", p.Path, r.BillingCode, prefix)
+			}
+		}
+		total += int64(len(rows))
+	}
+	if total != 8 {
+		t.Errorf("total rows = %d, want 8", total)
+	}
+
+	// 27447 is a CPT code → billing_code_type=CPT/billing_code_prefix=27
+	if _, err := os.Stat(filepath.Join(dir, "billing_code_type=CPT", "billing_code_prefix=27", "part-0001.parquet")); err != nil {
+		t.Errorf("expected CPT/27 partition: %v", err)
+	}
+}
+
+func TestPartitionedRatesMaxOpenParts(t *testing.T) {
+	// With one open part, every partition switch closes the previous part.
+	dir, _ := convertPartitioned(t, "in-network-rates-all-negotiated-types-sample.json",
+		PartitionOptions{PartitionBy: []string{PartitionBillingCodePrefix}, MaxOpenParts: 1})
+
+	m := readManifest(t, dir)
+	var total int64
+	for _, p := range m.Parts {
+		total += p.Rows
+	}
+	if total != 8 {
+		t.Errorf("manifest rows = %d, want 8", total)
+	}
+}
+
+func TestEscapePartitionValue(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"CPT", "CPT"},
+		{"MS-DRG", "MS-DRG"},
+		{"", hiveDefaultPartition},
+		{"A/B", "A%2FB"},
+		{"a=b c", "a%3Db%20c"},
+	}
+	for _, tt := range tests {
+		if got := escapePartitionValue(tt.in); got != tt.want {
+			t.Errorf("escapePartitionValue(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if tt.in != "" {
+			got := parsePartition("k=" + escapePartitionValue(tt.in))["k"]
+			if got != tt.in {
+				t.Errorf("round trip %q = %q", tt.in, got)
+			}
+		}
+	}
+}
+
+func TestPartitionOptionsValidate(t *testing.T) {
+	if err := (PartitionOptions{PartitionBy: []string{"plan_id"}}).Validate(); err == nil {
+		t.Error("expected error for unknown partition key")
+	}
+	if err := (PartitionOptions{PartitionBy: []string{"billing_code_type", "billing_code_type"}}).Validate(); err == nil {
+		t.Error("expected error for duplicate partition key")
+	}
+	if err := (PartitionOptions{MaxRows: -1}).Validate(); err == nil {
+		t.Error("expected error for negative max rows")
+	}
+}
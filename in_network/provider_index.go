@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultGroupIndexMem is the number of distinct provider groups kept in
+// memory before the index spills to disk (~50 bytes per entry).
+const defaultGroupIndexMem = 2_000_000
+
+// groupKey is a 128-bit FNV-1a hash of a canonicalized provider group.
+type groupKey [16]byte
+
+// canonicalGroupKey hashes a provider group's sorted, de-duplicated NPIs
+// and its TIN, so the same group listed in a different NPI order hashes
+// identically.
+func canonicalGroupKey(pg ProviderGroup) groupKey {
+	npis := slices.Clone(pg.NPI)
+	slices.Sort(npis)
+	npis = slices.Compact(npis)
+
+	h := fnv.New128a()
+	var buf []byte
+	for _, npi := range npis {
+		buf = strconv.AppendInt(buf[:0], npi, 10)
+		buf = append(buf, ',')
+		h.Write(buf)
+	}
+	h.Write([]byte{0})
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(pg.TIN.Type))))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.TrimSpace(pg.TIN.Value)))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.TrimSpace(pg.TIN.BusinessName)))
+
+	var k groupKey
+	h.Sum(k[:0])
+	return k
+}
+
+// providerGroupIndex maps canonical group hashes to assigned provider group
+// IDs. The first memLimit entries live in a map; later entries go to an
+// on-disk open-addressing table so memory stays bounded on files with
+// hundreds of millions of distinct groups.
+type providerGroupIndex struct {
+	mem      map[groupKey]int32
+	memLimit int
+	tmpDir   string
+	disk     *diskGroupIndex
+	spilled  bool
+}
+
+func newProviderGroupIndex(memLimit int, tmpDir string) *providerGroupIndex {
+	if memLimit <= 0 {
+		memLimit = defaultGroupIndexMem
+	}
+	return &providerGroupIndex{
+		mem:      make(map[groupKey]int32),
+		memLimit: memLimit,
+		tmpDir:   tmpDir,
+	}
+}
+
+// Lookup returns the ID assigned to k, if any.
+func (x *providerGroupIndex) Lookup(k groupKey) (int32, bool, error) {
+	if id, ok := x.mem[k]; ok {
+		return id, true, nil
+	}
+	if x.disk == nil {
+		return 0, false, nil
+	}
+	return x.disk.Lookup(k)
+}
+
+// Insert records id for k. Callers must Lookup first; k must be new.
+func (x *providerGroupIndex) Insert(k groupKey, id int32) error {
+	if len(x.mem) < x.memLimit {
+		x.mem[k] = id
+		return nil
+	}
+	if x.disk == nil {
+		d, err := newDiskGroupIndex(x.tmpDir, 1<<16)
+		if err != nil {
+			return err
+		}
+		x.disk = d
+		x.spilled = true
+	}
+	return x.disk.Insert(k, id)
+}
+
+// Len returns the number of distinct groups indexed.
+func (x *providerGroupIndex) Len() int64 {
+	n := int64(len(x.mem))
+	if x.disk != nil {
+		n += x.disk.count
+	}
+	return n
+}
+
+// Spilled reports whether the index has overflowed to disk.
+func (x *providerGroupIndex) Spilled() bool { return x.spilled }
+
+// Close removes the on-disk table, if one was created.
+func (x *providerGroupIndex) Close() error {
+	if x.disk == nil {
+		return nil
+	}
+	err := x.disk.Close()
+	x.disk = nil
+	return err
+}
+
+// diskGroupIndex is a linear-probing hash table stored in a temp file.
+// Each slot holds a 16-byte key and a 4-byte ID; ID 0 marks an empty slot
+// (assigned IDs start at 1). The table doubles when half full.
+type diskGroupIndex struct {
+	file   *os.File
+	dir    string
+	slots  int64 // power of two
+	count  int64
+	window []byte
+}
+
+const (
+	diskSlotSize    = 20
+	diskProbeWindow = 16 // slots read per ReadAt while probing
+)
+
+func newDiskGroupIndex(dir string, slots int64) (*diskGroupIndex, error) {
+	f, err := os.CreateTemp(dir, "in_network-groups-*.idx")
+	if err != nil {
+		return nil, fmt.Errorf("create provider group index: %w", err)
+	}
+	if err := f.Truncate(slots * diskSlotSize); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, fmt.Errorf("size provider group index: %w", err)
+	}
+	return &diskGroupIndex{
+		file:   f,
+		dir:    dir,
+		slots:  slots,
+		window: make([]byte, diskProbeWindow*diskSlotSize),
+	}, nil
+}
+
+func (d *diskGroupIndex) home(k groupKey) int64 {
+	return int64(binary.LittleEndian.Uint64(k[:8]) & uint64(d.slots-1))
+}
+
+// probe walks the chain for k and returns either the slot holding k
+// (found=true) or the first empty slot.
+func (d *diskGroupIndex) probe(k groupKey) (slot int64, id int32, found bool, err error) {
+	slot = d.home(k)
+	for {
+		n := int64(diskProbeWindow)
+		if slot+n > d.slots {
+			n = d.slots - slot
+		}
+		buf := d.window[:n*diskSlotSize]
+		if _, err := d.file.ReadAt(buf, slot*diskSlotSize); err != nil && !errors.Is(err, io.EOF) {
+			return 0, 0, false, fmt.Errorf("read provider group index: %w", err)
+		}
+		for i := int64(0); i < n; i++ {
+			rec := buf[i*diskSlotSize : (i+1)*diskSlotSize]
+			id := int32(binary.LittleEndian.Uint32(rec[16:]))
+			if id == 0 {
+				return slot + i, 0, false, nil
+			}
+			if groupKey(rec[:16]) == k {
+				return slot + i, id, true, nil
+			}
+		}
+		slot = (slot + n) & (d.slots - 1)
+	}
+}
+
+func (d *diskGroupIndex) Lookup(k groupKey) (int32, bool, error) {
+	_, id, found, err := d.probe(k)
+	return id, found, err
+}
+
+func (d *diskGroupIndex) Insert(k groupKey, id int32) error {
+	if (d.count+1)*2 > d.slots {
+		if err := d.grow(); err != nil {
+			return err
+		}
+	}
+	slot, _, found, err := d.probe(k)
+	if err != nil {
+		return err
+	}
+	if found {
+		return nil
+	}
+	if err := d.writeSlot(slot, k, id); err != nil {
+		return err
+	}
+	d.count++
+	return nil
+}
+
+func (d *diskGroupIndex) writeSlot(slot int64, k groupKey, id int32) error {
+	var rec [diskSlotSize]byte
+	copy(rec[:16], k[:])
+	binary.LittleEndian.PutUint32(rec[16:], uint32(id))
+	if _, err := d.file.WriteAt(rec[:], slot*diskSlotSize); err != nil {
+		return fmt.Errorf("write provider group index: %w", err)
+	}
+	return nil
+}
+
+// grow rehashes every entry into a table twice the size.
+func (d *diskGroupIndex) grow() error {
+	next, err := newDiskGroupIndex(d.dir, d.slots*2)
+	if err != nil {
+		return err
+	}
+	const chunkSlots = 64 * 1024
+	buf := make([]byte, chunkSlots*diskSlotSize)
+	for off := int64(0); off < d.slots; off += chunkSlots {
+		n := min(int64(chunkSlots), d.slots-off)
+		chunk := buf[:n*diskSlotSize]
+		if _, err := d.file.ReadAt(chunk, off*diskSlotSize); err != nil && !errors.Is(err, io.EOF) {
+			next.Close()
+			return fmt.Errorf("read provider group index: %w", err)
+		}
+		for i := int64(0); i < n; i++ {
+			rec := chunk[i*diskSlotSize : (i+1)*diskSlotSize]
+			id := int32(binary.LittleEndian.Uint32(rec[16:]))
+			if id == 0 {
+				continue
+			}
+			if err := next.Insert(groupKey(rec[:16]), id); err != nil {
+				next.Close()
+				return err
+			}
+		}
+	}
+	if err := d.Close(); err != nil {
+		next.Close()
+		return err
+	}
+	*d = *next
+	return nil
+}
+
+func (d *diskGroupIndex) Close() error {
+	name := d.file.Name()
+	err := d.file.Close()
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// embeddedGroupsJSON has three items whose negotiated rates embed provider
+// groups. Group A appears three times (once with NPIs reordered), group B
+// once, and group C (same NPIs as A, different TIN) once.
+const embeddedGroupsJSON = `{
+  "reporting_entity_name": "test",
+  "reporting_entity_type": "health insurance issuer",
+  "last_updated_on": "2024-01-01",
+  "version": "1.3.1",
+  "in_network": [{
+    "negotiation_arrangement": "ffs",
+    "name": "Office visit",
+    "billing_code_type": "CPT",
+    "billing_code_type_version": "2024",
+    "billing_code": "99213",
+    "description": "Office visit",
+    "negotiated_rates": [{
+      "provider_groups": [
+        {"npi": [1111111111, 2222222222], "tin": {"type": "ein", "value": "11-1111111"}},
+        {"npi": [3333333333], "tin": {"type": "ein", "value": "33-3333333"}}
+      ],
+      "negotiated_prices": [{"negotiated_type": "negotiated", "negotiated_rate": 100, "expiration_date": "9999-12-31", "billing_class": "professional"}]
+    }]
+  },{
+    "negotiation_arrangement": "ffs",
+    "name": "Office visit",
+    "billing_code_type": "CPT",
+    "billing_code_type_version": "2024",
+    "billing_code": "99214",
+    "description": "Office visit",
+    "negotiated_rates": [{
+      "provider_groups": [
+        {"npi": [2222222222, 1111111111], "tin": {"type": "ein", "value": "11-1111111"}}
+      ],
+      "negotiated_prices": [{"negotiated_type": "negotiated", "negotiated_rate": 150, "expiration_date": "9999-12-31", "billing_class": "professional"}]
+    }]
+  },{
+    "negotiation_arrangement": "ffs",
+    "name": "Office visit",
+    "billing_code_type": "CPT",
+    "billing_code_type_version": "2024",
+    "billing_code": "99215",
+    "description": "Office visit",
+    "negotiated_rates": [{
+      "provider_groups": [
+        {"npi": [1111111111, 2222222222], "tin": {"type": "ein", "value": "11-1111111"}},
+        {"npi": [1111111111, 2222222222], "tin": {"type": "ein", "value": "99-9999999"}}
+      ],
+      "negotiated_prices": [{"negotiated_type": "negotiated", "negotiated_rate": 200, "expiration_date": "9999-12-31", "billing_class": "professional"}]
+    }]
+  }]
+}`
+
+func convertEmbedded(t *testing.T, filter map[int64]bool, memLimit int) ([]RateRow, []ProviderRow, *ConvertStats) {
+	t.Helper()
+
+	dir := t.TempDir()
+	ratesPath := filepath.Join(dir, "rates.parquet")
+	providersPath := filepath.Join(dir, "providers.parquet")
+
+	rw, err := NewRateParquetWriter(ratesPath)
+	if err != nil {
+		t.Fatalf("rate writer: %v", err)
+	}
+	pw, err := NewProviderParquetWriter(providersPath)
+	if err != nil {
+		t.Fatalf("provider writer: %v", err)
+	}
+
+	converter := NewStreamConverter(strings.NewReader(embeddedGroupsJSON), false)
+	converter.SetGroupIndexLimit(memLimit, dir)
+	if filter != nil {
+		converter.SetNPIFilter(filter)
+	}
+	stats, err := converter.Convert(rw, pw)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	rw.Close()
+	pw.Close()
+
+	return readRateRows(t, ratesPath), readProviderRows(t, providersPath), stats
+}
+
+func TestEmbeddedProviderGroupDedup(t *testing.T) {
+	for _, memLimit := range []int{0, 1} {
+		t.Run(fmt.Sprintf("mem=%d", memLimit), func(t *testing.T) {
+			rates, providers, stats := convertEmbedded(t, nil, memLimit)
+
+			if stats.UniqueProviderGroups != 3 {
+				t.Errorf("unique groups = %d, want 3", stats.UniqueProviderGroups)
+			}
+			if stats.DuplicateProviderGroups != 2 {
+				t.Errorf("duplicate groups = %d, want 2", stats.DuplicateProviderGroups)
+			}
+			// A: 2 NPIs, B: 1 NPI, C: 2 NPIs
+			if len(providers) != 5 {
+				t.Fatalf("provider rows = %d, want 5", len(providers))
+			}
+			if len(rates) != 3 {
+				t.Fatalf("rate rows = %d, want 3", len(rates))
+			}
+
+			want := map[string][]int32{
+				"99213": {1, 2},
+				"99214": {1},
+				"99215": {1, 3},
+			}
+			for _, r := range rates {
+				got := r.ProviderGroupIDs
+				w := want[r.BillingCode]
+				if fmt.Sprint(got) != fmt.Sprint(w) {
+					t.Errorf("%s provider_group_ids = %v, want %v", r.BillingCode, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedProviderGroupDedupWithNPIFilter(t *testing.T) {
+	// Only group B's NPI: duplicates of A must not be attached to rates.
+	rates, providers, stats := convertEmbedded(t, map[int64]bool{3333333333: true}, 0)
+
+	if stats.DuplicateProviderGroups != 2 {
+		t.Errorf("duplicate groups = %d, want 2", stats.DuplicateProviderGroups)
+	}
+	if len(providers) != 1 || providers[0].ProviderGroupID != 2 {
+		t.Fatalf("providers = %+v, want one row for group 2", providers)
+	}
+	if len(rates) != 1 || rates[0].BillingCode != "99213" {
+		t.Fatalf("rates = %d, want only 99213", len(rates))
+	}
+	if fmt.Sprint(rates[0].ProviderGroupIDs) != "[2]" {
+		t.Errorf("provider_group_ids = %v, want [2]", rates[0].ProviderGroupIDs)
+	}
+}
+
+func TestCanonicalGroupKey(t *testing.T) {
+	a := ProviderGroup{NPI: []int64{2, 1, 1}, TIN: TIN{Type: "EIN", Value: "11"}}
+	b := ProviderGroup{NPI: []int64{1, 2}, TIN: TIN{Type: "ein", Value: " 11 "}}
+	if canonicalGroupKey(a) != canonicalGroupKey(b) {
+		t.Error("expected reordered/duplicated NPIs and TIN case to hash identically")
+	}
+	c := ProviderGroup{NPI: []int64{1, 2}, TIN: TIN{Type: "ein", Value: "12"}}
+	if canonicalGroupKey(b) == canonicalGroupKey(c) {
+		t.Error("expected different TIN to hash differently")
+	}
+	d := ProviderGroup{NPI: []int64{12}, TIN: TIN{Type: "ein", Value: "11"}}
+	if canonicalGroupKey(b) == canonicalGroupKey(d) {
+		t.Error("expected NPI list [1,2] and [12] to hash differently")
+	}
+}
+
+func TestDiskGroupIndexGrow(t *testing.T) {
+	idx := newProviderGroupIndex(10, t.TempDir())
+	defer idx.Close()
+
+	// Enough entries to force several on-disk table doublings.
+	const n = 200_000
+	for i := int32(1); i <= n; i++ {
+		k := canonicalGroupKey(ProviderGroup{NPI: []int64{int64(i)}})
+		if _, ok, err := idx.Lookup(k); err != nil || ok {
+			t.Fatalf("lookup new key %d: ok=%v err=%v", i, ok, err)
+		}
+		if err := idx.Insert(k, i); err != nil {
+			t.Fatalf("insert %d: %v", i, err)
+		}
+	}
+	if !idx.Spilled() {
+		t.Fatal("expected index to spill to disk")
+	}
+	if idx.Len() != n {
+		t.Errorf("len = %d, want %d", idx.Len(), n)
+	}
+	for _, i := range []int32{1, 10, 11, 5000, n} {
+		k := canonicalGroupKey(ProviderGroup{NPI: []int64{int64(i)}})
+		id, ok, err := idx.Lookup(k)
+		if err != nil || !ok || id != i {
+			t.Errorf("lookup %d = (%d, %v, %v)", i, id, ok, err)
+		}
+	}
+}
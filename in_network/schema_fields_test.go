@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const allFieldsSample = "testdata/in-network-all-fields.json"
+
+// tagNames returns the names in a struct's json or parquet tags.
+func tagNames(typ reflect.Type, key string) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < typ.NumField(); i++ {
+		if tag := typ.Field(i).Tag.Get(key); tag != "" {
+			names[strings.Split(tag, ",")[0]] = true
+		}
+	}
+	return names
+}
+
+// TestSchemaFieldsModeled fails when the bundled schema has a property the
+// decode types would silently drop.
+func TestSchemaFieldsModeled(t *testing.T) {
+	type object struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Items      *object                    `json:"items"`
+	}
+	var schema struct {
+		object
+		Definitions map[string]object `json:"definitions"`
+	}
+	if err := json.Unmarshal(inNetworkSchemaJSON, &schema); err != nil {
+		t.Fatal(err)
+	}
+	var providerRefs object
+	if err := json.Unmarshal(schema.Properties["provider_references"], &providerRefs); err != nil {
+		t.Fatal(err)
+	}
+	var tin object
+	if err := json.Unmarshal(schema.Definitions["providers"].Properties["tin"], &tin); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		props map[string]json.RawMessage
+		typ   reflect.Type
+	}{
+		{"in_network", schema.Definitions["in_network"].Properties, reflect.TypeOf(InNetworkItem{})},
+		{"contained_billing_code", schema.Definitions["contained_billing_code"].Properties, reflect.TypeOf(ContainedCode{})},
+		{"negotiated_rates", schema.Definitions["negotiated_rates"].Properties, reflect.TypeOf(NegotiatedRate{})},
+		{"negotiated_price", schema.Definitions["negotiated_price"].Properties, reflect.TypeOf(NegotiatedPrice{})},
+		{"providers", schema.Definitions["providers"].Properties, reflect.TypeOf(ProviderGroup{})},
+		{"tin", tin.Properties, reflect.TypeOf(TIN{})},
+		{"provider_references", providerRefs.Items.Properties, reflect.TypeOf(ProviderReference{})},
+	} {
+		fields := tagNames(tt.typ, "json")
+		for prop := range tt.props {
+			if !fields[prop] {
+				t.Errorf("%s.%s is not modeled by %s", tt.name, prop, tt.typ.Name())
+			}
+		}
+	}
+
+	// Root scalars are copied onto every rate row.
+	columns := tagNames(reflect.TypeOf(RateRow{}), "parquet")
+	for prop := range schema.Properties {
+		if prop != "provider_references" && prop != "in_network" && !columns[prop] {
+			t.Errorf("root %s has no rate column", prop)
+		}
+	}
+}
+
+func TestConvertAllFields(t *testing.T) {
+	f, err := os.Open(allFieldsSample)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewValidator(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := v.Validate()
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Valid() {
+		var buf bytes.Buffer
+		report.WriteText(&buf)
+		t.Fatalf("fixture does not match the schema:\n%s", buf.String())
+	}
+
+	base := filepath.Join(t.TempDir(), "all")
+	res, err := convertFile(allFieldsSample, base, ConvertOptions{BufferSize: 1 << 16, CodesJSON: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates := readParquetRows[RateRow](t, res.Outputs.Rates)
+	providers := readParquetRows[ProviderRow](t, res.Outputs.Providers)
+	items := readParquetRows[ItemRow](t, res.Outputs.Items)
+	codes := readParquetRows[ContainedCodeRow](t, res.Outputs.ContainedCodes)
+
+	if len(rates) != 7 || len(items) != 4 || len(codes) != 3 || len(providers) != 4 {
+		t.Fatalf("got %d rates, %d items, %d codes, %d providers; want 7, 4, 3, 4",
+			len(rates), len(items), len(codes), len(providers))
+	}
+
+	r := rates[0]
+	for name, got := range map[string]*string{
+		"plan_name":         r.PlanName,
+		"issuer_name":       r.IssuerName,
+		"plan_sponsor_name": r.PlanSponsorName,
+		"plan_id_type":      r.PlanIDType,
+		"plan_id":           r.PlanID,
+		"plan_market_type":  r.PlanMarketType,
+	} {
+		if got == nil || *got == "" {
+			t.Errorf("%s not set", name)
+		}
+	}
+	if r.AdditionalInformation == nil || !strings.HasPrefix(*r.AdditionalInformation, "Telehealth") {
+		t.Errorf("additional_information = %v", r.AdditionalInformation)
+	}
+	if len(r.ServiceCode) != 2 || len(r.BillingCodeModifier) != 1 || r.BillingCodeModifier[0] != "25" {
+		t.Errorf("service_code = %v, billing_code_modifier = %v", r.ServiceCode, r.BillingCodeModifier)
+	}
+	if r.SeverityOfIllness != nil {
+		t.Errorf("severity_of_illness = %q on a CPT rate, want NULL", *r.SeverityOfIllness)
+	}
+
+	units := make(map[string]string)
+	for _, r := range rates {
+		units[r.NegotiatedType] = r.RateUnit
+	}
+	wantUnits := map[string]string{
+		NegotiatedTypeNegotiated:  RateUnitDollars,
+		NegotiatedTypeDerived:     RateUnitDollars,
+		NegotiatedTypeFeeSchedule: RateUnitDollars,
+		NegotiatedTypePercentage:  RateUnitPercent,
+		NegotiatedTypePerDiem:     RateUnitDollarsPerDay,
+	}
+	if !reflect.DeepEqual(units, wantUnits) {
+		t.Errorf("rate units = %v, want %v", units, wantUnits)
+	}
+
+	drg := rates[3]
+	if drg.BillingCode != "302" || drg.SeverityOfIllness == nil || *drg.SeverityOfIllness != "3" {
+		t.Errorf("DRG rate %s severity_of_illness = %v, want 3", drg.BillingCode, drg.SeverityOfIllness)
+	}
+	if drg.NegotiatedRate != 40.5 || drg.RateUnit != RateUnitPercent {
+		t.Errorf("percentage rate = %v %s, want 40.5 %s", drg.NegotiatedRate, drg.RateUnit, RateUnitPercent)
+	}
+	if items[1].SeverityOfIllness == nil || *items[1].SeverityOfIllness != "3" {
+		t.Errorf("item severity_of_illness = %v, want 3", items[1].SeverityOfIllness)
+	}
+
+	bundle := rates[5]
+	if bundle.NegotiationArrangement != "bundle" || !reflect.DeepEqual(bundle.BillingCodeModifier, []string{"RT", "LT"}) {
+		t.Errorf("bundle rate %s modifiers = %v, want [RT LT]", bundle.BillingCode, bundle.BillingCodeModifier)
+	}
+	if bundle.BundledCodesJSON == nil || !strings.Contains(*bundle.BundledCodesJSON, "29881") {
+		t.Errorf("bundled_codes_json = %v", bundle.BundledCodesJSON)
+	}
+	if rates[6].CoveredServicesJSON == nil || !rates[2].NoExpiration {
+		t.Errorf("covered_services_json = %v, no_expiration = %v", rates[6].CoveredServicesJSON, rates[2].NoExpiration)
+	}
+
+	var tinOnly *ProviderRow
+	for i := range providers {
+		if providers[i].NPI == 0 {
+			tinOnly = &providers[i]
+		}
+		if len(providers[i].NetworkNames) == 0 {
+			t.Errorf("provider %d has no network_names", providers[i].NPI)
+		}
+	}
+	if tinOnly == nil || tinOnly.TINValue != "22-2222222" || tinOnly.BusinessName == nil {
+		t.Errorf("TIN-only provider row = %+v", tinOnly)
+	}
+}
+
+func TestProviderGroupNPIs(t *testing.T) {
+	if got := (ProviderGroup{}).NPIs(); !reflect.DeepEqual(got, []int64{0}) {
+		t.Errorf("NPIs() of a group without NPIs = %v, want [0]", got)
+	}
+	if got := (ProviderGroup{NPI: []int64{1234567890}}).NPIs(); !reflect.DeepEqual(got, []int64{1234567890}) {
+		t.Errorf("NPIs() = %v, want [1234567890]", got)
+	}
+}
+
+func TestGroupMatchesFilterTINOnly(t *testing.T) {
+	c := &StreamConverter{npiFilter: map[int64]bool{0: true}}
+	if !c.groupMatchesFilter(ProviderGroup{TIN: TIN{Type: "ein", Value: "22-2222222"}}) {
+		t.Error("TIN-only group does not match a filter on its NPI 0 row")
+	}
+	c.npiFilter = map[int64]bool{1234567890: true}
+	if c.groupMatchesFilter(ProviderGroup{NPI: []int64{1987654321}}) {
+		t.Error("group matches a filter without any of its NPIs")
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// stdioPath is the -file / -out value meaning stdin or stdout.
+const stdioPath = "-"
+
+// stdinOutputBase names the outputs of a conversion read from stdin when
+// no -out is given.
+const stdinOutputBase = "stdin"
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// isGzip reports whether br starts with the gzip magic bytes, without
+// consuming them.
+func isGzip(br *bufio.Reader) bool {
+	head, _ := br.Peek(len(gzipMagic))
+	return bytes.Equal(head, gzipMagic)
+}
+
+// gunzipIfCompressed wraps br in a gzip reader when the stream starts with
+// the gzip magic bytes. The returned close function releases the gzip
+// reader, if any.
+func gunzipIfCompressed(br *bufio.Reader) (io.Reader, func() error, error) {
+	if !isGzip(br) {
+		return br, func() error { return nil }, nil
+	}
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create gzip reader: %w", err)
+	}
+	return gz, gz.Close, nil
+}
+
+// writeTar writes every file and directory under dir to w as an
+// uncompressed tar stream, with names relative to dir.
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if info.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("write tar: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("write tar: %w", err)
+	}
+	return nil
+}
+
+// convertToTar converts input into a temporary directory and streams the
+// outputs to w as a tar archive, named as if name were the output base.
+// Part directories are archived with their manifests.
+func convertToTar(w io.Writer, input, name string, opts ConvertOptions) (*ConvertResult, error) {
+	dir, err := os.MkdirTemp(opts.TmpDir, "in_network-")
+	if err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	res, err := convertFile(input, filepath.Join(dir, name), opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTar(w, dir); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
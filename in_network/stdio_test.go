@@ -0,0 +1,133 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// withStdin points os.Stdin at a file holding data for the test's duration.
+func withStdin(t *testing.T, data []byte) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = old
+		f.Close()
+	})
+}
+
+func TestOpenInputStdin(t *testing.T) {
+	const doc = `{"reporting_entity_name": "x"}`
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(doc))
+	zw.Close()
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{
+		{"plain", []byte(doc)},
+		{"gzip", gz.Bytes()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			withStdin(t, tc.data)
+			r, closeInput, err := openInput(stdioPath, 1<<16)
+			if err != nil {
+				t.Fatalf("openInput: %v", err)
+			}
+			defer closeInput()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if string(got) != doc {
+				t.Errorf("read %q, want %q", got, doc)
+			}
+		})
+	}
+}
+
+func TestConvertToTar(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(examplesDir, "in-network-rates-fee-for-service-single-plan-sample.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(data)
+	zw.Close()
+	withStdin(t, gz.Bytes())
+
+	opts := ConvertOptions{BufferSize: 1 << 16, CodesJSON: true, Summary: true, TmpDir: t.TempDir()}
+	base := defaultOutputBase(stdioPath)
+	var archive bytes.Buffer
+	res, err := convertToTar(&archive, stdioPath, base, opts)
+	if err != nil {
+		t.Fatalf("convertToTar: %v", err)
+	}
+	if res.Stats.RateRows == 0 {
+		t.Fatal("no rate rows converted")
+	}
+
+	// Unpack and check the entries are the usual outputs, readable as Parquet.
+	outDir := t.TempDir()
+	var names []string
+	tr := tar.NewReader(&archive)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tar: %v", err)
+		}
+		names = append(names, hdr.Name)
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(outDir, hdr.Name), body, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var want []string
+	for _, p := range outputPaths(base, opts).Paths() {
+		want = append(want, filepath.Base(p))
+	}
+	sort.Strings(want)
+	sort.Strings(names)
+	if len(names) != len(want) {
+		t.Fatalf("tar entries = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("tar entries = %v, want %v", names, want)
+		}
+	}
+	if rates := readRateRows(t, filepath.Join(outDir, "stdin_rates.parquet")); int64(len(rates)) != res.Stats.RateRows {
+		t.Errorf("rates in tar = %d, want %d", len(rates), res.Stats.RateRows)
+	}
+
+	left, err := os.ReadDir(opts.TmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) != 0 {
+		t.Errorf("temporary outputs left behind: %v", left)
+	}
+}
@@ -2,32 +2,72 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"slices"
+	"strings"
+	"time"
 )
 
 // ConvertStats tracks conversion statistics.
 type ConvertStats struct {
-	InNetworkItems int64
-	RateRows       int64
-	ProviderRows   int64
+	InNetworkItems    int64 `json:"in_network_items"`
+	RateRows          int64 `json:"rate_rows"`
+	ProviderRows      int64 `json:"provider_rows"`
+	ItemRows          int64 `json:"item_rows"`
+	ContainedCodeRows int64 `json:"contained_code_rows"`
+
+	// Embedded provider groups are de-duplicated by content hash.
+	// UniqueProviderGroups counts distinct groups assigned an ID;
+	// DuplicateProviderGroups counts repeats that reused an existing ID.
+	UniqueProviderGroups    int64 `json:"unique_provider_groups"`
+	DuplicateProviderGroups int64 `json:"duplicate_provider_groups"`
+
+	// ExpiredPrices counts prices dropped by SetAsOf;
+	// InvalidExpirationDates counts unparseable expiration_date values.
+	ExpiredPrices          int64 `json:"expired_prices,omitempty"`
+	InvalidExpirationDates int64 `json:"invalid_expiration_dates,omitempty"`
+
+	// PlanRows, SummaryRows and NetworkRows count the _plans, _summary
+	// and _networks outputs; they are set by convertFile.
+	PlanRows    int64 `json:"plan_rows,omitempty"`
+	SummaryRows int64 `json:"summary_rows,omitempty"`
+	NetworkRows int64 `json:"network_rows,omitempty"`
 }
 
-// StreamConverter reads in-network JSON and writes to Parquet files.
+// RowWriter receives one kind of output row from StreamConverter. The
+// Parquet writers in parquet.go and the CSV writers in csv.go implement it.
+type RowWriter[T any] interface {
+	Write(row T) error
+	Close() error
+}
+
+// StreamConverter reads in-network JSON and writes rows to RowWriters.
 type StreamConverter struct {
-	decoder         *json.Decoder
+	scan            *jsonScanner
 	meta            RootMetadata
 	verbose         bool
 	nextProviderID  int32 // auto-increment for embedded provider groups
 	npiFilter       map[int64]bool
 	matchedGroupIDs map[int32]bool
+	groupIndex      *providerGroupIndex
+	groupIndexMem   int
+	groupIndexDir   string
+	nextItemID      int64
+	itemWriter      RowWriter[ItemRow]
+	codeWriter      RowWriter[ContainedCodeRow]
+	noCodesJSON     bool
+	summary         *RateSummary
+	networks        *NetworkIndex
+	asOf            time.Time // zero keeps expired prices
 }
 
 // NewStreamConverter creates a new streaming converter.
 func NewStreamConverter(r io.Reader, verbose bool) *StreamConverter {
 	return &StreamConverter{
-		decoder:         json.NewDecoder(r),
+		scan:            newJSONScanner(r),
 		verbose:         verbose,
 		matchedGroupIDs: make(map[int32]bool),
 	}
@@ -39,110 +79,113 @@ func (c *StreamConverter) SetNPIFilter(filter map[int64]bool) {
 	c.npiFilter = filter
 }
 
-// Convert streams the JSON input and writes to both Parquet writers.
-func (c *StreamConverter) Convert(rateWriter *RateParquetWriter, providerWriter *ProviderParquetWriter) (*ConvertStats, error) {
-	stats := &ConvertStats{}
+// SetItemWriters enables the normalized item and contained code outputs.
+// Either writer may be nil to skip that output. Rate rows carry item_id
+// whether or not the item output is written.
+func (c *StreamConverter) SetItemWriters(items RowWriter[ItemRow], codes RowWriter[ContainedCodeRow]) {
+	c.itemWriter = items
+	c.codeWriter = codes
+}
 
-	// Read opening {
-	t, err := c.decoder.Token()
-	if err != nil {
-		return nil, fmt.Errorf("read opening token: %w", err)
-	}
-	if d, ok := t.(json.Delim); !ok || d != '{' {
-		return nil, fmt.Errorf("expected {, got %v", t)
-	}
+// SetCodesJSON controls whether rate rows also carry the denormalized
+// bundled_codes_json and covered_services_json columns (default true).
+func (c *StreamConverter) SetCodesJSON(enabled bool) {
+	c.noCodesJSON = !enabled
+}
 
-	for c.decoder.More() {
-		t, err := c.decoder.Token()
-		if err != nil {
-			return nil, fmt.Errorf("read field name: %w", err)
-		}
-		field, ok := t.(string)
-		if !ok {
-			return nil, fmt.Errorf("expected field name, got %T", t)
+// SetAsOf drops negotiated prices that expired before date. Prices with
+// the no-expiration sentinel or an unparseable expiration_date are kept.
+func (c *StreamConverter) SetAsOf(date time.Time) {
+	c.asOf = date
+}
+
+// SetSummary makes the converter add every written rate row to s.
+func (c *StreamConverter) SetSummary(s *RateSummary) {
+	c.summary = s
+}
+
+// SetNetworks makes the converter record provider_references networks
+// and the rate rows referencing each group in x.
+func (c *StreamConverter) SetNetworks(x *NetworkIndex) {
+	c.networks = x
+}
+
+// SetGroupIndexLimit sets how many distinct embedded provider groups are
+// kept in memory before the de-duplication index spills to a temp file in
+// dir ("" uses the system temp directory).
+func (c *StreamConverter) SetGroupIndexLimit(n int, dir string) {
+	c.groupIndexMem = n
+	c.groupIndexDir = dir
+}
+
+// GroupIndexSpilled reports whether the provider group index overflowed
+// to disk during conversion.
+func (c *StreamConverter) GroupIndexSpilled() bool {
+	return c.groupIndex != nil && c.groupIndex.Spilled()
+}
+
+// Convert streams the JSON input and writes to both writers.
+func (c *StreamConverter) Convert(rateWriter RowWriter[RateRow], providerWriter RowWriter[ProviderRow]) (*ConvertStats, error) {
+	stats := &ConvertStats{}
+	defer func() {
+		if c.groupIndex != nil {
+			c.groupIndex.Close()
 		}
+	}()
 
+	optional := func(dst **string) error {
+		v, err := c.scan.String()
+		*dst = &v
+		return err
+	}
+	err := c.scan.Object(func(key []byte) error {
+		var err error
+		field := string(key)
 		switch field {
 		case "reporting_entity_name":
-			if err := c.decoder.Decode(&c.meta.ReportingEntityName); err != nil {
-				return nil, fmt.Errorf("decode reporting_entity_name: %w", err)
-			}
+			c.meta.ReportingEntityName, err = c.scan.String()
 		case "reporting_entity_type":
-			if err := c.decoder.Decode(&c.meta.ReportingEntityType); err != nil {
-				return nil, fmt.Errorf("decode reporting_entity_type: %w", err)
-			}
+			c.meta.ReportingEntityType, err = c.scan.String()
 		case "plan_name":
-			var s string
-			if err := c.decoder.Decode(&s); err != nil {
-				return nil, fmt.Errorf("decode plan_name: %w", err)
-			}
-			c.meta.PlanName = &s
+			err = optional(&c.meta.PlanName)
 		case "issuer_name":
-			var s string
-			if err := c.decoder.Decode(&s); err != nil {
-				return nil, fmt.Errorf("decode issuer_name: %w", err)
-			}
-			c.meta.IssuerName = &s
+			err = optional(&c.meta.IssuerName)
 		case "plan_sponsor_name":
-			var s string
-			if err := c.decoder.Decode(&s); err != nil {
-				return nil, fmt.Errorf("decode plan_sponsor_name: %w", err)
-			}
-			c.meta.PlanSponsorName = &s
+			err = optional(&c.meta.PlanSponsorName)
 		case "plan_id_type":
-			var s string
-			if err := c.decoder.Decode(&s); err != nil {
-				return nil, fmt.Errorf("decode plan_id_type: %w", err)
-			}
-			c.meta.PlanIDType = &s
+			err = optional(&c.meta.PlanIDType)
 		case "plan_id":
-			var s string
-			if err := c.decoder.Decode(&s); err != nil {
-				return nil, fmt.Errorf("decode plan_id: %w", err)
-			}
-			c.meta.PlanID = &s
+			err = optional(&c.meta.PlanID)
 		case "plan_market_type":
-			var s string
-			if err := c.decoder.Decode(&s); err != nil {
-				return nil, fmt.Errorf("decode plan_market_type: %w", err)
-			}
-			c.meta.PlanMarketType = &s
+			err = optional(&c.meta.PlanMarketType)
 		case "last_updated_on":
-			if err := c.decoder.Decode(&c.meta.LastUpdatedOn); err != nil {
-				return nil, fmt.Errorf("decode last_updated_on: %w", err)
-			}
+			c.meta.LastUpdatedOn, err = c.scan.String()
 		case "version":
-			if err := c.decoder.Decode(&c.meta.Version); err != nil {
-				return nil, fmt.Errorf("decode version: %w", err)
-			}
+			c.meta.Version, err = c.scan.String()
 		case "provider_references":
-			if err := c.streamProviderReferences(providerWriter, stats); err != nil {
-				return nil, err
-			}
+			err = c.streamProviderReferences(providerWriter, stats)
 		case "in_network":
-			if err := c.streamInNetwork(rateWriter, providerWriter, stats); err != nil {
-				return nil, err
-			}
+			err = c.streamInNetwork(rateWriter, providerWriter, stats)
 		default:
-			var skip json.RawMessage
-			if err := c.decoder.Decode(&skip); err != nil {
-				return nil, fmt.Errorf("skip field %s: %w", field, err)
+			if err := c.scan.Skip(); err != nil {
+				return fmt.Errorf("skip field %s: %w", field, err)
 			}
 		}
+		if err != nil {
+			return fmt.Errorf("read %s: %w", field, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	// Read closing }
-	if _, err := c.decoder.Token(); err != nil {
-		return nil, fmt.Errorf("read closing token: %w", err)
-	}
-
 	return stats, nil
 }
 
-func (c *StreamConverter) streamProviderReferences(w *ProviderParquetWriter, stats *ConvertStats) error {
+func (c *StreamConverter) streamProviderReferences(w RowWriter[ProviderRow], stats *ConvertStats) error {
 	return c.streamArray(func() error {
 		var ref ProviderReference
-		if err := c.decoder.Decode(&ref); err != nil {
+		if err := decodeProviderReference(c.scan, &ref); err != nil {
 			return fmt.Errorf("decode provider_reference: %w", err)
 		}
 
@@ -153,7 +196,7 @@ func (c *StreamConverter) streamProviderReferences(w *ProviderParquetWriter, sta
 				s := pg.TIN.BusinessName
 				bizName = &s
 			}
-			for _, npi := range pg.NPI {
+			for _, npi := range pg.NPIs() {
 				if c.npiFilter != nil && !c.npiFilter[npi] {
 					continue
 				}
@@ -174,15 +217,18 @@ func (c *StreamConverter) streamProviderReferences(w *ProviderParquetWriter, sta
 		}
 		if matched {
 			c.matchedGroupIDs[int32(ref.ProviderGroupID)] = true
+			if c.networks != nil {
+				c.networks.AddGroup(int32(ref.ProviderGroupID), ref.NetworkName)
+			}
 		}
 		return nil
 	})
 }
 
-func (c *StreamConverter) streamInNetwork(w *RateParquetWriter, pw *ProviderParquetWriter, stats *ConvertStats) error {
+func (c *StreamConverter) streamInNetwork(w RowWriter[RateRow], pw RowWriter[ProviderRow], stats *ConvertStats) error {
 	return c.streamArray(func() error {
 		var item InNetworkItem
-		if err := c.decoder.Decode(&item); err != nil {
+		if err := decodeInNetworkItem(c.scan, &item); err != nil {
 			return fmt.Errorf("decode in_network item: %w", err)
 		}
 		stats.InNetworkItems++
@@ -192,9 +238,13 @@ func (c *StreamConverter) streamInNetwork(w *RateParquetWriter, pw *ProviderParq
 				stats.InNetworkItems, stats.RateRows)
 		}
 
+		c.nextItemID++
+		itemID := c.nextItemID
+		rateRowsBefore := stats.RateRows
+
 		// Serialize bundled_codes and covered_services to JSON strings
 		var bundledJSON, coveredJSON *string
-		if len(item.BundledCodes) > 0 {
+		if len(item.BundledCodes) > 0 && !c.noCodesJSON {
 			b, err := json.Marshal(item.BundledCodes)
 			if err != nil {
 				return fmt.Errorf("marshal bundled_codes: %w", err)
@@ -202,7 +252,7 @@ func (c *StreamConverter) streamInNetwork(w *RateParquetWriter, pw *ProviderParq
 			s := string(b)
 			bundledJSON = &s
 		}
-		if len(item.CoveredServices) > 0 {
+		if len(item.CoveredServices) > 0 && !c.noCodesJSON {
 			b, err := json.Marshal(item.CoveredServices)
 			if err != nil {
 				return fmt.Errorf("marshal covered_services: %w", err)
@@ -215,10 +265,21 @@ func (c *StreamConverter) streamInNetwork(w *RateParquetWriter, pw *ProviderParq
 			var ids []int32
 
 			if len(nr.ProviderGroups) > 0 {
-				// Embedded provider groups: assign auto-incremented IDs and write provider rows
+				// Embedded provider groups: identical groups share one ID, and
+				// provider rows are written only the first time a group is seen.
 				for _, pg := range nr.ProviderGroups {
-					c.nextProviderID++
-					pgID := c.nextProviderID
+					pgID, isNew, err := c.embeddedGroupID(pg)
+					if err != nil {
+						return err
+					}
+					if !isNew {
+						stats.DuplicateProviderGroups++
+						if c.groupMatchesFilter(pg) && !slices.Contains(ids, pgID) {
+							ids = append(ids, pgID)
+						}
+						continue
+					}
+					stats.UniqueProviderGroups++
 
 					var bizName *string
 					if pg.TIN.BusinessName != "" {
@@ -226,7 +287,7 @@ func (c *StreamConverter) streamInNetwork(w *RateParquetWriter, pw *ProviderParq
 						bizName = &s
 					}
 					groupMatched := false
-					for _, npi := range pg.NPI {
+					for _, npi := range pg.NPIs() {
 						if c.npiFilter != nil && !c.npiFilter[npi] {
 							continue
 						}
@@ -261,8 +322,30 @@ func (c *StreamConverter) streamInNetwork(w *RateParquetWriter, pw *ProviderParq
 			if c.npiFilter != nil && len(ids) == 0 {
 				continue
 			}
+			nrRowsBefore := stats.RateRows
 
 			for _, price := range nr.NegotiatedPrices {
+				var (
+					expDate *int32
+					expRaw  *string
+				)
+				date, noExpiration, ok := parseExpirationDate(price.ExpirationDate)
+				switch {
+				case strings.TrimSpace(price.ExpirationDate) == "":
+					// Missing or empty: no date, and nothing worth keeping
+				case !ok:
+					stats.InvalidExpirationDates++
+					raw := price.ExpirationDate
+					expRaw = &raw
+				case !noExpiration:
+					if !c.asOf.IsZero() && date.Before(c.asOf) {
+						stats.ExpiredPrices++
+						continue
+					}
+					days := epochDays(date)
+					expDate = &days
+				}
+
 				var addlInfo *string
 				if price.AdditionalInformation != "" {
 					s := price.AdditionalInformation
@@ -280,17 +363,22 @@ func (c *StreamConverter) streamInNetwork(w *RateParquetWriter, pw *ProviderParq
 					PlanMarketType:         c.meta.PlanMarketType,
 					LastUpdatedOn:          c.meta.LastUpdatedOn,
 					Version:                c.meta.Version,
+					ItemID:                 itemID,
 					NegotiationArrangement: item.NegotiationArrangement,
 					Name:                   item.Name,
 					BillingCodeType:        item.BillingCodeType,
 					BillingCodeTypeVersion: item.BillingCodeTypeVersion,
+					SeverityOfIllness:      nonEmpty(item.SeverityOfIllness),
 					BillingCode:            item.BillingCode,
 					Description:            item.Description,
 					NegotiatedRate:         price.NegotiatedRate,
 					NegotiatedType:         price.NegotiatedType,
+					RateUnit:               RateUnit(price.NegotiatedType),
 					BillingClass:           price.BillingClass,
 					Setting:                price.Setting,
-					ExpirationDate:         price.ExpirationDate,
+					ExpirationDate:         expDate,
+					NoExpiration:           noExpiration,
+					ExpirationDateRaw:      expRaw,
 					ServiceCode:            price.ServiceCode,
 					BillingCodeModifier:    price.BillingCodeModifier,
 					AdditionalInformation:  addlInfo,
@@ -301,28 +389,123 @@ func (c *StreamConverter) streamInNetwork(w *RateParquetWriter, pw *ProviderParq
 				if err := w.Write(row); err != nil {
 					return err
 				}
+				if c.summary != nil {
+					c.summary.Add(&row)
+				}
 				stats.RateRows++
 			}
+			if c.networks != nil && len(nr.ProviderGroups) == 0 {
+				c.networks.AddRates(ids, stats.RateRows-nrRowsBefore)
+			}
 		}
-		return nil
+
+		// With an NPI filter or -as-of, items left without rates are
+		// dropped entirely.
+		if (c.npiFilter != nil || !c.asOf.IsZero()) && stats.RateRows == rateRowsBefore {
+			return nil
+		}
+		return c.writeItem(itemID, &item, stats)
 	})
 }
 
-// streamArray reads a JSON array token by token, calling fn for each element.
-func (c *StreamConverter) streamArray(fn func() error) error {
-	t, err := c.decoder.Token()
+// writeItem writes the normalized item row and its contained codes.
+func (c *StreamConverter) writeItem(itemID int64, item *InNetworkItem, stats *ConvertStats) error {
+	if c.itemWriter != nil {
+		row := ItemRow{
+			ItemID:                 itemID,
+			NegotiationArrangement: item.NegotiationArrangement,
+			Name:                   item.Name,
+			BillingCodeType:        item.BillingCodeType,
+			BillingCodeTypeVersion: item.BillingCodeTypeVersion,
+			SeverityOfIllness:      nonEmpty(item.SeverityOfIllness),
+			BillingCode:            item.BillingCode,
+			Description:            item.Description,
+			BundledCodeCount:       int32(len(item.BundledCodes)),
+			CoveredServiceCount:    int32(len(item.CoveredServices)),
+		}
+		if err := c.itemWriter.Write(row); err != nil {
+			return err
+		}
+		stats.ItemRows++
+	}
+	if c.codeWriter == nil {
+		return nil
+	}
+	for _, rel := range []struct {
+		name  string
+		codes []ContainedCode
+	}{
+		{RelationBundled, item.BundledCodes},
+		{RelationCovered, item.CoveredServices},
+	} {
+		for _, cc := range rel.codes {
+			row := ContainedCodeRow{
+				ItemID:                 itemID,
+				Relation:               rel.name,
+				BillingCodeType:        cc.BillingCodeType,
+				BillingCodeTypeVersion: cc.BillingCodeTypeVersion,
+				BillingCode:            cc.BillingCode,
+				Description:            cc.Description,
+			}
+			if err := c.codeWriter.Write(row); err != nil {
+				return err
+			}
+			stats.ContainedCodeRows++
+		}
+	}
+	return nil
+}
+
+// embeddedGroupID returns the provider group ID for an embedded group,
+// assigning the next ID if its canonical hash has not been seen.
+func (c *StreamConverter) embeddedGroupID(pg ProviderGroup) (int32, bool, error) {
+	if c.groupIndex == nil {
+		c.groupIndex = newProviderGroupIndex(c.groupIndexMem, c.groupIndexDir)
+	}
+	k := canonicalGroupKey(pg)
+	id, ok, err := c.groupIndex.Lookup(k)
 	if err != nil {
-		return fmt.Errorf("read array start: %w", err)
+		return 0, false, err
 	}
-	if d, ok := t.(json.Delim); !ok || d != '[' {
-		return fmt.Errorf("expected [, got %v", t)
+	if ok {
+		return id, false, nil
 	}
-	for c.decoder.More() {
-		if err := fn(); err != nil {
-			return err
+	c.nextProviderID++
+	if err := c.groupIndex.Insert(k, c.nextProviderID); err != nil {
+		return 0, false, err
+	}
+	return c.nextProviderID, true, nil
+}
+
+// groupMatchesFilter reports whether any NPI in pg passes the NPI filter,
+// using the same NPIs() the provider rows are written from.
+func (c *StreamConverter) groupMatchesFilter(pg ProviderGroup) bool {
+	if c.npiFilter == nil {
+		return true
+	}
+	for _, npi := range pg.NPIs() {
+		if c.npiFilter[npi] {
+			return true
 		}
 	}
-	// Read closing ]
-	_, err = c.decoder.Token()
+	return false
+}
+
+// streamArray calls fn for each element of the JSON array at the current
+// position; fn must consume exactly one element.
+func (c *StreamConverter) streamArray(fn func() error) error {
+	isArray, err := c.scan.Array(fn)
+	if err == nil && !isArray {
+		err = errors.New("expected array, got null")
+	}
 	return err
 }
+
+// nonEmpty returns a pointer to s, or nil for an empty string, for
+// optional Parquet columns.
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
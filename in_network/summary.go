@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// sketchRelativeAccuracy bounds the relative error of sketch quantiles:
+// a reported p50 of 100.00 means the true median is within ±1%.
+const sketchRelativeAccuracy = 0.01
+
+// sketchVersion prefixes encoded sketches.
+const sketchVersion = 1
+
+var (
+	sketchGamma    = (1 + sketchRelativeAccuracy) / (1 - sketchRelativeAccuracy)
+	sketchLogGamma = math.Log(sketchGamma)
+)
+
+// rateSketch is a DDSketch: values are counted in logarithmic buckets so
+// quantiles have bounded relative error, and two sketches merge exactly by
+// adding bucket counts. Negotiated rates are positive by schema, but zero
+// and negative values are kept so nothing is dropped.
+type rateSketch struct {
+	pos   map[int32]uint64
+	neg   map[int32]uint64 // keyed by the bucket of -v
+	zeros uint64
+	count uint64
+	min   float64
+	max   float64
+}
+
+func newRateSketch() *rateSketch {
+	return &rateSketch{pos: make(map[int32]uint64), neg: make(map[int32]uint64)}
+}
+
+func sketchIndex(v float64) int32 {
+	return int32(math.Ceil(math.Log(v) / sketchLogGamma))
+}
+
+// sketchValue returns the representative value of bucket i, which is
+// within the relative accuracy of every value counted there.
+func sketchValue(i int32) float64 {
+	return 2 * math.Pow(sketchGamma, float64(i)) / (sketchGamma + 1)
+}
+
+// Add counts one value. NaN and infinities are ignored.
+func (s *rateSketch) Add(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+	switch {
+	case v > 0:
+		s.pos[sketchIndex(v)]++
+	case v < 0:
+		s.neg[sketchIndex(-v)]++
+	default:
+		s.zeros++
+	}
+	if s.count == 0 || v < s.min {
+		s.min = v
+	}
+	if s.count == 0 || v > s.max {
+		s.max = v
+	}
+	s.count++
+}
+
+// Merge adds o's counts to s.
+func (s *rateSketch) Merge(o *rateSketch) {
+	if o.count == 0 {
+		return
+	}
+	for i, n := range o.pos {
+		s.pos[i] += n
+	}
+	for i, n := range o.neg {
+		s.neg[i] += n
+	}
+	s.zeros += o.zeros
+	if s.count == 0 || o.min < s.min {
+		s.min = o.min
+	}
+	if s.count == 0 || o.max > s.max {
+		s.max = o.max
+	}
+	s.count += o.count
+}
+
+// Quantile returns the q-quantile (0 ≤ q ≤ 1). p0 and p100 are the exact
+// min and max, and other quantiles are clamped to that range.
+func (s *rateSketch) Quantile(q float64) float64 {
+	if s.count == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return s.min
+	}
+	if q >= 1 {
+		return s.max
+	}
+	rank := uint64(q * float64(s.count-1))
+
+	var v float64
+	var seen uint64
+	found := false
+	negKeys := sortedKeys(s.neg)
+	for i := len(negKeys) - 1; i >= 0 && !found; i-- { // most negative first
+		seen += s.neg[negKeys[i]]
+		if seen > rank {
+			v, found = -sketchValue(negKeys[i]), true
+		}
+	}
+	if !found {
+		seen += s.zeros
+		if seen > rank {
+			v, found = 0, true
+		}
+	}
+	if !found {
+		for _, k := range sortedKeys(s.pos) {
+			seen += s.pos[k]
+			if seen > rank {
+				v, found = sketchValue(k), true
+				break
+			}
+		}
+	}
+	return math.Min(math.Max(v, s.min), s.max)
+}
+
+func sortedKeys(m map[int32]uint64) []int32 {
+	keys := make([]int32, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// MarshalBinary encodes the sketch: version, count, min, max, zeros, then
+// the positive and negative buckets as delta-encoded (index, count) pairs.
+func (s *rateSketch) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, 32+8*(len(s.pos)+len(s.neg)))
+	buf = append(buf, sketchVersion)
+	buf = binary.AppendUvarint(buf, s.count)
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.min))
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(s.max))
+	buf = binary.AppendUvarint(buf, s.zeros)
+	for _, store := range []map[int32]uint64{s.pos, s.neg} {
+		keys := sortedKeys(store)
+		buf = binary.AppendUvarint(buf, uint64(len(keys)))
+		prev := int32(0)
+		for _, k := range keys {
+			buf = binary.AppendVarint(buf, int64(k-prev))
+			buf = binary.AppendUvarint(buf, store[k])
+			prev = k
+		}
+	}
+	return buf, nil
+}
+
+var errBadSketch = errors.New("invalid rate sketch encoding")
+
+// UnmarshalBinary decodes a sketch written by MarshalBinary.
+func (s *rateSketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 1 || data[0] != sketchVersion {
+		return errBadSketch
+	}
+	*s = *newRateSketch()
+	p := data[1:]
+	uvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(p)
+		if n <= 0 {
+			return 0, errBadSketch
+		}
+		p = p[n:]
+		return v, nil
+	}
+	float := func() (float64, error) {
+		if len(p) < 8 {
+			return 0, errBadSketch
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(p))
+		p = p[8:]
+		return v, nil
+	}
+
+	var err error
+	if s.count, err = uvarint(); err != nil {
+		return err
+	}
+	if s.min, err = float(); err != nil {
+		return err
+	}
+	if s.max, err = float(); err != nil {
+		return err
+	}
+	if s.zeros, err = uvarint(); err != nil {
+		return err
+	}
+	for _, store := range []map[int32]uint64{s.pos, s.neg} {
+		n, err := uvarint()
+		if err != nil {
+			return err
+		}
+		prev := int32(0)
+		for j := uint64(0); j < n; j++ {
+			d, m := binary.Varint(p)
+			if m <= 0 {
+				return errBadSketch
+			}
+			p = p[m:]
+			c, err := uvarint()
+			if err != nil {
+				return err
+			}
+			prev += int32(d)
+			store[prev] = c
+		}
+	}
+	return nil
+}
+
+// summaryKey groups rates in the summary output.
+type summaryKey struct {
+	BillingCodeType string
+	BillingCode     string
+	NegotiatedType  string
+	BillingClass    string
+	Setting         string
+}
+
+// RateSummary accumulates a rate sketch per summaryKey while converting.
+type RateSummary struct {
+	groups map[summaryKey]*rateSketch
+}
+
+// NewRateSummary creates an empty summary.
+func NewRateSummary() *RateSummary {
+	return &RateSummary{groups: make(map[summaryKey]*rateSketch)}
+}
+
+// Add counts a rate row.
+func (s *RateSummary) Add(r *RateRow) {
+	s.sketch(summaryKey{
+		BillingCodeType: r.BillingCodeType,
+		BillingCode:     r.BillingCode,
+		NegotiatedType:  r.NegotiatedType,
+		BillingClass:    r.BillingClass,
+		Setting:         r.Setting,
+	}).Add(r.NegotiatedRate)
+}
+
+func (s *RateSummary) sketch(k summaryKey) *rateSketch {
+	sk := s.groups[k]
+	if sk == nil {
+		sk = newRateSketch()
+		s.groups[k] = sk
+	}
+	return sk
+}
+
+// AddRow merges a previously written summary row into s.
+func (s *RateSummary) AddRow(row *SummaryRow) error {
+	var sk rateSketch
+	if err := sk.UnmarshalBinary(row.Sketch); err != nil {
+		return fmt.Errorf("summary %s %s: %w", row.BillingCodeType, row.BillingCode, err)
+	}
+	s.sketch(summaryKey{
+		BillingCodeType: row.BillingCodeType,
+		BillingCode:     row.BillingCode,
+		NegotiatedType:  row.NegotiatedType,
+		BillingClass:    row.BillingClass,
+		Setting:         row.Setting,
+	}).Merge(&sk)
+	return nil
+}
+
+// Len returns the number of groups.
+func (s *RateSummary) Len() int { return len(s.groups) }
+
+// Rows returns one summary row per group, sorted by key.
+func (s *RateSummary) Rows() ([]SummaryRow, error) {
+	rows := make([]SummaryRow, 0, len(s.groups))
+	for k, sk := range s.groups {
+		enc, err := sk.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, SummaryRow{
+			BillingCodeType: k.BillingCodeType,
+			BillingCode:     k.BillingCode,
+			NegotiatedType:  k.NegotiatedType,
+			BillingClass:    k.BillingClass,
+			Setting:         k.Setting,
+			Count:           int64(sk.count),
+			Min:             sk.min,
+			P10:             sk.Quantile(0.10),
+			Median:          sk.Quantile(0.50),
+			P90:             sk.Quantile(0.90),
+			Max:             sk.max,
+			Sketch:          enc,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a.BillingCodeType != b.BillingCodeType {
+			return a.BillingCodeType < b.BillingCodeType
+		}
+		if a.BillingCode != b.BillingCode {
+			return a.BillingCode < b.BillingCode
+		}
+		if a.NegotiatedType != b.NegotiatedType {
+			return a.NegotiatedType < b.NegotiatedType
+		}
+		if a.BillingClass != b.BillingClass {
+			return a.BillingClass < b.BillingClass
+		}
+		return a.Setting < b.Setting
+	})
+	return rows, nil
+}
+
+// WriteParquet writes the summary rows to path.
+func (s *RateSummary) WriteParquet(path string) (int64, error) {
+	rows, err := s.Rows()
+	if err != nil {
+		return 0, err
+	}
+	w, err := NewSummaryParquetWriter(path)
+	if err != nil {
+		return 0, fmt.Errorf("create summary writer: %w", err)
+	}
+	for _, r := range rows {
+		if err := w.Write(r); err != nil {
+			w.Close()
+			return 0, fmt.Errorf("write summary row: %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return 0, fmt.Errorf("close summary writer: %w", err)
+	}
+	return int64(len(rows)), nil
+}
+
+// mergeSummaryFiles combines _summary.parquet files into one summary.
+func mergeSummaryFiles(paths []string) (*RateSummary, error) {
+	s := NewRateSummary()
+	for _, p := range paths {
+		err := readParquetFile(p, make([]SummaryRow, 1024), func(rows []SummaryRow) error {
+			for i := range rows {
+				if err := s.AddRow(&rows[i]); err != nil {
+					return fmt.Errorf("%s: %w", p, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestRateSketchQuantiles(t *testing.T) {
+	s := newRateSketch()
+	for i := 1; i <= 10000; i++ {
+		s.Add(float64(i))
+	}
+	if s.count != 10000 || s.min != 1 || s.max != 10000 {
+		t.Fatalf("count/min/max = %d/%v/%v", s.count, s.min, s.max)
+	}
+	for _, tc := range []struct{ q, want float64 }{
+		{0, 1}, {0.10, 1000}, {0.50, 5000}, {0.90, 9000}, {1, 10000},
+	} {
+		got := s.Quantile(tc.q)
+		if math.Abs(got-tc.want)/tc.want > sketchRelativeAccuracy+0.001 {
+			t.Errorf("Quantile(%v) = %v, want %v ±1%%", tc.q, got, tc.want)
+		}
+	}
+
+	if !math.IsNaN(newRateSketch().Quantile(0.5)) {
+		t.Error("empty sketch quantile should be NaN")
+	}
+}
+
+func TestRateSketchMergeAndEncoding(t *testing.T) {
+	whole, a, b := newRateSketch(), newRateSketch(), newRateSketch()
+	values := []float64{-5, 0, 0.01, 12.5, 99.99, 100, 250, 1e6, 37, 37}
+	for i, v := range values {
+		whole.Add(v)
+		if i%2 == 0 {
+			a.Add(v)
+		} else {
+			b.Add(v)
+		}
+	}
+	a.Merge(b)
+
+	encWhole, _ := whole.MarshalBinary()
+	encMerged, _ := a.MarshalBinary()
+	if !bytes.Equal(encWhole, encMerged) {
+		t.Error("merged sketch differs from sketch over all values")
+	}
+
+	var decoded rateSketch
+	if err := decoded.UnmarshalBinary(encWhole); err != nil {
+		t.Fatalf("UnmarshalBinary: %v", err)
+	}
+	for _, q := range []float64{0, 0.1, 0.5, 0.9, 1} {
+		if decoded.Quantile(q) != whole.Quantile(q) {
+			t.Errorf("decoded Quantile(%v) = %v, want %v", q, decoded.Quantile(q), whole.Quantile(q))
+		}
+	}
+	if decoded.Quantile(0) != -5 || decoded.Quantile(1) != 1e6 {
+		t.Errorf("decoded min/max = %v/%v", decoded.Quantile(0), decoded.Quantile(1))
+	}
+
+	if err := decoded.UnmarshalBinary([]byte{sketchVersion, 5}); err == nil {
+		t.Error("expected error for truncated sketch")
+	}
+	if err := decoded.UnmarshalBinary([]byte{99}); err == nil {
+		t.Error("expected error for unknown version")
+	}
+}
+
+func TestSummaryOutput(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "out")
+	res, err := convertFile(filepath.Join(examplesDir, "in-network-rates-fee-for-service-single-plan-sample.json"),
+		base, ConvertOptions{BufferSize: 1 << 16, Summary: true})
+	if err != nil {
+		t.Fatalf("convertFile: %v", err)
+	}
+	if res.Outputs.Summary != base+"_summary.parquet" {
+		t.Fatalf("summary output = %q", res.Outputs.Summary)
+	}
+	rows := readParquetRows[SummaryRow](t, res.Outputs.Summary)
+	if int64(len(rows)) != res.Stats.SummaryRows || len(rows) == 0 {
+		t.Fatalf("summary rows = %d, stats %d", len(rows), res.Stats.SummaryRows)
+	}
+
+	rates := readParquetRows[RateRow](t, res.Outputs.Rates)
+	var total int64
+	for _, r := range rows {
+		total += r.Count
+		if r.Min > r.P10 || r.P10 > r.Median || r.Median > r.P90 || r.P90 > r.Max {
+			t.Errorf("unordered stats %+v", r)
+		}
+		// Every group's min and max match the rate rows exactly.
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, rate := range rates {
+			if rate.BillingCodeType == r.BillingCodeType && rate.BillingCode == r.BillingCode &&
+				rate.NegotiatedType == r.NegotiatedType && rate.BillingClass == r.BillingClass &&
+				rate.Setting == r.Setting {
+				min, max = math.Min(min, rate.NegotiatedRate), math.Max(max, rate.NegotiatedRate)
+			}
+		}
+		if r.Min != min || r.Max != max {
+			t.Errorf("%s %s: min/max = %v/%v, want %v/%v", r.BillingCode, r.BillingClass, r.Min, r.Max, min, max)
+		}
+	}
+	if total != int64(len(rates)) {
+		t.Errorf("summary count total = %d, want %d rate rows", total, len(rates))
+	}
+
+	// Merging a summary with itself doubles counts and keeps quantiles.
+	merged, err := mergeSummaryFiles([]string{res.Outputs.Summary, res.Outputs.Summary})
+	if err != nil {
+		t.Fatalf("mergeSummaryFiles: %v", err)
+	}
+	mrows, err := merged.Rows()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mrows) != len(rows) {
+		t.Fatalf("merged rows = %d, want %d", len(mrows), len(rows))
+	}
+	for i := range rows {
+		if mrows[i].Count != 2*rows[i].Count || mrows[i].Min != rows[i].Min || mrows[i].Max != rows[i].Max {
+			t.Errorf("merged row %d = %+v, from %+v", i, mrows[i], rows[i])
+		}
+	}
+}
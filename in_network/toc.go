@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// TOC extraction row types, matching mrfparser's Parquet outputs.
+
+// tocPlanParquet is mrfparser's denormalized plan row.
+type tocPlanParquet struct {
+	PlanName       string   `parquet:"plan_name"`
+	PlanIDType     string   `parquet:"plan_id_type"`
+	PlanID         string   `parquet:"plan_id"`
+	PlanMarketType string   `parquet:"plan_market_type"`
+	IssuerName     string   `parquet:"issuer_name"`
+	Description    string   `parquet:"description"`
+	InNetworkURLs  []string `parquet:"in_network_urls,list"`
+}
+
+// tocNormalizedPlanParquet is mrfparser's normalized plan row; URLs live
+// in the sibling <base>_urls.parquet keyed by reporting_structure_id.
+type tocNormalizedPlanParquet struct {
+	ReportingStructureID int64  `parquet:"reporting_structure_id"`
+	PlanName             string `parquet:"plan_name"`
+	PlanIDType           string `parquet:"plan_id_type"`
+	PlanID               string `parquet:"plan_id"`
+	PlanMarketType       string `parquet:"plan_market_type"`
+	IssuerName           string `parquet:"issuer_name"`
+	Description          string `parquet:"description"`
+}
+
+type tocURLParquet struct {
+	ReportingStructureID int64  `parquet:"reporting_structure_id"`
+	URL                  string `parquet:"url"`
+}
+
+// tocJSONFile is mrfparser's JSON output.
+type tocJSONFile struct {
+	Plans []struct {
+		PlanName       string   `json:"plan_name"`
+		PlanIDType     string   `json:"plan_id_type"`
+		PlanID         string   `json:"plan_id"`
+		PlanMarketType string   `json:"plan_market_type"`
+		IssuerName     string   `json:"issuer_name"`
+		Description    string   `json:"description"`
+		InNetworkURLs  []string `json:"in_network_urls"`
+	} `json:"plans"`
+}
+
+// TOCIndex maps in-network file URLs to the plans that reference them.
+// It is read-only once loaded and safe to share across conversions.
+type TOCIndex struct {
+	byURL  map[string][]PlanRow // plan rows (InNetworkFile unset) per URL
+	byName map[string][]string  // tocFileKey → URLs
+	plans  int
+}
+
+// URLs returns the number of distinct in-network URLs indexed.
+func (x *TOCIndex) URLs() int { return len(x.byURL) }
+
+// Plans returns the number of plan/URL pairs indexed.
+func (x *TOCIndex) Plans() int { return x.plans }
+
+func (x *TOCIndex) add(u string, row PlanRow) {
+	if x.byURL == nil {
+		x.byURL = make(map[string][]PlanRow)
+		x.byName = make(map[string][]string)
+	}
+	row.SourceURL = u
+	rows, seen := x.byURL[u]
+	for _, r := range rows {
+		if r == row {
+			return
+		}
+	}
+	if !seen {
+		key := tocFileKey(u)
+		x.byName[key] = append(x.byName[key], u)
+	}
+	x.byURL[u] = append(rows, row)
+	x.plans++
+}
+
+// tocFileKey reduces a URL or local path to its file name without query
+// string or .json/.gz extensions, so a downloaded file matches its URL.
+func tocFileKey(s string) string {
+	name := s
+	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
+		name = path.Base(u.Path)
+	} else {
+		name = filepath.Base(s)
+	}
+	lower := strings.ToLower(name)
+	for _, ext := range []string{".gz", ".json"} {
+		if strings.HasSuffix(lower, ext) {
+			name = name[:len(name)-len(ext)]
+			lower = lower[:len(lower)-len(ext)]
+		}
+	}
+	return name
+}
+
+// PlansFor returns the plans referencing input. With sourceURL set, only
+// that exact URL matches; otherwise the indexed URL with the same file name
+// does. Several URLs sharing the file name (payers reuse generic names in
+// different directories) are an error, since input could be any of them.
+// InNetworkFile is set to inNetworkFile on each row.
+func (x *TOCIndex) PlansFor(input, sourceURL, inNetworkFile string) ([]PlanRow, error) {
+	u := sourceURL
+	if u == "" {
+		urls := x.byName[tocFileKey(input)]
+		if len(urls) > 1 {
+			return nil, fmt.Errorf("%s matches %d TOC URLs by file name (%s, %s, ...); use -source-url to pick one",
+				filepath.Base(input), len(urls), urls[0], urls[1])
+		}
+		if len(urls) == 0 {
+			return nil, nil
+		}
+		u = urls[0]
+	}
+	var out []PlanRow
+	for _, r := range x.byURL[u] {
+		r.InNetworkFile = inNetworkFile
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// LoadTOCIndex reads an mrfparser extraction: JSON (-format json), or
+// Parquet (-format parquet), either denormalized with an in_network_urls
+// list or normalized with a sibling <base>_urls.parquet.
+func LoadTOCIndex(p string) (*TOCIndex, error) {
+	if strings.HasSuffix(strings.ToLower(p), ".parquet") {
+		return loadTOCParquet(p)
+	}
+
+	r, closeInput, err := openInput(p, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("open TOC extraction: %w", err)
+	}
+	defer closeInput()
+
+	var doc tocJSONFile
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse TOC extraction %s: %w", p, err)
+	}
+	x := &TOCIndex{}
+	for _, pl := range doc.Plans {
+		row := PlanRow{
+			PlanName:       pl.PlanName,
+			IssuerName:     pl.IssuerName,
+			PlanIDType:     pl.PlanIDType,
+			PlanID:         pl.PlanID,
+			PlanMarketType: pl.PlanMarketType,
+			Description:    pl.Description,
+		}
+		for _, u := range pl.InNetworkURLs {
+			x.add(u, row)
+		}
+	}
+	return x, nil
+}
+
+func loadTOCParquet(p string) (*TOCIndex, error) {
+	plansPath := strings.TrimSuffix(p, "_urls.parquet")
+	if plansPath != p {
+		plansPath += ".parquet"
+	}
+
+	f, err := os.Open(plansPath)
+	if err != nil {
+		return nil, fmt.Errorf("open TOC extraction: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("stat TOC extraction: %w", err)
+	}
+	pf, err := parquet.OpenFile(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("open TOC parquet %s: %w", plansPath, err)
+	}
+	denormalized := false
+	for _, field := range pf.Schema().Fields() {
+		if field.Name() == "in_network_urls" {
+			denormalized = true
+		}
+	}
+	f.Close()
+
+	x := &TOCIndex{}
+	if denormalized {
+		err := readParquetFile(plansPath, make([]tocPlanParquet, 1024), func(rows []tocPlanParquet) error {
+			for _, pl := range rows {
+				row := PlanRow{
+					PlanName:       pl.PlanName,
+					IssuerName:     pl.IssuerName,
+					PlanIDType:     pl.PlanIDType,
+					PlanID:         pl.PlanID,
+					PlanMarketType: pl.PlanMarketType,
+					Description:    pl.Description,
+				}
+				for _, u := range pl.InNetworkURLs {
+					x.add(u, row)
+				}
+			}
+			return nil
+		})
+		return x, err
+	}
+
+	urlsPath := strings.TrimSuffix(plansPath, ".parquet") + "_urls.parquet"
+	urls := make(map[int64][]string)
+	err = readParquetFile(urlsPath, make([]tocURLParquet, 4096), func(rows []tocURLParquet) error {
+		for _, r := range rows {
+			urls[r.ReportingStructureID] = append(urls[r.ReportingStructureID], r.URL)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read TOC URLs (normalized extraction needs %s): %w", filepath.Base(urlsPath), err)
+	}
+	err = readParquetFile(plansPath, make([]tocNormalizedPlanParquet, 1024), func(rows []tocNormalizedPlanParquet) error {
+		for _, pl := range rows {
+			row := PlanRow{
+				PlanName:       pl.PlanName,
+				IssuerName:     pl.IssuerName,
+				PlanIDType:     pl.PlanIDType,
+				PlanID:         pl.PlanID,
+				PlanMarketType: pl.PlanMarketType,
+				Description:    pl.Description,
+			}
+			for _, u := range urls[pl.ReportingStructureID] {
+				x.add(u, row)
+			}
+		}
+		return nil
+	})
+	return x, err
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+const (
+	multiPlanSample = "in-network-rates-multiple-plans-sample.json"
+	multiPlanURL    = "https://cdn.example.com/2024-01/in-network-rates-multiple-plans-sample.json.gz?sig=abc"
+	otherURL        = "https://cdn.example.com/2024-01/other-in-network-rates.json.gz"
+)
+
+// tocFixturePlans: two plans reference the multi-plan sample (one listed
+// twice), one references another file.
+var tocFixturePlans = []tocPlanParquet{
+	{PlanName: "Silver PPO", PlanIDType: "hios", PlanID: "12345NY0010001", PlanMarketType: "individual",
+		IssuerName: "Acme", Description: "NY individual", InNetworkURLs: []string{multiPlanURL}},
+	{PlanName: "Employer Group", PlanIDType: "ein", PlanID: "12-3456789", PlanMarketType: "group",
+		IssuerName: "Acme", Description: "Group plans", InNetworkURLs: []string{multiPlanURL, otherURL}},
+	{PlanName: "Silver PPO", PlanIDType: "hios", PlanID: "12345NY0010001", PlanMarketType: "individual",
+		IssuerName: "Acme", Description: "NY individual", InNetworkURLs: []string{multiPlanURL}},
+	{PlanName: "Gold HMO", PlanIDType: "hios", PlanID: "12345NY0020001", PlanMarketType: "individual",
+		IssuerName: "Acme", Description: "Other file only", InNetworkURLs: []string{otherURL}},
+}
+
+func writeTOCJSON(t *testing.T, dir string) string {
+	t.Helper()
+	var doc struct {
+		ReportingEntityName string           `json:"reporting_entity_name"`
+		Plans               []map[string]any `json:"plans"`
+	}
+	doc.ReportingEntityName = "Acme"
+	for _, p := range tocFixturePlans {
+		doc.Plans = append(doc.Plans, map[string]any{
+			"plan_name": p.PlanName, "plan_id_type": p.PlanIDType, "plan_id": p.PlanID,
+			"plan_market_type": p.PlanMarketType, "issuer_name": p.IssuerName,
+			"description": p.Description, "in_network_urls": p.InNetworkURLs,
+		})
+	}
+	data, _ := json.Marshal(doc)
+	path := filepath.Join(dir, "ny_plans.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeTOCParquet(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "ny_plans.parquet")
+	if err := parquet.WriteFile(path, tocFixturePlans); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func writeTOCNormalized(t *testing.T, dir string) string {
+	t.Helper()
+	var (
+		plans []tocNormalizedPlanParquet
+		urls  []tocURLParquet
+	)
+	for i, p := range tocFixturePlans {
+		id := int64(i + 1)
+		plans = append(plans, tocNormalizedPlanParquet{
+			ReportingStructureID: id, PlanName: p.PlanName, PlanIDType: p.PlanIDType, PlanID: p.PlanID,
+			PlanMarketType: p.PlanMarketType, IssuerName: p.IssuerName, Description: p.Description,
+		})
+		for _, u := range p.InNetworkURLs {
+			urls = append(urls, tocURLParquet{ReportingStructureID: id, URL: u})
+		}
+	}
+	path := filepath.Join(dir, "ny_plans_norm.parquet")
+	if err := parquet.WriteFile(path, plans); err != nil {
+		t.Fatal(err)
+	}
+	if err := parquet.WriteFile(filepath.Join(dir, "ny_plans_norm_urls.parquet"), urls); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func convertWithTOC(t *testing.T, toc *TOCIndex, sourceURL string) []PlanRow {
+	t.Helper()
+	base := filepath.Join(t.TempDir(), "in-network-rates-multiple-plans-sample")
+	res, err := convertFile(filepath.Join(examplesDir, multiPlanSample), base,
+		ConvertOptions{BufferSize: 1 << 16, TOC: toc, SourceURL: sourceURL})
+	if err != nil {
+		t.Fatalf("convertFile: %v", err)
+	}
+	if res.Outputs.Plans != base+"_plans.parquet" {
+		t.Fatalf("plans output = %q", res.Outputs.Plans)
+	}
+	rows := readParquetRows[PlanRow](t, res.Outputs.Plans)
+	if int64(len(rows)) != res.Stats.PlanRows {
+		t.Errorf("PlanRows = %d, file has %d", res.Stats.PlanRows, len(rows))
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].PlanID < rows[j].PlanID })
+	return rows
+}
+
+func TestPlansBridge(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name string
+		path string
+	}{
+		{"json", writeTOCJSON(t, dir)},
+		{"parquet", writeTOCParquet(t, dir)},
+		{"normalized parquet", writeTOCNormalized(t, dir)},
+		{"normalized parquet via urls file", filepath.Join(dir, "ny_plans_norm_urls.parquet")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			toc, err := LoadTOCIndex(tc.path)
+			if err != nil {
+				t.Fatalf("LoadTOCIndex: %v", err)
+			}
+			if toc.URLs() != 2 || toc.Plans() != 4 {
+				t.Errorf("index = %d URLs, %d plans; want 2, 4", toc.URLs(), toc.Plans())
+			}
+
+			rows := convertWithTOC(t, toc, "")
+			if len(rows) != 2 {
+				t.Fatalf("plan rows = %+v, want 2", rows)
+			}
+			if rows[0].PlanIDType != "ein" || rows[0].PlanID != "12-3456789" ||
+				rows[1].PlanIDType != "hios" || rows[1].PlanID != "12345NY0010001" {
+				t.Errorf("plans = %+v", rows)
+			}
+			for _, r := range rows {
+				if r.InNetworkFile != "in-network-rates-multiple-plans-sample" || r.SourceURL != multiPlanURL {
+					t.Errorf("bridge keys = %q, %q", r.InNetworkFile, r.SourceURL)
+				}
+			}
+		})
+	}
+}
+
+func TestPlansBridgeSourceURL(t *testing.T) {
+	toc, err := LoadTOCIndex(writeTOCJSON(t, t.TempDir()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An explicit URL overrides file name matching.
+	rows := convertWithTOC(t, toc, otherURL)
+	if len(rows) != 2 || rows[1].PlanID != "12345NY0020001" {
+		t.Errorf("plans for %s = %+v", otherURL, rows)
+	}
+	if rows := convertWithTOC(t, toc, "https://cdn.example.com/unknown.json"); len(rows) != 0 {
+		t.Errorf("unknown URL matched %+v", rows)
+	}
+}
+
+func TestPlansForSharedFileName(t *testing.T) {
+	east := "https://cdn.example.com/east/in-network-rates.json.gz"
+	west := "https://cdn.example.com/west/in-network-rates.json.gz"
+	x := &TOCIndex{}
+	x.add(east, PlanRow{PlanIDType: "hios", PlanID: "12345NY0010001"})
+	x.add(west, PlanRow{PlanIDType: "ein", PlanID: "98-7654321"})
+
+	if rows, err := x.PlansFor("/data/in-network-rates.json.gz", "", "in-network-rates"); err == nil {
+		t.Errorf("ambiguous file name matched %+v", rows)
+	}
+	rows, err := x.PlansFor("/data/in-network-rates.json.gz", west, "in-network-rates")
+	if err != nil {
+		t.Fatalf("PlansFor with -source-url: %v", err)
+	}
+	if len(rows) != 1 || rows[0].PlanID != "98-7654321" || rows[0].SourceURL != west {
+		t.Errorf("plans for %s = %+v", west, rows)
+	}
+}
+
+func TestTOCFileKey(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://cdn.example.com/a/b/2024-01_rates.json.gz?X-Amz-Signature=abc": "2024-01_rates",
+		"https://cdn.example.com/a/b/2024-01_rates.JSON":                        "2024-01_rates",
+		"/data/drop/2024-01_rates.json.gz":                                      "2024-01_rates",
+		"2024-01_rates.json":                                                    "2024-01_rates",
+	} {
+		if got := tocFileKey(in); got != want {
+			t.Errorf("tocFileKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -21,12 +21,22 @@ type ProviderReference struct {
 	ProviderGroups  []ProviderGroup `json:"provider_groups"`
 }
 
-// ProviderGroup contains a TIN and list of NPIs.
+// ProviderGroup contains a TIN and list of NPIs (the schema's providers
+// object). Contracts made only at the TIN level report "npi": [0].
 type ProviderGroup struct {
 	NPI []int64 `json:"npi"`
 	TIN TIN     `json:"tin"`
 }
 
+// NPIs returns the group's NPIs, or [0] for a TIN-only group that lists
+// none, so the TIN still produces a provider row.
+func (pg ProviderGroup) NPIs() []int64 {
+	if len(pg.NPI) == 0 {
+		return []int64{0}
+	}
+	return pg.NPI
+}
+
 // TIN contains tax identification number details.
 type TIN struct {
 	Type         string `json:"type"`
@@ -40,6 +50,7 @@ type InNetworkItem struct {
 	Name                   string           `json:"name"`
 	BillingCodeType        string           `json:"billing_code_type"`
 	BillingCodeTypeVersion string           `json:"billing_code_type_version"`
+	SeverityOfIllness      string           `json:"severity_of_illness"` // DRG rates that depend on SOI
 	BillingCode            string           `json:"billing_code"`
 	Description            string           `json:"description"`
 	NegotiatedRates        []NegotiatedRate `json:"negotiated_rates"`
@@ -57,7 +68,11 @@ type NegotiatedRate struct {
 	NegotiatedPrices   []NegotiatedPrice `json:"negotiated_prices"`
 }
 
-// NegotiatedPrice contains a single negotiated price.
+// NegotiatedPrice contains a single negotiated price. NegotiatedRate is a
+// dollar amount except for the "percentage" type, where it is a whole
+// percentage of billed charges (40.5 means 40.5%); see RateUnit.
+// BillingCodeModifier qualifies the item's own billing_code, so on a
+// bundle it applies to the bundle as a whole, not to its bundled_codes.
 type NegotiatedPrice struct {
 	NegotiatedType        string   `json:"negotiated_type"`
 	NegotiatedRate        float64  `json:"negotiated_rate"`
@@ -76,3 +91,31 @@ type ContainedCode struct {
 	BillingCode            string `json:"billing_code"`
 	Description            string `json:"description"`
 }
+
+// Negotiated types (negotiated_price.negotiated_type).
+const (
+	NegotiatedTypeNegotiated  = "negotiated"   // contracted dollar amount
+	NegotiatedTypeDerived     = "derived"      // dollar price for internal accounting or 45 CFR 153.710(c) reporting
+	NegotiatedTypeFeeSchedule = "fee schedule" // dollar rate used for cost sharing when it differs from the negotiated rate
+	NegotiatedTypePercentage  = "percentage"   // whole percentage of billed charges
+	NegotiatedTypePerDiem     = "per diem"     // dollar amount per day
+)
+
+// Units of RateRow.NegotiatedRate, derived from the negotiated type.
+const (
+	RateUnitDollars       = "dollars"
+	RateUnitDollarsPerDay = "dollars_per_day"
+	RateUnitPercent       = "percent_of_billed_charges"
+)
+
+// RateUnit returns the unit negotiated_rate is expressed in for a
+// negotiated type. Unknown types are assumed to be dollar amounts.
+func RateUnit(negotiatedType string) string {
+	switch negotiatedType {
+	case NegotiatedTypePercentage:
+		return RateUnitPercent
+	case NegotiatedTypePerDiem:
+		return RateUnitDollarsPerDay
+	}
+	return RateUnitDollars
+}
@@ -0,0 +1,633 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+//go:embed schemas/in-network-rates.json
+var inNetworkSchemaJSON []byte
+
+// Rule names used in addition to the JSON Schema keywords (required, enum,
+// pattern, ...) that a violation is reported under.
+const (
+	RuleUnknownProviderReference = "provider_reference"
+	RuleDuplicateProviderGroupID = "unique_provider_group_id"
+)
+
+// Violation aggregates every occurrence of one rule failing at one JSON path.
+// Path has array indices elided (in_network[].name); Example is the first
+// concrete location (in_network[3].name).
+type Violation struct {
+	Path    string `json:"path"`
+	Rule    string `json:"rule"`
+	Count   int64  `json:"count"`
+	Example string `json:"example"`
+	Message string `json:"message"`
+}
+
+// ValidationReport is the result of validating one in-network file.
+type ValidationReport struct {
+	InNetworkItems     int64       `json:"in_network_items"`
+	NegotiatedRates    int64       `json:"negotiated_rates"`
+	NegotiatedPrices   int64       `json:"negotiated_prices"`
+	ProviderReferences int64       `json:"provider_references"`
+	Violations         []Violation `json:"violations"`
+
+	index map[string]int
+}
+
+// Valid reports whether no violations were found.
+func (r *ValidationReport) Valid() bool { return len(r.Violations) == 0 }
+
+// TotalViolations returns the number of individual violations.
+func (r *ValidationReport) TotalViolations() int64 {
+	var n int64
+	for _, v := range r.Violations {
+		n += v.Count
+	}
+	return n
+}
+
+var arrayIndex = regexp.MustCompile(`\[\d+\]`)
+
+func (r *ValidationReport) add(path, rule, msg string, count int64) {
+	key := arrayIndex.ReplaceAllString(path, "[]")
+	k := key + "\x00" + rule
+	if i, ok := r.index[k]; ok {
+		r.Violations[i].Count += count
+		return
+	}
+	if r.index == nil {
+		r.index = make(map[string]int)
+	}
+	r.index[k] = len(r.Violations)
+	r.Violations = append(r.Violations, Violation{
+		Path: key, Rule: rule, Count: count, Example: path, Message: msg,
+	})
+}
+
+// sort orders violations by descending count, then path and rule.
+func (r *ValidationReport) sort() {
+	sort.SliceStable(r.Violations, func(i, j int) bool {
+		a, b := r.Violations[i], r.Violations[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Rule < b.Rule
+	})
+	r.index = nil
+}
+
+// WriteText writes a human-readable report.
+func (r *ValidationReport) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Checked %d in-network items, %d negotiated rates, %d negotiated prices, %d provider references\n",
+		r.InNetworkItems, r.NegotiatedRates, r.NegotiatedPrices, r.ProviderReferences)
+	if r.Valid() {
+		_, err := fmt.Fprintln(w, "No schema violations found")
+		return err
+	}
+	fmt.Fprintf(w, "%d violations (%d distinct path/rule pairs)\n\n", r.TotalViolations(), len(r.Violations))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COUNT\tRULE\tPATH\tFIRST EXAMPLE")
+	for _, v := range r.Violations {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s: %s\n", v.Count, v.Rule, v.Path, v.Example, v.Message)
+	}
+	return tw.Flush()
+}
+
+// Validator streams an in-network rates file and checks it against the
+// bundled CMS schema, one in_network item at a time. Provider reference
+// IDs are cross-checked even when provider_references follows in_network.
+type Validator struct {
+	decoder *json.Decoder
+	schema  *jsonSchema
+	report  *ValidationReport
+
+	knownGroups map[int64]bool
+	pendingRefs map[int64]*pendingRef
+}
+
+// pendingRef is a referenced provider group ID not yet defined.
+type pendingRef struct {
+	count   int64
+	example string
+}
+
+// NewValidator creates a validator reading from r.
+func NewValidator(r io.Reader) (*Validator, error) {
+	schema, err := parseJSONSchema(inNetworkSchemaJSON)
+	if err != nil {
+		return nil, fmt.Errorf("parse bundled schema: %w", err)
+	}
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	return &Validator{
+		decoder:     d,
+		schema:      schema,
+		report:      &ValidationReport{},
+		knownGroups: make(map[int64]bool),
+		pendingRefs: make(map[int64]*pendingRef),
+	}, nil
+}
+
+// Validate reads the whole input and returns the report. An error is
+// returned only when the input is not well-formed JSON.
+func (v *Validator) Validate() (*ValidationReport, error) {
+	t, err := v.decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("read opening token: %w", err)
+	}
+	if d, ok := t.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("expected {, got %v", t)
+	}
+
+	root := v.schema.root
+	rootProps, _ := root["properties"].(map[string]any)
+	rootVals := make(map[string]any)
+
+	for v.decoder.More() {
+		t, err := v.decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("read field name: %w", err)
+		}
+		field, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected field name, got %T", t)
+		}
+		prop, _ := rootProps[field].(map[string]any)
+
+		switch field {
+		case "provider_references", "in_network":
+			rootVals[field] = true
+			items, _ := prop["items"].(map[string]any)
+			n, err := v.streamArray(field, func(path string, elem any) {
+				if items != nil {
+					v.schema.validate(elem, items, path, v.report.add)
+				}
+				if field == "in_network" {
+					v.checkItem(elem, path)
+				} else {
+					v.checkProviderReference(elem, path)
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+			if field == "in_network" {
+				v.report.InNetworkItems += n
+				if n == 0 {
+					v.report.add(field, "minItems", "in_network is empty", 1)
+				}
+			} else {
+				v.report.ProviderReferences += n
+			}
+		default:
+			var val any
+			if err := v.decoder.Decode(&val); err != nil {
+				return nil, fmt.Errorf("decode %s: %w", field, err)
+			}
+			rootVals[field] = val
+			if prop != nil {
+				v.schema.validate(val, prop, field, v.report.add)
+			}
+		}
+	}
+	if _, err := v.decoder.Token(); err != nil {
+		return nil, fmt.Errorf("read closing token: %w", err)
+	}
+
+	// Object-level root rules (required, dependencies, if/then) on the
+	// scalar fields; the arrays were checked as they streamed.
+	rootRules := make(map[string]any, len(root))
+	for k, val := range root {
+		if k != "properties" {
+			rootRules[k] = val
+		}
+	}
+	v.schema.validate(rootVals, rootRules, "", v.report.add)
+
+	ids := make([]int64, 0, len(v.pendingRefs))
+	for id := range v.pendingRefs {
+		if !v.knownGroups[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		p := v.pendingRefs[id]
+		v.report.add(p.example, RuleUnknownProviderReference,
+			fmt.Sprintf("provider group %d is not defined in provider_references", id), p.count)
+	}
+
+	v.report.sort()
+	return v.report, nil
+}
+
+// streamArray decodes each element of the array value of field and passes
+// it to fn with its concrete path. It returns the element count.
+func (v *Validator) streamArray(field string, fn func(path string, elem any)) (int64, error) {
+	t, err := v.decoder.Token()
+	if err != nil {
+		return 0, fmt.Errorf("read %s: %w", field, err)
+	}
+	if d, ok := t.(json.Delim); !ok || d != '[' {
+		// Not an array: validate the value as-is against the root schema.
+		v.report.add(field, "type", fmt.Sprintf("expected array, got %s", jsonTypeName(t)), 1)
+		if d, ok := t.(json.Delim); ok && d == '{' {
+			var skip json.RawMessage
+			for v.decoder.More() {
+				if _, err := v.decoder.Token(); err != nil {
+					return 0, err
+				}
+				if err := v.decoder.Decode(&skip); err != nil {
+					return 0, err
+				}
+			}
+			_, err = v.decoder.Token()
+		}
+		return 0, err
+	}
+	var n int64
+	for v.decoder.More() {
+		var elem any
+		if err := v.decoder.Decode(&elem); err != nil {
+			return n, fmt.Errorf("decode %s[%d]: %w", field, n, err)
+		}
+		fn(fmt.Sprintf("%s[%d]", field, n), elem)
+		n++
+	}
+	_, err = v.decoder.Token()
+	return n, err
+}
+
+// checkProviderReference records a defined provider group ID.
+func (v *Validator) checkProviderReference(elem any, path string) {
+	obj, ok := elem.(map[string]any)
+	if !ok {
+		return
+	}
+	id, ok := jsonInt(obj["provider_group_id"])
+	if !ok {
+		return
+	}
+	if v.knownGroups[id] {
+		v.report.add(path+".provider_group_id", RuleDuplicateProviderGroupID,
+			fmt.Sprintf("provider group %d is defined more than once", id), 1)
+		return
+	}
+	v.knownGroups[id] = true
+}
+
+// checkItem counts rates and prices and records provider references.
+func (v *Validator) checkItem(elem any, path string) {
+	obj, ok := elem.(map[string]any)
+	if !ok {
+		return
+	}
+	rates, _ := obj["negotiated_rates"].([]any)
+	v.report.NegotiatedRates += int64(len(rates))
+	for i, r := range rates {
+		rate, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		prices, _ := rate["negotiated_prices"].([]any)
+		v.report.NegotiatedPrices += int64(len(prices))
+
+		refs, _ := rate["provider_references"].([]any)
+		for j, ref := range refs {
+			id, ok := jsonInt(ref)
+			if !ok || v.knownGroups[id] {
+				continue
+			}
+			p := v.pendingRefs[id]
+			if p == nil {
+				p = &pendingRef{example: fmt.Sprintf("%s.negotiated_rates[%d].provider_references[%d]", path, i, j)}
+				v.pendingRefs[id] = p
+			}
+			p.count++
+		}
+	}
+}
+
+// jsonSchema interprets the subset of JSON Schema draft-07 used by the CMS
+// price transparency schemas.
+type jsonSchema struct {
+	root     map[string]any
+	defs     map[string]any
+	patterns map[string]*regexp.Regexp
+}
+
+func parseJSONSchema(data []byte) (*jsonSchema, error) {
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	defs, _ := root["definitions"].(map[string]any)
+	return &jsonSchema{root: root, defs: defs, patterns: make(map[string]*regexp.Regexp)}, nil
+}
+
+type emitFunc func(path, rule, msg string, count int64)
+
+// matches reports whether val validates against node without recording
+// violations (used by oneOf and if).
+func (s *jsonSchema) matches(val any, node map[string]any) bool {
+	ok := true
+	s.validate(val, node, "", func(string, string, string, int64) { ok = false })
+	return ok
+}
+
+// validate checks val against node, calling emit for each violation.
+func (s *jsonSchema) validate(val any, node map[string]any, path string, emit emitFunc) {
+	if ref, ok := node["$ref"].(string); ok {
+		def, _ := s.defs[strings.TrimPrefix(ref, "#/definitions/")].(map[string]any)
+		if def != nil {
+			s.validate(val, def, path, emit)
+		}
+		return
+	}
+
+	if typ, ok := node["type"].(string); ok && !jsonTypeMatches(val, typ) {
+		emit(path, "type", fmt.Sprintf("expected %s, got %s", typ, jsonTypeName(val)), 1)
+		return
+	}
+	if enum, ok := node["enum"].([]any); ok && !enumContains(enum, val) {
+		emit(path, "enum", fmt.Sprintf("%s is not one of %s", jsonString(val), jsonString(enum)), 1)
+	}
+	if c, ok := node["const"]; ok && jsonString(c) != jsonString(val) {
+		emit(path, "const", fmt.Sprintf("expected %s, got %s", jsonString(c), jsonString(val)), 1)
+	}
+
+	switch x := val.(type) {
+	case string:
+		s.validateString(x, node, path, emit)
+	case json.Number:
+		s.validateNumber(x, node, path, emit)
+	case []any:
+		s.validateArray(x, node, path, emit)
+	case map[string]any:
+		s.validateObject(x, node, path, emit)
+	}
+
+	if branches, ok := node["oneOf"].([]any); ok {
+		n := 0
+		for _, b := range branches {
+			if bn, ok := b.(map[string]any); ok && s.matches(val, bn) {
+				n++
+			}
+		}
+		if n != 1 {
+			emit(path, "oneOf", fmt.Sprintf("matches %d of %d allowed forms", n, len(branches)), 1)
+		}
+	}
+	if cond, ok := node["if"].(map[string]any); ok {
+		branch := "else"
+		if s.matches(val, cond) {
+			branch = "then"
+		}
+		if bn, ok := node[branch].(map[string]any); ok {
+			s.validate(val, bn, path, emit)
+		}
+	}
+}
+
+func (s *jsonSchema) validateString(x string, node map[string]any, path string, emit emitFunc) {
+	n := len([]rune(x))
+	if min, ok := jsonFloat(node["minLength"]); ok && float64(n) < min {
+		emit(path, "minLength", fmt.Sprintf("length %d is less than %v", n, min), 1)
+	}
+	if max, ok := jsonFloat(node["maxLength"]); ok && float64(n) > max {
+		emit(path, "maxLength", fmt.Sprintf("length %d is greater than %v", n, max), 1)
+	}
+	if p, ok := node["pattern"].(string); ok {
+		re := s.patterns[p]
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(p); err != nil {
+				return
+			}
+			s.patterns[p] = re
+		}
+		if !re.MatchString(x) {
+			emit(path, "pattern", fmt.Sprintf("%q does not match %s", x, p), 1)
+		}
+	}
+	if f, _ := node["format"].(string); f == "date" {
+		if _, err := time.Parse("2006-01-02", x); err != nil {
+			emit(path, "format", fmt.Sprintf("%q is not a YYYY-MM-DD date", x), 1)
+		}
+	}
+}
+
+func (s *jsonSchema) validateNumber(x json.Number, node map[string]any, path string, emit emitFunc) {
+	f, err := x.Float64()
+	if err != nil {
+		return
+	}
+	if min, ok := jsonFloat(node["minimum"]); ok && f < min {
+		emit(path, "minimum", fmt.Sprintf("%s is less than %v", x, min), 1)
+	}
+	if max, ok := jsonFloat(node["maximum"]); ok && f > max {
+		emit(path, "maximum", fmt.Sprintf("%s is greater than %v", x, max), 1)
+	}
+	if min, ok := jsonFloat(node["exclusiveMinimum"]); ok && f <= min {
+		emit(path, "exclusiveMinimum", fmt.Sprintf("%s must be greater than %v", x, min), 1)
+	}
+}
+
+func (s *jsonSchema) validateArray(x []any, node map[string]any, path string, emit emitFunc) {
+	if min, ok := jsonFloat(node["minItems"]); ok && float64(len(x)) < min {
+		emit(path, "minItems", fmt.Sprintf("has %d items, want at least %v", len(x), min), 1)
+	}
+	if max, ok := jsonFloat(node["maxItems"]); ok && float64(len(x)) > max {
+		emit(path, "maxItems", fmt.Sprintf("has %d items, want at most %v", len(x), max), 1)
+	}
+	if u, _ := node["uniqueItems"].(bool); u && len(x) > 1 {
+		seen := make(map[string]int, len(x))
+		for i, e := range x {
+			k := jsonString(e)
+			if j, dup := seen[k]; dup {
+				emit(fmt.Sprintf("%s[%d]", path, i), "uniqueItems",
+					fmt.Sprintf("duplicates item %d", j), 1)
+				continue
+			}
+			seen[k] = i
+		}
+	}
+	if items, ok := node["items"].(map[string]any); ok {
+		for i, e := range x {
+			s.validate(e, items, fmt.Sprintf("%s[%d]", path, i), emit)
+		}
+	}
+}
+
+func (s *jsonSchema) validateObject(x map[string]any, node map[string]any, path string, emit emitFunc) {
+	if req, ok := node["required"].([]any); ok {
+		for _, r := range req {
+			k, _ := r.(string)
+			if _, present := x[k]; !present {
+				emit(joinPath(path, k), "required", fmt.Sprintf("%s is required", k), 1)
+			}
+		}
+	}
+	if deps, ok := node["dependencies"].(map[string]any); ok {
+		keys := make([]string, 0, len(deps))
+		for k := range deps {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, present := x[k]; !present {
+				continue
+			}
+			need, _ := deps[k].([]any)
+			for _, r := range need {
+				d, _ := r.(string)
+				if _, present := x[d]; !present {
+					emit(joinPath(path, d), "dependencies", fmt.Sprintf("%s is required when %s is present", d, k), 1)
+				}
+			}
+		}
+	}
+	if props, ok := node["properties"].(map[string]any); ok {
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if pn, ok := props[k].(map[string]any); ok {
+				s.validate(x[k], pn, joinPath(path, k), emit)
+			}
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func jsonTypeMatches(v any, typ string) bool {
+	switch typ {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		_, ok := jsonInt(v)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	}
+	return true
+}
+
+func jsonTypeName(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case json.Delim:
+		switch x {
+		case '{':
+			return "object"
+		case '[':
+			return "array"
+		}
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// jsonInt returns v as an integer if it is an integral JSON number.
+func jsonInt(v any) (int64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		return i, true
+	}
+	f, err := n.Float64()
+	if err != nil || f != math.Trunc(f) {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// jsonFloat reads a numeric schema keyword, which json.Unmarshal decodes
+// as float64.
+func jsonFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func enumContains(enum []any, v any) bool {
+	s := jsonString(v)
+	for _, e := range enum {
+		if jsonString(e) == s {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonString renders v as canonical JSON (object keys sorted), so values
+// decoded from the input and from the schema compare equal.
+func jsonString(v any) string {
+	if n, ok := v.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func validateString(t *testing.T, input string) *ValidationReport {
+	t.Helper()
+	v, err := NewValidator(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewValidator: %v", err)
+	}
+	report, err := v.Validate()
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	return report
+}
+
+func findViolation(r *ValidationReport, path, rule string) *Violation {
+	for i := range r.Violations {
+		if r.Violations[i].Path == path && r.Violations[i].Rule == rule {
+			return &r.Violations[i]
+		}
+	}
+	return nil
+}
+
+func TestValidateExamples(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(examplesDir, "*.json"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no examples: %v", err)
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := NewValidator(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := v.Validate()
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if !report.Valid() {
+			var buf bytes.Buffer
+			report.WriteText(&buf)
+			t.Errorf("%s: unexpected violations:\n%s", filepath.Base(path), buf.String())
+		}
+	}
+}
+
+// invalidInput has a negotiated_type outside the enum, two prices missing
+// billing_class, and a rate referencing undefined provider group 99. The
+// provider_references come last to exercise deferred reference checks.
+const invalidInput = `{
+  "reporting_entity_name": "acme",
+  "reporting_entity_type": "health insurance issuer",
+  "plan_name": "Acme PPO",
+  "last_updated_on": "2024-13-01",
+  "version": "1.0.0",
+  "in_network": [{
+    "negotiation_arrangement": "ffs",
+    "name": "Knee",
+    "billing_code_type": "CPT",
+    "billing_code_type_version": "2024",
+    "billing_code": "27447",
+    "description": "Knee replacement",
+    "negotiated_rates": [{
+      "provider_references": [1, 99],
+      "negotiated_prices": [
+        {"negotiated_type": "contracted", "negotiated_rate": 10, "expiration_date": "9999-12-31", "setting": "outpatient", "billing_class": "institutional"},
+        {"negotiated_type": "negotiated", "negotiated_rate": 0, "expiration_date": "9999-12-31", "setting": "outpatient"},
+        {"negotiated_type": "negotiated", "negotiated_rate": 5, "expiration_date": "9999-12-31", "setting": "inpatient"}
+      ]
+    }]
+  }],
+  "provider_references": [
+    {"provider_group_id": 1, "network_name": ["A"], "provider_groups": [{"npi": [1111111111], "tin": {"type": "ein", "value": "11-1111111", "business_name": "X"}}]}
+  ]
+}`
+
+func TestValidateViolations(t *testing.T) {
+	r := validateString(t, invalidInput)
+
+	if r.InNetworkItems != 1 || r.NegotiatedRates != 1 || r.NegotiatedPrices != 3 || r.ProviderReferences != 1 {
+		t.Errorf("counts = %d/%d/%d/%d", r.InNetworkItems, r.NegotiatedRates, r.NegotiatedPrices, r.ProviderReferences)
+	}
+
+	const prices = "in_network[].negotiated_rates[].negotiated_prices[]"
+	tests := []struct {
+		path, rule string
+		count      int64
+		example    string
+	}{
+		{prices + ".negotiated_type", "enum", 1, "in_network[0].negotiated_rates[0].negotiated_prices[0].negotiated_type"},
+		{prices + ".billing_class", "required", 2, "in_network[0].negotiated_rates[0].negotiated_prices[1].billing_class"},
+		{prices + ".negotiated_rate", "exclusiveMinimum", 1, ""},
+		{"in_network[].negotiated_rates[].provider_references[]", RuleUnknownProviderReference, 1,
+			"in_network[0].negotiated_rates[0].provider_references[1]"},
+		{"last_updated_on", "format", 1, ""},
+		{"plan_id", "dependencies", 1, ""},
+	}
+	for _, tt := range tests {
+		v := findViolation(r, tt.path, tt.rule)
+		if v == nil {
+			t.Errorf("missing %s %s", tt.rule, tt.path)
+			continue
+		}
+		if v.Count != tt.count {
+			t.Errorf("%s %s count = %d, want %d", tt.rule, tt.path, v.Count, tt.count)
+		}
+		if tt.example != "" && v.Example != tt.example {
+			t.Errorf("%s %s example = %q, want %q", tt.rule, tt.path, v.Example, tt.example)
+		}
+	}
+
+	// Most frequent violation sorts first.
+	if r.Violations[0].Rule != "required" {
+		t.Errorf("first violation = %+v, want the required billing_class", r.Violations[0])
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `"contracted" is not one of`) {
+		t.Errorf("report text missing enum message:\n%s", buf.String())
+	}
+}
+
+func TestValidateProviderReferences(t *testing.T) {
+	r := validateString(t, `{
+  "reporting_entity_name": "acme",
+  "reporting_entity_type": "health insurance issuer",
+  "last_updated_on": "2024-01-01",
+  "version": "1.0.0",
+  "provider_references": [
+    {"provider_group_id": 1, "network_name": ["A"], "provider_groups": [{"npi": [1111111111], "tin": {"type": "npi", "value": "1111111111"}}]},
+    {"provider_group_id": 1, "network_name": ["A"], "provider_groups": [{"npi": [2222222222], "tin": {"type": "npi", "value": "2222222222"}}]}
+  ],
+  "in_network": [{
+    "negotiation_arrangement": "ffs", "name": "Visit", "billing_code_type": "CPT",
+    "billing_code_type_version": "2024", "billing_code": "99213", "description": "Office visit",
+    "negotiated_rates": [
+      {"provider_references": [1, 7], "negotiated_prices": [{"negotiated_type": "negotiated", "negotiated_rate": 80, "expiration_date": "9999-12-31", "billing_class": "institutional", "setting": "outpatient"}]},
+      {"provider_references": [7], "negotiated_prices": [{"negotiated_type": "negotiated", "negotiated_rate": 90, "expiration_date": "9999-12-31", "billing_class": "institutional", "setting": "outpatient"}]}
+    ]
+  }]
+}`)
+
+	if v := findViolation(r, "provider_references[].provider_group_id", RuleDuplicateProviderGroupID); v == nil || v.Count != 1 {
+		t.Errorf("duplicate provider_group_id violation = %+v", v)
+	}
+	v := findViolation(r, "in_network[].negotiated_rates[].provider_references[]", RuleUnknownProviderReference)
+	if v == nil || v.Count != 2 {
+		t.Fatalf("unknown reference violation = %+v, want count 2", v)
+	}
+	if !strings.Contains(v.Message, "provider group 7") {
+		t.Errorf("message = %q", v.Message)
+	}
+	if len(r.Violations) != 2 {
+		t.Errorf("violations = %+v, want 2", r.Violations)
+	}
+}
+
+func TestValidateMalformedJSON(t *testing.T) {
+	v, err := NewValidator(strings.NewReader(`{"in_network": [{"name": }]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.Validate(); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
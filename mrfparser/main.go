@@ -1,8 +1,6 @@
 package main
 
 import (
-	"bufio"
-	"compress/gzip"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -16,8 +14,9 @@ import (
 
 func main() {
 	// CLI flags
-	inputFile := flag.String("file", "", "Input TOC JSON file (required, supports .json and .json.gz)")
-	outputFile := flag.String("out", "", "Output file for extracted plans (default based on format)")
+	inputFile := flag.String("file", "", "Input TOC JSON file (required, supports .json and .json.gz; - reads stdin)")
+	outputFile := flag.String("out", "", "Output file for extracted plans (default based on format; - writes to stdout)")
+	flat := flag.Bool("flat", false, "Parquet: write one file with in_network_urls as a list column instead of <out> plus <out>_urls.parquet")
 	outputFormat := flag.String("format", "json", "Output format: json or parquet")
 	stateCode := flag.String("state", "", "2-letter state code to filter by HIOS ID (e.g., NY, CA, TX)")
 	marketType := flag.String("market", "", "Filter by market type: 'individual' (marketplace/ACA), 'group', or '' for both")
@@ -37,6 +36,12 @@ health insurance plans by state and their in-network file URLs.
 Usage:
   mrfparser -file <input.json> [-out <output.json>] [options]
 
+With -file -, the TOC is read from stdin and gzip is detected from the
+stream. With -out -, the output is written to stdout: JSON, or a single
+Parquet file with -flat. Normalized Parquet is two files, so they are
+written to a temporary directory and streamed as an uncompressed tar
+archive. Logs always go to stderr.
+
 Options:
 `)
 		flag.PrintDefaults()
@@ -60,6 +65,12 @@ Examples:
   # Add custom keywords for matching
   mrfparser -file toc.json -keywords "upstate,westchester"
 
+  # Stream a gzipped TOC from a URL and upload the result
+  curl -s <toc-url> | mrfparser -file - -state NY -format parquet -flat -out - | aws s3 cp - s3://bucket/ny_plans.parquet
+
+  # Normalized Parquet to stdout as a tar archive
+  zcat toc.json.gz | mrfparser -file - -state NY -format parquet -out - | tar -x -C out/
+
   # Dry run to check file without writing output
   mrfparser -file toc.json -dry-run -v
 
@@ -73,8 +84,10 @@ HIOS ID Matching:
 
 Output Formats:
   JSON: Contains metadata and array of plans with in_network_urls as array
-  Parquet: Columnar format with in_network_urls as repeated/list type
-           Uses Snappy compression, includes url_count column
+  Parquet: Plans in <out> and URLs in <out>_urls.parquet, joined on
+           reporting_structure_id. With -flat, one file with
+           in_network_urls as repeated/list type and a url_count column.
+           Uses Snappy compression
 
 Output Fields:
   - plan_name: Name of the health plan
@@ -117,18 +130,21 @@ Output Fields:
 		os.Exit(1)
 	}
 
-	// Set default output file based on format and state
+	// Set default output file based on format and state. The default name
+	// is also used inside the tar stream for normalized Parquet on stdout.
+	defaultOutput := "plans"
+	if *stateCode != "" {
+		defaultOutput = strings.ToLower(*stateCode) + "_plans"
+	}
+	if *outputFormat == "parquet" {
+		defaultOutput += ".parquet"
+	} else {
+		defaultOutput += ".json"
+	}
 	if *outputFile == "" {
-		base := "plans"
-		if *stateCode != "" {
-			base = strings.ToLower(*stateCode) + "_plans"
-		}
-		if *outputFormat == "parquet" {
-			*outputFile = base + ".parquet"
-		} else {
-			*outputFile = base + ".json"
-		}
+		*outputFile = defaultOutput
 	}
+	toStdout := *outputFile == stdioPath
 
 	// Configure the filter
 	// Enable HIOS/keyword matching only when a state is specified
@@ -156,7 +172,11 @@ Output Fields:
 	log.Printf("Starting MRF TOC parser...")
 	log.Printf("Input file: %s", *inputFile)
 	if !*dryRun {
-		log.Printf("Output file: %s (format: %s)", *outputFile, *outputFormat)
+		if toStdout {
+			log.Printf("Output: stdout (format: %s)", *outputFormat)
+		} else {
+			log.Printf("Output file: %s (format: %s)", *outputFile, *outputFormat)
+		}
 	}
 
 	// Log filter configuration
@@ -172,37 +192,17 @@ Output Fields:
 		stateDesc, marketDesc,
 		CurrentFilter.UseHIOSStateCode, CurrentFilter.UseKeywords)
 
-	// Open input file
-	file, err := os.Open(*inputFile)
-	if err != nil {
-		log.Fatalf("Failed to open input file: %v", err)
-	}
-	defer file.Close()
-
-	// Get file size for progress reporting
-	fileInfo, err := file.Stat()
+	// Open input
+	bufSize := *bufferSize * 1024 * 1024
+	reader, closeInput, err := openInput(*inputFile, bufSize)
 	if err != nil {
-		log.Fatalf("Failed to stat input file: %v", err)
+		log.Fatalf("Failed to open input: %v", err)
 	}
-	fileSize := fileInfo.Size()
-	log.Printf("File size: %.2f GB", float64(fileSize)/(1024*1024*1024))
-
-	// Set up reader with buffering
-	var reader io.Reader
-	bufSize := *bufferSize * 1024 * 1024
-	bufferedReader := bufio.NewReaderSize(file, bufSize)
-
-	// Handle gzipped files
-	if strings.HasSuffix(strings.ToLower(*inputFile), ".gz") {
-		gzReader, err := gzip.NewReader(bufferedReader)
-		if err != nil {
-			log.Fatalf("Failed to create gzip reader: %v", err)
+	defer closeInput()
+	if *inputFile != stdioPath {
+		if fileInfo, err := os.Stat(*inputFile); err == nil {
+			log.Printf("File size: %.2f GB", float64(fileInfo.Size())/(1024*1024*1024))
 		}
-		defer gzReader.Close()
-		reader = gzReader
-		log.Printf("Detected gzipped input file")
-	} else {
-		reader = bufferedReader
 	}
 
 	// Create streaming parser
@@ -211,22 +211,37 @@ Output Fields:
 	// For Parquet, we can stream directly to file
 	// For JSON, we need to collect all plans first (for the wrapper object)
 	var matchedPlans []NYSPlanOutput
-	var parquetWriter *NormalizedParquetWriter
+	var parquetWriter PlanWriter
+	var tarDir string // normalized Parquet staged here for a tar on stdout
 
 	if !*dryRun && *outputFormat == "parquet" {
-		// Ensure output directory exists
-		outDir := filepath.Dir(*outputFile)
-		if outDir != "" && outDir != "." {
-			if err := os.MkdirAll(outDir, 0755); err != nil {
-				log.Fatalf("Failed to create output directory: %v", err)
+		switch {
+		case *flat && toStdout:
+			parquetWriter = NewParquetStreamWriter(os.Stdout)
+		case toStdout:
+			tarDir, err = os.MkdirTemp("", "mrfparser-")
+			if err != nil {
+				log.Fatalf("Failed to create temporary directory: %v", err)
+			}
+			defer os.RemoveAll(tarDir)
+			parquetWriter, err = NewNormalizedParquetWriter(filepath.Join(tarDir, defaultOutput))
+		default:
+			// Ensure output directory exists
+			outDir := filepath.Dir(*outputFile)
+			if outDir != "" && outDir != "." {
+				if err := os.MkdirAll(outDir, 0755); err != nil {
+					log.Fatalf("Failed to create output directory: %v", err)
+				}
+			}
+			if *flat {
+				parquetWriter, err = NewParquetWriter(*outputFile)
+			} else {
+				parquetWriter, err = NewNormalizedParquetWriter(*outputFile)
 			}
 		}
-
-		parquetWriter, err = NewNormalizedParquetWriter(*outputFile)
 		if err != nil {
 			log.Fatalf("Failed to create parquet writer: %v", err)
 		}
-		defer parquetWriter.Close()
 	}
 
 	// Progress callback
@@ -242,12 +257,14 @@ Output Fields:
 	}
 
 	// Plan callback
+	written := 0
 	onPlan := func(plan NYSPlanOutput) {
 		if parquetWriter != nil {
 			// Stream directly to Parquet
 			if err := parquetWriter.Write(plan); err != nil {
 				log.Fatalf("Failed to write to parquet: %v", err)
 			}
+			written++
 		} else if !*dryRun {
 			// Collect for JSON output
 			matchedPlans = append(matchedPlans, plan)
@@ -256,7 +273,7 @@ Output Fields:
 		if *verbose {
 			count := len(matchedPlans)
 			if parquetWriter != nil {
-				count = parquetWriter.PlanCount()
+				count = written
 			}
 			if count%100 == 0 {
 				log.Printf("Found %d NYS plans so far...", count)
@@ -294,15 +311,25 @@ Output Fields:
 		if err := parquetWriter.Close(); err != nil {
 			log.Fatalf("Failed to finalize parquet file: %v", err)
 		}
-		log.Printf("Successfully wrote %d plans to %s (Parquet)", parquetWriter.PlanCount(), *outputFile)
-		log.Printf("Successfully wrote %d URLs to %s (Parquet)", parquetWriter.URLCount(), parquetWriter.URLPath())
+		if tarDir != "" {
+			if err := writeTar(os.Stdout, tarDir); err != nil {
+				log.Fatalf("Failed to write output: %v", err)
+			}
+		}
+		switch w := parquetWriter.(type) {
+		case *ParquetWriter:
+			log.Printf("Successfully wrote %d plans to %s (Parquet)", w.Count(), *outputFile)
+		case *NormalizedParquetWriter:
+			log.Printf("Successfully wrote %d plans to %s (Parquet)", w.PlanCount(), filepath.Base(w.PlanPath()))
+			log.Printf("Successfully wrote %d URLs to %s (Parquet)", w.URLCount(), filepath.Base(w.URLPath()))
+		}
 	} else {
 		// Write JSON output
 		log.Printf("Writing output to %s...", *outputFile)
 
 		// Ensure output directory exists
 		outDir := filepath.Dir(*outputFile)
-		if outDir != "" && outDir != "." {
+		if !toStdout && outDir != "" && outDir != "." {
 			if err := os.MkdirAll(outDir, 0755); err != nil {
 				log.Fatalf("Failed to create output directory: %v", err)
 			}
@@ -318,13 +345,17 @@ Output Fields:
 			Plans:               matchedPlans,
 		}
 
-		outFile, err := os.Create(*outputFile)
-		if err != nil {
-			log.Fatalf("Failed to create output file: %v", err)
+		var out io.Writer = os.Stdout
+		if !toStdout {
+			outFile, err := os.Create(*outputFile)
+			if err != nil {
+				log.Fatalf("Failed to create output file: %v", err)
+			}
+			defer outFile.Close()
+			out = outFile
 		}
-		defer outFile.Close()
 
-		encoder := json.NewEncoder(outFile)
+		encoder := json.NewEncoder(out)
 		encoder.SetIndent("", "  ")
 		if err := encoder.Encode(output); err != nil {
 			log.Fatalf("Failed to write output: %v", err)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -23,9 +24,16 @@ type NYSPlanParquet struct {
 
 const parquetFlushInterval = 100_000
 
-// ParquetWriter handles writing plans to a Parquet file
+// PlanWriter is implemented by ParquetWriter and NormalizedParquetWriter
+type PlanWriter interface {
+	Write(plan NYSPlanOutput) error
+	Close() error
+}
+
+// ParquetWriter handles writing plans to a single Parquet file, with
+// in_network_urls as a list column
 type ParquetWriter struct {
-	file   *os.File
+	file   *os.File // nil when writing to a caller-owned stream
 	writer *parquet.GenericWriter[NYSPlanParquet]
 	count  int
 }
@@ -47,6 +55,17 @@ func NewParquetWriter(filename string) (*ParquetWriter, error) {
 	}, nil
 }
 
+// NewParquetStreamWriter creates a Parquet writer on w, such as stdout.
+// Parquet is written front to back, so w need not be seekable. Close
+// finishes the file but does not close w.
+func NewParquetStreamWriter(w io.Writer) *ParquetWriter {
+	return &ParquetWriter{
+		writer: parquet.NewGenericWriter[NYSPlanParquet](w,
+			parquet.Compression(&parquet.Snappy),
+		),
+	}
+}
+
 // Write writes a plan to the Parquet file
 func (pw *ParquetWriter) Write(plan NYSPlanOutput) error {
 	record := NYSPlanParquet{
@@ -80,9 +99,14 @@ func (pw *ParquetWriter) Write(plan NYSPlanOutput) error {
 // Close flushes and closes the Parquet writer
 func (pw *ParquetWriter) Close() error {
 	if err := pw.writer.Close(); err != nil {
-		pw.file.Close()
+		if pw.file != nil {
+			pw.file.Close()
+		}
 		return fmt.Errorf("failed to close parquet writer: %w", err)
 	}
+	if pw.file == nil {
+		return nil
+	}
 	return pw.file.Close()
 }
 
@@ -229,6 +253,11 @@ func (nw *NormalizedParquetWriter) URLCount() int {
 	return nw.urlCount
 }
 
+// PlanPath returns the plan parquet file path
+func (nw *NormalizedParquetWriter) PlanPath() string {
+	return nw.planFile.Name()
+}
+
 // URLPath returns the URL parquet file path
 func (nw *NormalizedParquetWriter) URLPath() string {
 	return nw.urlFile.Name()
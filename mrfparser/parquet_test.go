@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"strings"
@@ -117,6 +118,33 @@ func TestParquetWriter(t *testing.T) {
 	}
 }
 
+// TestParquetStreamWriter verifies a plan file written to a plain stream,
+// as with -flat -out -, reads back like one written to disk
+func TestParquetStreamWriter(t *testing.T) {
+	var buf bytes.Buffer // not seekable, like a pipe
+	writer := NewParquetStreamWriter(&buf)
+	plan := NYSPlanOutput{
+		PlanName:      "Stream Plan",
+		PlanIDType:    "hios",
+		PlanID:        "12345NY0010001",
+		InNetworkURLs: []string{"https://example.com/a.json", "https://example.com/b.json"},
+	}
+	if err := writer.Write(plan); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	records, err := parquet.Read[NYSPlanParquet](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read parquet stream: %v", err)
+	}
+	if len(records) != 1 || records[0].PlanID != plan.PlanID || records[0].URLCount != 2 {
+		t.Errorf("Unexpected records: %+v", records)
+	}
+}
+
 // TestUnfilteredJSONParquetParity verifies that unfiltered JSON and Parquet output
 // produce identical plan data from the same TOC input.
 func TestUnfilteredJSONParquetParity(t *testing.T) {
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// stdioPath is the -file / -out value meaning stdin or stdout.
+const stdioPath = "-"
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// openInput opens path with a buffered reader, decompressing .gz files.
+// A path of "-" reads stdin, detecting gzip from the stream's magic bytes.
+// The returned close function releases the file.
+func openInput(path string, bufSize int) (io.Reader, func() error, error) {
+	var (
+		br        *bufio.Reader
+		closeFile = func() error { return nil }
+		gzipped   bool
+	)
+	if path == stdioPath {
+		br = bufio.NewReaderSize(os.Stdin, bufSize)
+		head, _ := br.Peek(len(gzipMagic))
+		gzipped = bytes.Equal(head, gzipMagic)
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open input file: %w", err)
+		}
+		br = bufio.NewReaderSize(file, bufSize)
+		closeFile = file.Close
+		gzipped = strings.HasSuffix(strings.ToLower(path), ".gz")
+	}
+	if !gzipped {
+		return br, closeFile, nil
+	}
+	gz, err := gzip.NewReader(br)
+	if err != nil {
+		closeFile()
+		return nil, nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	return gz, func() error {
+		gz.Close()
+		return closeFile()
+	}, nil
+}
+
+// writeTar writes every regular file under dir to w as an uncompressed tar
+// stream, with names relative to dir.
+func writeTar(w io.Writer, dir string) error {
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write tar: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write tar: %w", err)
+	}
+	return nil
+}
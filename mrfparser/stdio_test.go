@@ -0,0 +1,96 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenInputStdin(t *testing.T) {
+	const doc = `{"reporting_entity_name": "x"}`
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(doc))
+	zw.Close()
+
+	for name, data := range map[string][]byte{"plain": []byte(doc), "gzip": gz.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "stdin")
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			old := os.Stdin
+			os.Stdin = f
+			defer func() { os.Stdin = old }()
+
+			r, closeInput, err := openInput(stdioPath, 1<<16)
+			if err != nil {
+				t.Fatalf("openInput: %v", err)
+			}
+			defer closeInput()
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if string(got) != doc {
+				t.Errorf("Expected %q, got %q", doc, got)
+			}
+		})
+	}
+}
+
+func TestWriteTar(t *testing.T) {
+	dir := t.TempDir()
+	writer, err := NewNormalizedParquetWriter(filepath.Join(dir, "ny_plans.parquet"))
+	if err != nil {
+		t.Fatalf("Failed to create writer: %v", err)
+	}
+	if err := writer.Write(NYSPlanOutput{StructureID: 1, PlanID: "p", InNetworkURLs: []string{"u"}}); err != nil {
+		t.Fatalf("Failed to write plan: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close writer: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTar(&buf, dir); err != nil {
+		t.Fatalf("writeTar: %v", err)
+	}
+	tr := tar.NewReader(&buf)
+	sizes := map[string]int64{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar: %v", err)
+		}
+		n, err := io.Copy(io.Discard, tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[hdr.Name] = n
+	}
+	for _, name := range []string{"ny_plans.parquet", "ny_plans_urls.parquet"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sizes[name] != info.Size() {
+			t.Errorf("%s: tar size %d, file size %d", name, sizes[name], info.Size())
+		}
+	}
+	if len(sizes) != 2 {
+		t.Errorf("Expected 2 tar entries, got %v", sizes)
+	}
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -56,7 +55,7 @@ var CurrentFilter = DefaultFilterConfig()
 
 // StreamParser handles streaming JSON parsing for large TOC files
 type StreamParser struct {
-	decoder  *json.Decoder
+	scan     *jsonScanner
 	stats    ParserStats
 	metadata TOCMetadata
 }
@@ -80,9 +79,8 @@ type TOCMetadata struct {
 
 // NewStreamParser creates a new streaming parser
 func NewStreamParser(r io.Reader) *StreamParser {
-	decoder := json.NewDecoder(r)
 	return &StreamParser{
-		decoder: decoder,
+		scan: newJSONScanner(r),
 	}
 }
 
@@ -165,75 +163,39 @@ func generateDescription(plan ReportingPlan) string {
 
 // Parse streams through the TOC file and extracts NYS plans
 func (p *StreamParser) Parse(onPlan func(NYSPlanOutput), onProgress func(stats ParserStats)) error {
-	// Read opening brace
-	t, err := p.decoder.Token()
-	if err != nil {
-		return fmt.Errorf("error reading opening token: %w", err)
-	}
-	if delim, ok := t.(json.Delim); !ok || delim != '{' {
-		return fmt.Errorf("expected object start, got %v", t)
-	}
-
-	// Read top-level fields until we hit reporting_structure
-	for p.decoder.More() {
-		// Read field name
-		t, err := p.decoder.Token()
-		if err != nil {
-			return fmt.Errorf("error reading field name: %w", err)
-		}
-
-		fieldName, ok := t.(string)
-		if !ok {
-			return fmt.Errorf("expected field name string, got %T", t)
-		}
-
+	// Read top-level fields, streaming reporting_structure when we hit it
+	return p.scan.Object(func(key []byte) error {
+		var err error
+		fieldName := string(key)
 		switch fieldName {
 		case "reporting_entity_name":
-			if err := p.decoder.Decode(&p.metadata.ReportingEntityName); err != nil {
-				return fmt.Errorf("error decoding reporting_entity_name: %w", err)
-			}
+			p.metadata.ReportingEntityName, err = p.scan.String()
 		case "reporting_entity_type":
-			if err := p.decoder.Decode(&p.metadata.ReportingEntityType); err != nil {
-				return fmt.Errorf("error decoding reporting_entity_type: %w", err)
-			}
+			p.metadata.ReportingEntityType, err = p.scan.String()
 		case "last_updated_on":
-			if err := p.decoder.Decode(&p.metadata.LastUpdatedOn); err != nil {
-				return fmt.Errorf("error decoding last_updated_on: %w", err)
-			}
+			p.metadata.LastUpdatedOn, err = p.scan.String()
 		case "version":
-			if err := p.decoder.Decode(&p.metadata.Version); err != nil {
-				return fmt.Errorf("error decoding version: %w", err)
-			}
+			p.metadata.Version, err = p.scan.String()
 		case "reporting_structure":
 			// Stream through the reporting_structure array
-			if err := p.parseReportingStructure(onPlan, onProgress); err != nil {
-				return err
-			}
+			return p.parseReportingStructure(onPlan, onProgress)
 		default:
 			// Skip unknown fields
-			var skip json.RawMessage
-			if err := p.decoder.Decode(&skip); err != nil {
+			if err := p.scan.Skip(); err != nil {
 				return fmt.Errorf("error skipping field %s: %w", fieldName, err)
 			}
 		}
-	}
-
-	return nil
+		if err != nil {
+			return fmt.Errorf("error decoding %s: %w", fieldName, err)
+		}
+		return nil
+	})
 }
 
 // parseReportingStructure streams through the reporting_structure array
 func (p *StreamParser) parseReportingStructure(onPlan func(NYSPlanOutput), onProgress func(stats ParserStats)) error {
-	// Read opening bracket of array
-	t, err := p.decoder.Token()
-	if err != nil {
-		return fmt.Errorf("error reading reporting_structure start: %w", err)
-	}
-	if delim, ok := t.(json.Delim); !ok || delim != '[' {
-		return fmt.Errorf("expected array start for reporting_structure, got %v", t)
-	}
-
 	// Stream through each reporting structure
-	for p.decoder.More() {
+	isArray, err := p.scan.Array(func() error {
 		if err := p.parseOneStructure(onPlan); err != nil {
 			return err
 		}
@@ -242,14 +204,14 @@ func (p *StreamParser) parseReportingStructure(onPlan func(NYSPlanOutput), onPro
 		if p.stats.TotalStructures%10000 == 0 {
 			onProgress(p.stats)
 		}
-	}
-
-	// Read closing bracket
-	_, err = p.decoder.Token()
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("error reading reporting_structure end: %w", err)
+		return fmt.Errorf("error reading reporting_structure: %w", err)
+	}
+	if !isArray {
+		return fmt.Errorf("expected array start for reporting_structure, got null")
 	}
-
 	return nil
 }
 
@@ -257,15 +219,6 @@ func (p *StreamParser) parseReportingStructure(onPlan func(NYSPlanOutput), onPro
 // decoding plans one at a time to limit per-structure memory usage.
 // Handles either field ordering (reporting_plans before/after in_network_files).
 func (p *StreamParser) parseOneStructure(onPlan func(NYSPlanOutput)) error {
-	// Read opening brace
-	t, err := p.decoder.Token()
-	if err != nil {
-		return fmt.Errorf("error reading structure start: %w", err)
-	}
-	if delim, ok := t.(json.Delim); !ok || delim != '{' {
-		return fmt.Errorf("expected object start for structure, got %v", t)
-	}
-
 	p.stats.TotalStructures++
 
 	var urls []string
@@ -288,21 +241,13 @@ func (p *StreamParser) parseOneStructure(onPlan func(NYSPlanOutput)) error {
 		})
 	}
 
-	for p.decoder.More() {
-		t, err := p.decoder.Token()
-		if err != nil {
-			return fmt.Errorf("error reading structure field: %w", err)
-		}
-		fieldName, ok := t.(string)
-		if !ok {
-			return fmt.Errorf("expected field name, got %T", t)
-		}
-
+	err := p.scan.Object(func(key []byte) error {
+		fieldName := string(key)
 		switch fieldName {
 		case "reporting_plans":
-			if err := p.streamArray(func() error {
+			return p.streamArray(func() error {
 				var plan ReportingPlan
-				if err := p.decoder.Decode(&plan); err != nil {
+				if err := decodeReportingPlan(p.scan, &plan); err != nil {
 					return fmt.Errorf("error decoding plan: %w", err)
 				}
 
@@ -317,14 +262,12 @@ func (p *StreamParser) parseOneStructure(onPlan func(NYSPlanOutput)) error {
 					pendingPlans = append(pendingPlans, plan)
 				}
 				return nil
-			}); err != nil {
-				return err
-			}
+			})
 
 		case "in_network_files":
 			if err := p.streamArray(func() error {
 				var f FileLocation
-				if err := p.decoder.Decode(&f); err != nil {
+				if err := decodeFileLocation(p.scan, &f); err != nil {
 					return fmt.Errorf("error decoding file location: %w", err)
 				}
 				urls = append(urls, f.Location)
@@ -339,13 +282,17 @@ func (p *StreamParser) parseOneStructure(onPlan func(NYSPlanOutput)) error {
 				emitPlan(plan)
 			}
 			pendingPlans = nil
+			return nil
 
 		default:
-			var skip json.RawMessage
-			if err := p.decoder.Decode(&skip); err != nil {
+			if err := p.scan.Skip(); err != nil {
 				return fmt.Errorf("error skipping field %s: %w", fieldName, err)
 			}
+			return nil
 		}
+	})
+	if err != nil {
+		return err
 	}
 
 	// Emit any remaining buffered plans (e.g. no in_network_files field)
@@ -356,36 +303,58 @@ func (p *StreamParser) parseOneStructure(onPlan func(NYSPlanOutput)) error {
 	if matchedInStructure > 0 {
 		p.stats.MatchedStructures++
 	}
-
-	// Read closing brace
-	_, err = p.decoder.Token()
-	if err != nil {
-		return fmt.Errorf("error reading structure end: %w", err)
-	}
-
 	return nil
 }
 
-// streamArray reads a JSON array token by token, calling fn for each element.
-// fn must consume exactly one element from the decoder per call.
+// streamArray calls fn for each element of a JSON array.
+// fn must consume exactly one element per call.
 func (p *StreamParser) streamArray(fn func() error) error {
-	t, err := p.decoder.Token()
-	if err != nil {
-		return fmt.Errorf("error reading array start: %w", err)
-	}
-	if delim, ok := t.(json.Delim); !ok || delim != '[' {
-		return fmt.Errorf("expected array start, got %v", t)
+	isArray, err := p.scan.Array(fn)
+	if err == nil && !isArray {
+		err = fmt.Errorf("expected array start, got null")
 	}
+	return err
+}
 
-	for p.decoder.More() {
-		if err := fn(); err != nil {
-			return err
+// decodeReportingPlan decodes one reporting_plans entry. It must stay in
+// step with ReportingPlan's json tags; TestDecodeMatchesEncodingJSON checks.
+func decodeReportingPlan(s *jsonScanner, plan *ReportingPlan) error {
+	return s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "plan_name":
+			plan.PlanName, err = s.String()
+		case "issuer_name":
+			plan.IssuerName, err = s.String()
+		case "plan_id_type":
+			plan.PlanIDType, err = s.Interned()
+		case "plan_id":
+			plan.PlanID, err = s.String()
+		case "plan_sponsor_name":
+			plan.PlanSponsorName, err = s.String()
+		case "plan_market_type":
+			plan.PlanMarketType, err = s.Interned()
+		default:
+			err = s.Skip()
 		}
-	}
+		return err
+	})
+}
 
-	// Read closing bracket
-	_, err = p.decoder.Token()
-	return err
+// decodeFileLocation decodes one in_network_files entry.
+func decodeFileLocation(s *jsonScanner, f *FileLocation) error {
+	return s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "description":
+			f.Description, err = s.String()
+		case "location":
+			f.Location, err = s.String()
+		default:
+			err = s.Skip()
+		}
+		return err
+	})
 }
 
 // GetStats returns current parsing statistics
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 // nysFilter returns a filter configured for NY state matching
@@ -406,3 +412,161 @@ func TestStreamParserWithSampleFile(t *testing.T) {
 		t.Errorf("Expected 0 NYS plans from sample, got %d", len(plans))
 	}
 }
+
+// parseWithEncodingJSON is the reflection-based reference the scanner
+// replaced: it decodes the whole TOC and emits matched plans in order.
+func parseWithEncodingJSON(data []byte) ([]NYSPlanOutput, error) {
+	var toc struct {
+		ReportingStructure []ReportingStructure `json:"reporting_structure"`
+	}
+	if err := json.Unmarshal(data, &toc); err != nil {
+		return nil, err
+	}
+	var plans []NYSPlanOutput
+	for i, rs := range toc.ReportingStructure {
+		var urls []string
+		for _, f := range rs.InNetworkFiles {
+			urls = append(urls, f.Location)
+		}
+		for _, plan := range rs.ReportingPlans {
+			if !isNYSPlan(plan) {
+				continue
+			}
+			plans = append(plans, NYSPlanOutput{
+				PlanName:       plan.PlanName,
+				PlanIDType:     plan.PlanIDType,
+				PlanID:         plan.PlanID,
+				PlanMarketType: plan.PlanMarketType,
+				IssuerName:     plan.IssuerName,
+				Description:    generateDescription(plan),
+				InNetworkURLs:  urls,
+				StructureID:    int64(i + 1),
+			})
+		}
+	}
+	return plans, nil
+}
+
+func parseWithScanner(data []byte) ([]NYSPlanOutput, error) {
+	return parseReaderWithScanner(bytes.NewReader(data))
+}
+
+func parseReaderWithScanner(r io.Reader) ([]NYSPlanOutput, error) {
+	var plans []NYSPlanOutput
+	err := NewStreamParser(r).Parse(func(p NYSPlanOutput) {
+		plans = append(plans, p)
+	}, func(ParserStats) {})
+	return plans, err
+}
+
+// syntheticTOC builds a TOC with n reporting structures, alternating the
+// order of reporting_plans and in_network_files.
+func syntheticTOC(n int) []byte {
+	var b bytes.Buffer
+	b.WriteString(`{"reporting_entity_name": "Bench Health", "reporting_entity_type": "health insurance issuer",
+  "last_updated_on": "2024-01-01", "version": "1.0.0", "reporting_structure": [`)
+	states := []string{"NY", "CA", "TX", "NJ"}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		plans := fmt.Sprintf(`"reporting_plans": [
+      {"plan_name": "Plan %d Gold", "plan_id_type": "hios", "plan_id": "12345%s%03d0001", "plan_market_type": "individual", "issuer_name": "Issuer %d"},
+      {"plan_name": "Employer %d é Plan", "plan_id_type": "ein", "plan_id": "12-%07d", "plan_sponsor_name": "Sponsor of New York %d", "plan_market_type": "group", "issuer_name": "Issuer %d"}]`,
+			i, states[i%len(states)], i%1000, i%50, i, i, i, i%50)
+		files := fmt.Sprintf(`"in_network_files": [
+      {"description": "in-network rates", "location": "https://example.com/files/%d_in-network.json.gz"},
+      {"description": "in-network rates part 2", "location": "https://example.com/files/%d_in-network_2.json.gz"}],
+    "allowed_amount_file": {"description": "allowed amounts", "location": "https://example.com/files/%d_allowed.json"}`, i, i, i)
+		if i%2 == 0 {
+			fmt.Fprintf(&b, "\n    {%s,\n    %s}", plans, files)
+		} else {
+			fmt.Fprintf(&b, "\n    {%s,\n    %s}", files, plans)
+		}
+	}
+	b.WriteString("]}\n")
+	return b.Bytes()
+}
+
+func TestDecodeMatchesEncodingJSON(t *testing.T) {
+	CurrentFilter = nysFilter()
+	defer func() { CurrentFilter = DefaultFilterConfig() }()
+
+	for name, data := range map[string][]byte{
+		"synthetic":       syntheticTOC(200),
+		"small synthetic": syntheticTOC(8),
+		"edge cases": []byte(`{"REPORTING_STRUCTURE": [
+			{"reporting_plans": [{"Plan_Name": "Café \"NY\"", "plan_id_type": "hios", "plan_id": "12345NY001",
+			  "plan_market_type": null, "issuer_name": "Émblem", "extra": [1, {"a": null}]}, null],
+			 "in_network_files": []},
+			{"reporting_plans": [{"plan_name": "nys plan", "plan_id": "x", "issuer_name": "bad ` + "\xff" + ` utf8"}]}]}`),
+	} {
+		want, err := parseWithEncodingJSON(data)
+		if err != nil {
+			t.Fatalf("%s: encoding/json: %v", name, err)
+		}
+		if len(want) == 0 {
+			t.Fatalf("%s: no plans matched", name)
+		}
+		forEachSplitReader(data, func(reader string, r io.Reader) bool {
+			got, err := parseReaderWithScanner(r)
+			if err != nil {
+				t.Errorf("%s, %s: scanner: %v", name, reader, err)
+				return false
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s, %s: scanner parsed\n%+v\nencoding/json parsed\n%+v", name, reader, got, want)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+// maxSplitInput bounds the inputs forEachSplitReader splits at every
+// offset; each split is a full parse.
+const maxSplitInput = 32 << 10
+
+// forEachSplitReader calls fn with readers over data that deliver it
+// whole, a byte at a time, half a read at a time, and (for inputs up to
+// maxSplitInput) in two reads split at every offset, so the scanner
+// refills its buffer at every position. It stops at the first reader fn
+// returns false for.
+func forEachSplitReader(data []byte, fn func(name string, r io.Reader) bool) {
+	if !fn("bytes.Reader", bytes.NewReader(data)) ||
+		!fn("OneByteReader", iotest.OneByteReader(bytes.NewReader(data))) ||
+		!fn("HalfReader", iotest.HalfReader(bytes.NewReader(data))) ||
+		len(data) > maxSplitInput {
+		return
+	}
+	for i := 1; i < len(data); i++ {
+		r := io.MultiReader(bytes.NewReader(data[:i]), bytes.NewReader(data[i:]))
+		if !fn(fmt.Sprintf("split at %d", i), r) {
+			return
+		}
+	}
+}
+
+func BenchmarkParseTOC(b *testing.B) {
+	CurrentFilter = nysFilter()
+	defer func() { CurrentFilter = DefaultFilterConfig() }()
+
+	data := syntheticTOC(5000)
+	for _, bc := range []struct {
+		name  string
+		parse func([]byte) ([]NYSPlanOutput, error)
+	}{
+		{"encoding_json", parseWithEncodingJSON},
+		{"scanner", parseWithScanner},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := bc.parse(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -124,7 +124,7 @@ export async function GET(
         h.name AS hospital_name,
         COALESCE(h.addresses[1], '') AS hospital_address,
         h.license_state,
-        h.last_updated_on::text AS last_updated_on,
+        NULLIF(h.last_updated_on, '-infinity')::text AS last_updated_on,
         COALESCE(BOOL_OR(LOWER(sc.setting) IN ('inpatient', 'both')), false) AS has_inpatient,
         COALESCE(BOOL_OR(LOWER(sc.setting) IN ('outpatient', 'both')), false) AS has_outpatient
       FROM hospitals h